@@ -9,8 +9,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/disintegration/imaging"
@@ -20,6 +22,8 @@ import (
 	"tagexplorer/internal/api"
 	"tagexplorer/internal/data"
 	"tagexplorer/internal/logging"
+	"tagexplorer/internal/pathtemplate"
+	"tagexplorer/internal/tagquery"
 	"tagexplorer/internal/workspace"
 )
 
@@ -33,11 +37,20 @@ type App struct {
 	logCleanup       func()
 	currentWorkspace *data.Workspace
 	settings         *api.AppSettings
+
+	// rename 移动文件时首先尝试的重命名，测试中可替换以模拟跨设备移动
+	rename func(oldpath, newpath string) error
+
+	// opMu 串行化修改磁盘文件的操作与工作区切换（包括后台运行的自动整理规则），
+	// 持有期间 currentWorkspace 与 settings 不会被替换
+	opMu     sync.Mutex
+	ruleStop chan struct{} // 关闭后停止自动整理规则的后台检查
+	ruleDone chan struct{} // 后台检查退出后关闭
 }
 
 // NewApp 创建应用实例
 func NewApp() *App {
-	return &App{}
+	return &App{rename: os.Rename}
 }
 
 // startup 初始化运行环境
@@ -89,6 +102,10 @@ func (a *App) startup(ctx context.Context) {
 			Position:  "suffix",
 			AddSpaces: true,
 			Grouping:  "combined",
+
+			Filesystem:       filesystemAuto,
+			MaxNameLength:    defaultMaxNameLength,
+			OverflowStrategy: overflowTruncate,
 		},
 	}
 
@@ -98,10 +115,18 @@ func (a *App) startup(ctx context.Context) {
 			a.logger.Warn("从数据库加载设置失败，使用默认设置", zap.Error(err))
 		}
 	}
+
+	a.startOrganizeRuleScheduler()
+}
+
+// domReady 前端加载完成，此时推送的事件才能被前端收到
+func (a *App) domReady(ctx context.Context) {
+	a.detectInterruptedOrganizes()
 }
 
 // shutdown 释放资源
 func (a *App) shutdown(ctx context.Context) {
+	a.stopOrganizeRuleScheduler()
 	if a.db != nil {
 		if err := a.db.Close(); err != nil {
 			runtime.LogErrorf(ctx, "关闭数据库失败: %v", err)
@@ -141,12 +166,21 @@ func (a *App) UpdateSettings(settings *api.AppSettings) error {
 		return fmt.Errorf("设置验证失败: %w", err)
 	}
 
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
 	// 检查标签格式是否发生变化
 	formatChanged := a.settings == nil ||
 		a.settings.TagRule.Format != settings.TagRule.Format ||
 		a.settings.TagRule.Position != settings.TagRule.Position ||
 		a.settings.TagRule.AddSpaces != settings.TagRule.AddSpaces
 
+	// 文件名长度/保留名规则变化同样需要重新生成文件名
+	formatChanged = formatChanged ||
+		a.settings.TagRule.Filesystem != settings.TagRule.Filesystem ||
+		a.settings.TagRule.MaxNameLength != settings.TagRule.MaxNameLength ||
+		a.settings.TagRule.OverflowStrategy != settings.TagRule.OverflowStrategy
+
 	// 如果是自定义格式，还需要检查自定义格式设置
 	if settings.TagRule.Format == "custom" {
 		if a.settings == nil || a.settings.TagRule.CustomFormat == nil || settings.TagRule.CustomFormat == nil {
@@ -181,6 +215,8 @@ func (a *App) UpdateSettings(settings *api.AppSettings) error {
 	// 如果标签格式发生变化且有当前工作区，批量更新文件名
 	if formatChanged && a.currentWorkspace != nil {
 		go func() {
+			a.opMu.Lock()
+			defer a.opMu.Unlock()
 			if err := a.batchUpdateFileNamesWithNewFormat(); err != nil {
 				if a.logger != nil {
 					a.logger.Error("批量更新文件名格式失败", zap.Error(err))
@@ -207,6 +243,7 @@ func (a *App) batchUpdateFileNamesWithNewFormat() error {
 	const batchSize = 100
 	offset := 0
 	updatedCount := 0
+	var records []api.FileChangeRecord
 
 	for {
 		// 获取一批文件
@@ -227,7 +264,8 @@ func (a *App) batchUpdateFileNamesWithNewFormat() error {
 			}
 
 			// 尝试重命名文件以应用新格式
-			if err := a.RenameFileWithTags(file.ID); err != nil {
+			record := api.FileChangeRecord{FileID: file.ID}
+			if _, err := a.renameFileWithTags(file.ID, &record); err != nil {
 				if a.logger != nil {
 					a.logger.Warn("更新文件标签格式失败",
 						zap.Int64("file_id", file.ID),
@@ -238,6 +276,9 @@ func (a *App) batchUpdateFileNamesWithNewFormat() error {
 				// 继续处理其他文件
 				continue
 			}
+			if record.From != "" {
+				records = append(records, record)
+			}
 
 			updatedCount++
 		}
@@ -250,6 +291,18 @@ func (a *App) batchUpdateFileNamesWithNewFormat() error {
 		offset += batchSize
 	}
 
+	// 整批重命名记录为一次操作，便于整体撤销
+	if len(records) > 0 {
+		a.recordOperation(data.OperationRename, a.currentWorkspace.ID,
+			fmt.Sprintf("应用新的标签格式重命名 %d 个文件", len(records)),
+			api.FileOperationPayload{
+				WorkspaceID: a.currentWorkspace.ID,
+				Action:      renameActionFormat,
+				Changes:     records,
+			},
+		)
+	}
+
 	if a.logger != nil {
 		a.logger.Info("完成批量更新文件名标签格式",
 			zap.Int64("workspace_id", a.currentWorkspace.ID),
@@ -294,6 +347,33 @@ func (a *App) validateSettings(settings *api.AppSettings) error {
 		return errors.New("无效的标签组合方式")
 	}
 
+	// 验证文件名安全规则（为空时使用默认值）
+	validFilesystems := map[string]bool{
+		"":                true,
+		filesystemAuto:    true,
+		filesystemPosix:   true,
+		filesystemWindows: true,
+	}
+
+	if !validFilesystems[settings.TagRule.Filesystem] {
+		return errors.New("无效的目标文件系统")
+	}
+
+	validOverflowStrategies := map[string]bool{
+		"":               true,
+		overflowTruncate: true,
+		overflowDBOnly:   true,
+		overflowFail:     true,
+	}
+
+	if !validOverflowStrategies[settings.TagRule.OverflowStrategy] {
+		return errors.New("无效的文件名超长处理策略")
+	}
+
+	if settings.TagRule.MaxNameLength < 0 || settings.TagRule.MaxNameLength > 1024 {
+		return errors.New("文件名最大长度需在 0-1024 之间")
+	}
+
 	// 如果是自定义格式，验证自定义格式设置
 	if settings.TagRule.Format == "custom" {
 		if settings.TagRule.CustomFormat == nil {
@@ -378,6 +458,9 @@ func (a *App) AddWorkspaceFolder() (*api.ScanResult, error) {
 
 // RemoveWorkspaceFolder 从工作区移除文件夹
 func (a *App) RemoveWorkspaceFolder(workspaceID int64) error {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
 	// 这里只是从当前会话中移除，不删除数据库记录
 	// 因为用户可能还想保留历史数据
 	if a.currentWorkspace != nil && a.currentWorkspace.ID == workspaceID {
@@ -406,6 +489,9 @@ func (a *App) GetWorkspaceFolders() ([]api.Workspace, error) {
 
 // SetActiveWorkspace 设置当前活动的工作区
 func (a *App) SetActiveWorkspace(workspaceID int64) error {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
 	if a.db == nil {
 		return errors.New("数据库尚未准备就绪")
 	}
@@ -434,8 +520,12 @@ type WorkspaceConfig struct {
 	Folders   []string  `json:"folders"`
 	CreatedAt time.Time `json:"created_at"`
 	Version   string    `json:"version"`
+	// SavedSearches 随工作区共享的保存搜索
+	SavedSearches []SavedSearchConfig `json:"saved_searches,omitempty"`
 	// FilePath 是工作区配置文件的路径（仅在加载时填充，不保存到文件）
 	FilePath string `json:"file_path,omitempty"`
+	// SkippedSearches 因本地已有同名搜索而未导入的保存搜索（仅在加载时填充，不保存到文件）
+	SkippedSearches []string `json:"skipped_searches,omitempty"`
 }
 
 // SaveWorkspaceConfig 保存工作区配置到文件
@@ -475,10 +565,11 @@ func (a *App) SaveWorkspaceConfig(name string, folders []string) (string, error)
 
 	// 创建配置对象
 	config := WorkspaceConfig{
-		Name:      name,
-		Folders:   folders,
-		CreatedAt: time.Now().UTC(),
-		Version:   "1.0",
+		Name:          name,
+		Folders:       folders,
+		CreatedAt:     time.Now().UTC(),
+		Version:       "1.0",
+		SavedSearches: a.savedSearchesForConfig(folders),
 	}
 
 	// 序列化为 JSON
@@ -531,10 +622,11 @@ func (a *App) UpdateWorkspaceConfig(filePath string, name string, folders []stri
 
 	// 创建配置对象
 	config := WorkspaceConfig{
-		Name:      name,
-		Folders:   folders,
-		CreatedAt: createdAt,
-		Version:   "1.0",
+		Name:          name,
+		Folders:       folders,
+		CreatedAt:     createdAt,
+		Version:       "1.0",
+		SavedSearches: a.savedSearchesForConfig(folders),
 	}
 
 	// 序列化为 JSON
@@ -623,6 +715,8 @@ func (a *App) LoadWorkspaceConfig() (*WorkspaceConfig, error) {
 	// 设置文件路径
 	config.FilePath = selectedPath
 
+	config.SkippedSearches = a.importSavedSearches(config.Folders, config.SavedSearches)
+
 	// 记录到最近打开列表
 	if err := a.db.AddRecentItem(a.ctx, "workspace", selectedPath, config.Name); err != nil {
 		if a.logger != nil {
@@ -714,6 +808,8 @@ func (a *App) OpenRecentItem(path string, itemType string) (*api.ScanResult, err
 			return nil, errors.New("配置文件中的所有文件夹都不存在")
 		}
 
+		a.importSavedSearches(validFolders, config.SavedSearches)
+
 		// 更新最近打开记录
 		if err := a.db.AddRecentItem(a.ctx, "workspace", path, config.Name); err != nil {
 			if a.logger != nil {
@@ -790,6 +886,9 @@ func (a *App) ScanWorkspaceFolder(folderPath string) (*api.ScanResult, error) {
 
 // scanFolder 内部方法：扫描文件夹（不记录到最近列表）
 func (a *App) scanFolder(selectedPath string) (*api.ScanResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
 	absPath, err := filepath.Abs(selectedPath)
 	if err != nil {
 		return nil, fmt.Errorf("解析工作区绝对路径失败: %w", err)
@@ -858,7 +957,9 @@ func (a *App) SelectWorkspace() (*api.ScanResult, error) {
 		if a.logger != nil {
 			a.logger.Info("用户取消选择工作区")
 		}
+		a.opMu.Lock()
 		a.currentWorkspace = nil
+		a.opMu.Unlock()
 		return nil, nil
 	}
 
@@ -879,8 +980,13 @@ func (a *App) SelectWorkspace() (*api.ScanResult, error) {
 	return result, nil
 }
 
-// GetFiles 分页返回当前工作区文件列表
+// GetFiles 按 OFFSET 分页获取当前工作区文件（兼容旧接口，深分页请使用 GetFilesPage）
 func (a *App) GetFiles(limit, offset int) (*api.FilePage, error) {
+	return a.GetFilesSorted(limit, offset, "", "")
+}
+
+// GetFilesSorted 按指定排序分页获取当前工作区文件
+func (a *App) GetFilesSorted(limit, offset int, sortBy, sortOrder string) (*api.FilePage, error) {
 	if a.ctx == nil {
 		return nil, errors.New("应用尚未初始化")
 	}
@@ -891,14 +997,19 @@ func (a *App) GetFiles(limit, offset int) (*api.FilePage, error) {
 		return nil, errors.New("尚未选择工作区")
 	}
 
-	page, err := a.db.ListFiles(a.ctx, a.currentWorkspace.ID, limit, offset)
+	sort, err := toFileSort(sortBy, sortOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := a.db.ListFilesSorted(a.ctx, a.currentWorkspace.ID, sort, limit, offset)
 	if err != nil {
 		if a.logger != nil {
 			a.logger.Error(
 				"获取文件列表失败",
 				zap.Int64("workspace_id", a.currentWorkspace.ID),
-				zap.Int("limit", limit),
-				zap.Int("offset", offset),
+				zap.String("sort_by", sortBy),
+				zap.String("sort_order", sortOrder),
 				zap.Error(err),
 			)
 		}
@@ -908,6 +1019,41 @@ func (a *App) GetFiles(limit, offset int) (*api.FilePage, error) {
 	return toAPIFilePage(page), nil
 }
 
+// GetFilesPage 按游标分页获取当前工作区文件，cursor 为空时从第一页开始
+func (a *App) GetFilesPage(cursor string, limit int, sortBy, sortOrder string, includeTotal bool) (*api.FileCursorPage, error) {
+	if a.ctx == nil {
+		return nil, errors.New("应用尚未初始化")
+	}
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	sort, err := toFileSort(sortBy, sortOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := a.db.ListFilesByCursor(a.ctx, a.currentWorkspace.ID, sort, cursor, limit, includeTotal)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error(
+				"获取文件列表失败",
+				zap.Int64("workspace_id", a.currentWorkspace.ID),
+				zap.String("sort_by", sortBy),
+				zap.String("sort_order", sortOrder),
+				zap.Bool("with_cursor", cursor != ""),
+				zap.Error(err),
+			)
+		}
+		return nil, err
+	}
+
+	return toAPIFileCursorPage(page), nil
+}
+
 // ListTags 返回全部标签
 func (a *App) ListTags() ([]api.Tag, error) {
 	if a.db == nil {
@@ -927,107 +1073,134 @@ func (a *App) ListTags() ([]api.Tag, error) {
 	return result, nil
 }
 
-// CreateTag 创建新标签
-func (a *App) CreateTag(name, color string, parentID *int64) (*api.Tag, error) {
+// AutocompleteTags 按名称、全拼或拼音首字母联想标签（如 bg / baogao 可匹配「报告」）
+func (a *App) AutocompleteTags(query string, limit int) ([]api.TagSuggestion, error) {
 	if a.db == nil {
 		return nil, errors.New("数据库尚未准备就绪")
 	}
-	tag, err := a.db.CreateTag(a.ctx, name, color, parentID)
+
+	matches, err := a.db.AutocompleteTags(a.ctx, query, limit)
 	if err != nil {
 		if a.logger != nil {
-			a.logger.Error("创建标签失败", zap.String("name", name), zap.Error(err))
+			a.logger.Error("联想标签失败", zap.String("query", query), zap.Error(err))
 		}
 		return nil, err
 	}
-	apiTag := toAPITag(*tag)
-	return &apiTag, nil
-}
 
-// DeleteTag 删除标签
-func (a *App) DeleteTag(id int64) error {
-	if a.db == nil {
-		return errors.New("数据库尚未准备就绪")
-	}
-	if err := a.db.DeleteTag(a.ctx, id); err != nil {
-		if a.logger != nil {
-			a.logger.Error("删除标签失败", zap.Int64("tag_id", id), zap.Error(err))
+	result := make([]api.TagSuggestion, 0, len(matches))
+	for _, match := range matches {
+		kind := "contains"
+		switch match.Rank {
+		case data.TagMatchExact:
+			kind = "exact"
+		case data.TagMatchPrefix:
+			kind = "prefix"
+		case data.TagMatchInitials:
+			kind = "initials"
 		}
-		return err
+		result = append(result, api.TagSuggestion{Tag: toAPITag(match.Tag), Match: kind})
 	}
-	return nil
+	return result, nil
 }
 
-// AddTagToFile 为文件添加标签并重命名文件
-func (a *App) AddTagToFile(fileID, tagID int64) error {
+// SuggestTags 根据标签共现、同目录文件与名称相似的文件为文件推荐标签，附带推荐理由
+func (a *App) SuggestTags(fileID int64, limit int) ([]api.SuggestedTag, error) {
 	if a.db == nil {
-		return errors.New("数据库尚未准备就绪")
+		return nil, errors.New("数据库尚未准备就绪")
 	}
-	if err := a.db.AddTagToFile(a.ctx, fileID, tagID); err != nil {
+
+	scores, err := a.db.SuggestTags(a.ctx, fileID, limit)
+	if err != nil {
 		if a.logger != nil {
-			a.logger.Error("文件打标签失败", zap.Int64("file_id", fileID), zap.Int64("tag_id", tagID), zap.Error(err))
+			a.logger.Error("推荐标签失败", zap.Int64("file_id", fileID), zap.Error(err))
 		}
-		return err
+		return nil, err
 	}
 
-	// 添加标签后重命名文件
-	if err := a.RenameFileWithTags(fileID); err != nil {
-		if a.logger != nil {
-			a.logger.Warn("添加标签后重命名文件失败", zap.Int64("file_id", fileID), zap.Error(err))
+	result := make([]api.SuggestedTag, 0, len(scores))
+	for _, score := range scores {
+		item := api.SuggestedTag{
+			Tag:     toAPITag(score.Tag),
+			Score:   score.Score,
+			Reasons: make([]api.TagSuggestionReason, 0, len(score.Reasons)),
 		}
-		// 重命名失败不影响标签添加的成功
+		for _, reason := range score.Reasons {
+			r := api.TagSuggestionReason{
+				Kind:    reason.Kind,
+				Count:   reason.Count,
+				Total:   reason.Total,
+				Keyword: reason.Keyword,
+			}
+			switch reason.Kind {
+			case data.SuggestByCooccurrence:
+				related := toAPITag(*reason.RelatedTag)
+				r.RelatedTag = &related
+				r.Message = fmt.Sprintf("带有「%s」的 %d 个文件中有 %d 个同时使用了该标签", related.Name, reason.Total, reason.Count)
+			case data.SuggestBySibling:
+				r.Message = fmt.Sprintf("同一文件夹中 %d 个文件有 %d 个使用了该标签", reason.Total, reason.Count)
+			case data.SuggestBySimilarName:
+				r.Message = fmt.Sprintf("名称包含「%s」的 %d 个文件中有 %d 个使用了该标签", reason.Keyword, reason.Total, reason.Count)
+			}
+			item.Reasons = append(item.Reasons, r)
+		}
+		result = append(result, item)
 	}
-
-	return nil
+	return result, nil
 }
 
-// RemoveTagFromFile 移除文件标签并重命名文件
-func (a *App) RemoveTagFromFile(fileID, tagID int64) error {
+// CreateTag 创建新标签
+func (a *App) CreateTag(name, color string, parentID *int64) (*api.Tag, error) {
 	if a.db == nil {
-		return errors.New("数据库尚未准备就绪")
+		return nil, errors.New("数据库尚未准备就绪")
 	}
-	if err := a.db.RemoveTagFromFile(a.ctx, fileID, tagID); err != nil {
+	tag, err := a.db.CreateTag(a.ctx, name, color, parentID)
+	if err != nil {
 		if a.logger != nil {
-			a.logger.Error("移除文件标签失败", zap.Int64("file_id", fileID), zap.Int64("tag_id", tagID), zap.Error(err))
+			a.logger.Error("创建标签失败", zap.String("name", name), zap.Error(err))
 		}
-		return err
+		return nil, err
 	}
+	apiTag := toAPITag(*tag)
+	return &apiTag, nil
+}
 
-	// 移除标签后重命名文件
-	if err := a.RenameFileWithTags(fileID); err != nil {
-		if a.logger != nil {
-			a.logger.Warn("移除标签后重命名文件失败", zap.Int64("file_id", fileID), zap.Error(err))
-		}
-		// 重命名失败不影响标签移除的成功
-	}
+// AddTagToFile 为文件添加标签并重命名文件，返回重命名结果
+func (a *App) AddTagToFile(fileID, tagID int64) (*api.TagRenameResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.updateSingleFileTags(data.TagBatchAdd, fileID, []int64{tagID})
+}
 
-	return nil
+// RemoveTagFromFile 移除文件标签并重命名文件，返回重命名结果
+func (a *App) RemoveTagFromFile(fileID, tagID int64) (*api.TagRenameResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.updateSingleFileTags(data.TagBatchRemove, fileID, []int64{tagID})
 }
 
-// ClearAllTagsFromFile 清除文件的所有标签并重命名文件
-func (a *App) ClearAllTagsFromFile(fileID int64) error {
-	if a.db == nil {
-		return errors.New("数据库尚未准备就绪")
-	}
-	if err := a.db.ClearAllTagsFromFile(a.ctx, fileID); err != nil {
-		if a.logger != nil {
-			a.logger.Error("清除文件所有标签失败", zap.Int64("file_id", fileID), zap.Error(err))
-		}
-		return err
-	}
+// ClearAllTagsFromFile 清除文件的所有标签并重命名文件（移除文件名中的标签部分），返回重命名结果
+func (a *App) ClearAllTagsFromFile(fileID int64) (*api.TagRenameResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.updateSingleFileTags(data.TagBatchClear, fileID, nil)
+}
 
-	// 清除标签后重命名文件（移除文件名中的标签部分）
-	if err := a.RenameFileWithTags(fileID); err != nil {
-		if a.logger != nil {
-			a.logger.Warn("清除标签后重命名文件失败", zap.Int64("file_id", fileID), zap.Error(err))
-		}
-		// 重命名失败不影响标签清除的成功
+// renameAfterTagChange 标签变更后同步文件名，失败时记录警告并返回 failed 结果
+func (a *App) renameAfterTagChange(fileID int64, record *api.FileChangeRecord, warnMsg string) *api.TagRenameResult {
+	result, err := a.renameFileWithTags(fileID, record)
+	if err == nil {
+		return result
 	}
 
 	if a.logger != nil {
-		a.logger.Info("已清除文件所有标签", zap.Int64("file_id", fileID))
+		a.logger.Warn(warnMsg, zap.Int64("file_id", fileID), zap.Error(err))
 	}
-
-	return nil
+	if result == nil {
+		result = &api.TagRenameResult{FileID: fileID}
+	}
+	result.Status = "failed"
+	result.Message = err.Error()
+	return result
 }
 
 // parseTagsFromFileName 从文件名中解析标签，支持多种格式
@@ -1048,7 +1221,7 @@ func (a *App) parseTagsFromFileName(fileName string) []string {
 	}
 
 	// 如果有自定义格式，也加入检测
-	if a.settings.TagRule.Format == "custom" && a.settings.TagRule.CustomFormat != nil {
+	if a.settings != nil && a.settings.TagRule.Format == "custom" && a.settings.TagRule.CustomFormat != nil {
 		formats = append(formats, struct {
 			name      string
 			prefix    string
@@ -1146,9 +1319,9 @@ func (a *App) parseIndividualTags(nameWithoutExt string, format struct {
 				break
 			}
 
-			tagName := remaining[len(format.prefix):endIdx]
+			tagName := strings.TrimSpace(remaining[len(format.prefix):endIdx])
 			if tagName != "" {
-				tags = append(tags, strings.TrimSpace(tagName))
+				tags = append(tags, tagName)
 			}
 
 			remaining = remaining[endIdx+len(format.suffix):]
@@ -1163,10 +1336,10 @@ func (a *App) parseIndividualTags(nameWithoutExt string, format struct {
 				break
 			}
 
-			tagName := remaining[startIdx+len(format.prefix) : len(remaining)-len(format.suffix)]
+			tagName := strings.TrimSpace(remaining[startIdx+len(format.prefix) : len(remaining)-len(format.suffix)])
 			if tagName != "" {
 				// 因为是从后往前解析，所以要插入到前面
-				tags = append([]string{strings.TrimSpace(tagName)}, tags...)
+				tags = append([]string{tagName}, tags...)
 			}
 
 			remaining = remaining[:startIdx]
@@ -1175,7 +1348,11 @@ func (a *App) parseIndividualTags(nameWithoutExt string, format struct {
 		}
 	}
 
-	return tags
+	// 只有一个包含分隔符的标签块时是组合显示（如 [标签1, 标签2]），交由组合格式解析
+	if len(tags) == 1 && a.settings.TagRule.Grouping != "individual" && format.separator != "" && strings.Contains(tags[0], format.separator) {
+		return nil
+	}
+	return dropOverflowMarker(tags)
 }
 
 // splitTags 分割标签字符串
@@ -1188,7 +1365,7 @@ func (a *App) splitTags(tagsPart, separator string) []string {
 			tags = append(tags, cleaned)
 		}
 	}
-	return tags
+	return dropOverflowMarker(tags)
 }
 
 // getCleanFileName 获取不带标签的文件名
@@ -1202,63 +1379,29 @@ func (a *App) getCleanFileName(fileName string) string {
 	return nameWithoutExt + ext
 }
 
-// generateFileNameWithTags 生成带标签的文件名
+// generateFileNameWithTags 生成带标签的文件名（超出长度限制时按溢出策略处理）
 func (a *App) generateFileNameWithTags(originalName string, tags []data.Tag) string {
-	// 分离文件名和扩展名
-	ext := filepath.Ext(originalName)
-	nameWithoutExt := strings.TrimSuffix(originalName, ext)
-
-	if a.logger != nil {
-		a.logger.Debug("开始生成带标签的文件名",
-			zap.String("original_name", originalName),
-			zap.Int("tag_count", len(tags)),
-		)
-	}
-
-	// 完全移除现有的标签部分
-	cleanName := a.removeTagsFromFileName(nameWithoutExt)
-
-	if a.logger != nil {
-		a.logger.Debug("清理后的文件名",
-			zap.String("original", nameWithoutExt),
-			zap.String("cleaned", cleanName),
-		)
-	}
-
-	// 如果没有标签，返回不带标签的文件名
-	if len(tags) == 0 {
-		return cleanName + ext
-	}
-
-	// 根据设置生成标签字符串
-	tagStr := a.formatTagsText(tags)
-	if tagStr == "" {
-		return cleanName + ext
-	}
-
-	// 根据设置应用标签到文件名
-	space := ""
-	if a.settings.TagRule.AddSpaces {
-		space = " "
-	}
-
-	var result string
-	if a.settings.TagRule.Position == "prefix" {
-		result = tagStr + space + cleanName
-	} else {
-		result = cleanName + space + tagStr
+	result, err := a.buildFileNameWithTags(originalName, tags)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Warn("生成带标签的文件名失败，保留原文件名",
+				zap.String("original_name", originalName),
+				zap.Int("tag_count", len(tags)),
+				zap.Error(err),
+			)
+		}
+		return originalName
 	}
 
-	finalName := result + ext
-
 	if a.logger != nil {
 		a.logger.Debug("生成的最终文件名",
-			zap.String("final_name", finalName),
-			zap.String("tag_str", tagStr),
+			zap.String("original_name", originalName),
+			zap.String("final_name", result.Name),
+			zap.Int("overflow_count", len(result.Overflow)),
 		)
 	}
 
-	return finalName
+	return result.Name
 }
 
 // formatTagsText 根据设置格式化标签文本
@@ -1267,7 +1410,10 @@ func (a *App) formatTagsText(tags []data.Tag) string {
 		return ""
 	}
 
-	config := a.settings.TagRule
+	var config api.TagRuleConfig
+	if a.settings != nil {
+		config = a.settings.TagRule
+	}
 
 	// 获取格式设置
 	var prefix, suffix, separator string
@@ -1398,7 +1544,7 @@ func (a *App) removeTagsFromFileName(nameWithoutExt string) string {
 	}
 
 	// 如果有自定义格式，也加入检测
-	if a.settings.TagRule.Format == "custom" && a.settings.TagRule.CustomFormat != nil {
+	if a.settings != nil && a.settings.TagRule.Format == "custom" && a.settings.TagRule.CustomFormat != nil {
 		formats = append(formats, struct {
 			name   string
 			prefix string
@@ -1522,54 +1668,119 @@ func (a *App) isValidTagContent(content, prefix, suffix string) bool {
 	return !strings.Contains(content, prefix) && !strings.Contains(content, suffix)
 }
 
-// RenameFileWithTags 根据标签重命名文件
-func (a *App) RenameFileWithTags(fileID int64) error {
+// RenameFileWithTags 根据标签重命名文件，返回单个文件的处理结果
+func (a *App) RenameFileWithTags(fileID int64) (*api.TagRenameResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	record := api.FileChangeRecord{FileID: fileID}
+	result, err := a.renameFileWithTags(fileID, &record)
+	if err == nil && record.From != "" {
+		a.recordOperation(data.OperationRename, a.currentWorkspace.ID,
+			fmt.Sprintf("按标签重命名「%s」", result.OldName),
+			api.FileOperationPayload{
+				WorkspaceID: a.currentWorkspace.ID,
+				Action:      renameActionApplyTags,
+				Changes:     []api.FileChangeRecord{record},
+			},
+		)
+	}
+	return result, err
+}
+
+// renameFileWithTags 按标签生成文件名并重命名，发生重命名时把前后路径写入 record
+func (a *App) renameFileWithTags(fileID int64, record *api.FileChangeRecord) (*api.TagRenameResult, error) {
 	if a.db == nil {
-		return errors.New("数据库尚未准备就绪")
+		return nil, errors.New("数据库尚未准备就绪")
 	}
 	if a.currentWorkspace == nil {
-		return errors.New("尚未选择工作区")
+		return nil, errors.New("尚未选择工作区")
 	}
 
 	// 获取文件信息（包含标签）
 	file, err := a.db.GetFileByID(a.ctx, fileID)
 	if err != nil {
-		return fmt.Errorf("获取文件信息失败: %w", err)
+		return nil, fmt.Errorf("获取文件信息失败: %w", err)
 	}
 
-	// 记录原始文件名用于日志
-	originalName := file.Name
+	result := &api.TagRenameResult{
+		FileID:  fileID,
+		OldName: file.Name,
+		NewName: file.Name,
+	}
 
-	// 生成新的文件名（会自动移除旧格式标签并应用新格式）
-	newName := a.generateFileNameWithTags(file.Name, file.Tags)
+	// 生成新的文件名（会自动移除旧格式标签并应用新格式，并遵守文件名长度与保留名规则）
+	encoded, err := a.buildFileNameWithTags(file.Name, file.Tags)
+	if err != nil {
+		result.Status = "failed"
+		result.Message = err.Error()
+		return result, err
+	}
+	result.EmbeddedTags = len(encoded.Embedded)
+	for _, tag := range encoded.Overflow {
+		result.OverflowTags = append(result.OverflowTags, tag.Name)
+	}
+	if len(result.OverflowTags) > 0 {
+		result.Message = fmt.Sprintf("文件名长度受限，%d 个标签仅保存在数据库中", len(result.OverflowTags))
+	}
 
 	// 如果文件名没有变化，直接返回
-	if newName == file.Name {
+	if encoded.Name == file.Name {
 		if a.logger != nil {
 			a.logger.Debug("文件名无需更改",
 				zap.Int64("file_id", fileID),
 				zap.String("file_name", file.Name),
 			)
 		}
-		return nil
+		result.Status = "unchanged"
+		return result, nil
 	}
 
 	if a.logger != nil {
 		a.logger.Info("应用新标签格式重命名文件",
 			zap.Int64("file_id", fileID),
-			zap.String("original_name", originalName),
-			zap.String("new_name", newName),
+			zap.String("original_name", file.Name),
+			zap.String("new_name", encoded.Name),
 			zap.String("tag_format", a.settings.TagRule.Format),
 			zap.String("tag_position", a.settings.TagRule.Position),
+			zap.Int("overflow_count", len(encoded.Overflow)),
 		)
 	}
 
 	// 重命名文件
-	return a.RenameFile(fileID, newName)
+	if err := a.renameFile(fileID, encoded.Name, record); err != nil {
+		result.Status = "failed"
+		result.Message = err.Error()
+		return result, err
+	}
+
+	result.Status = "renamed"
+	result.NewName = encoded.Name
+	return result, nil
 }
 
 // RenameFile 重命名文件并更新数据库
 func (a *App) RenameFile(fileID int64, newName string) error {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	record := api.FileChangeRecord{FileID: fileID}
+	if err := a.renameFile(fileID, newName, &record); err != nil {
+		return err
+	}
+	a.recordOperation(data.OperationRename, a.currentWorkspace.ID,
+		fmt.Sprintf("重命名「%s」为「%s」", filepath.Base(record.From), newName),
+		api.FileOperationPayload{
+			WorkspaceID: a.currentWorkspace.ID,
+			Action:      renameActionRename,
+			Changes:     []api.FileChangeRecord{record},
+		},
+	)
+	return nil
+}
+
+// renameFile 在文件所在目录内重命名文件并同步数据库，成功后把前后相对路径写入 record
+func (a *App) renameFile(fileID int64, newName string, record *api.FileChangeRecord) error {
 	if a.db == nil {
 		return errors.New("数据库尚未准备就绪")
 	}
@@ -1585,6 +1796,9 @@ func (a *App) RenameFile(fileID int64, newName string) error {
 	if err != nil {
 		return fmt.Errorf("获取文件信息失败: %w", err)
 	}
+	if file.WorkspaceID != a.currentWorkspace.ID {
+		return errors.New("文件不属于当前工作区")
+	}
 
 	// 构建完整路径
 	oldPath := filepath.Join(a.currentWorkspace.Path, file.Path)
@@ -1596,7 +1810,8 @@ func (a *App) RenameFile(fileID int64, newName string) error {
 	}
 
 	// 重命名文件
-	if err := os.Rename(oldPath, newPath); err != nil {
+	copied, err := a.moveFile(oldPath, newPath, file.Path)
+	if err != nil {
 		return fmt.Errorf("重命名文件失败: %w", err)
 	}
 
@@ -1604,16 +1819,20 @@ func (a *App) RenameFile(fileID int64, newName string) error {
 	newRelPath, err := filepath.Rel(a.currentWorkspace.Path, newPath)
 	if err != nil {
 		// 如果更新数据库失败，尝试回滚文件重命名
-		_ = os.Rename(newPath, oldPath)
+		_, _ = a.moveFile(newPath, oldPath, file.Path)
 		return fmt.Errorf("计算相对路径失败: %w", err)
 	}
 
 	if err := a.db.UpdateFileName(a.ctx, fileID, newName, newRelPath); err != nil {
 		// 如果更新数据库失败，尝试回滚文件重命名
-		_ = os.Rename(newPath, oldPath)
+		_, _ = a.moveFile(newPath, oldPath, file.Path)
 		return fmt.Errorf("更新数据库失败: %w", err)
 	}
 
+	record.From = filepath.ToSlash(file.Path)
+	record.To = filepath.ToSlash(newRelPath)
+	record.Copied = copied
+
 	if a.logger != nil {
 		a.logger.Info("文件重命名成功",
 			zap.Int64("file_id", fileID),
@@ -1627,7 +1846,7 @@ func (a *App) RenameFile(fileID int64, newName string) error {
 
 // PreviewOrganize 生成整理预览
 func (a *App) PreviewOrganize(req api.OrganizeRequest) (*api.OrganizePreview, error) {
-	plan, err := a.buildOrganizePlan(req)
+	plan, err := a.buildOrganizePlan(a.currentWorkspace, req, "", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1636,11 +1855,29 @@ func (a *App) PreviewOrganize(req api.OrganizeRequest) (*api.OrganizePreview, er
 
 // ExecuteOrganize 执行整理并记录可撤销操作
 func (a *App) ExecuteOrganize(req api.OrganizeRequest) (*api.OrganizeResult, error) {
-	plan, err := a.buildOrganizePlan(req)
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	ws := a.currentWorkspace
+	plan, err := a.buildOrganizePlan(ws, req, "", nil)
 	if err != nil {
 		return nil, err
 	}
+	return a.executeOrganizePlan(ws, plan, organizeRun{
+		action:      organizeActionOrganize,
+		removeEmpty: req.RemoveEmptyFolders,
+		keepFolder:  req.SourceFolder,
+	})
+}
 
+// executeOrganizePlan 在工作区 ws 中按计划移动文件（整理、展平与自动整理共用），先写入意图日志，失败时回滚；
+// run.removeEmpty 为 true 时删除因移动而变空的源文件夹，撤销时删除本次创建的文件夹
+//
+// 调用方需持有 opMu，计划须由同一工作区生成。
+func (a *App) executeOrganizePlan(ws *data.Workspace, plan *api.OrganizePreview, run organizeRun) (*api.OrganizeResult, error) {
+	if ws == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
 	if plan.Summary.ConflictCount > 0 {
 		return nil, fmt.Errorf("存在 %d 个冲突，需先解决后再执行", plan.Summary.ConflictCount)
 	}
@@ -1648,123 +1885,204 @@ func (a *App) ExecuteOrganize(req api.OrganizeRequest) (*api.OrganizeResult, err
 		return &api.OrganizeResult{Preview: *plan}, nil
 	}
 
-	executed := make([]api.OrganizeMoveRecord, 0, plan.Summary.MoveCount)
-	for _, item := range plan.Items {
+	// 先写入完整的移动意图，异常退出后可据此继续或回滚；
+	// 替换目标位置的文件时，先把原有文件移入隔离区，再移动本文件
+	var steps []organizeStep
+	for i := range plan.Items {
+		item := &plan.Items[i]
 		if item.Status != "move" {
 			continue
 		}
-		record, moveErr := a.performOrganizeMove(item)
+		if item.QuarantinePath != "" {
+			steps = append(steps, organizeStep{record: api.OrganizeMoveRecord{
+				FileID: item.ReplacedFileID, From: item.ReplacedPath, To: item.QuarantinePath,
+			}})
+		}
+		steps = append(steps, organizeStep{item: item, record: api.OrganizeMoveRecord{
+			FileID: item.FileID, From: item.OriginalPath, To: item.TargetPath,
+		}})
+	}
+	payload := api.OrganizeOperationPayload{
+		Action:             run.action,
+		RuleID:             run.ruleID,
+		WorkspaceID:        ws.ID,
+		Moves:              make([]api.OrganizeMoveRecord, 0, len(steps)),
+		RemoveEmptyFolders: run.removeEmpty,
+		KeepFolder:         run.keepFolder,
+		QuarantineDir:      plan.QuarantineDir,
+	}
+	moves := make([]data.OperationMove, 0, len(steps))
+	seenDirs := make(map[string]bool)
+	for seq, step := range steps {
+		payload.Moves = append(payload.Moves, step.record)
+		payload.CreatedDirs = append(payload.CreatedDirs, missingParentDirs(ws.Path, step.record.To, seenDirs)...)
+		moves = append(moves, data.OperationMove{Seq: seq, FileID: step.record.FileID, From: step.record.From, To: step.record.To})
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化整理记录失败: %w", err)
+	}
+	opID, err := a.db.BeginOperation(a.ctx, ws.ID, data.OperationOrganize,
+		fmt.Sprintf("%s %d 个文件", organizeActionLabel(run.action), plan.Summary.MoveCount), string(raw), moves)
+	if err != nil {
+		return nil, fmt.Errorf("写入整理记录失败: %w", err)
+	}
+
+	executed := make([]api.OrganizeMoveRecord, 0, len(steps))
+	copied := 0
+	for seq, step := range steps {
+		record := step.record
+		var moveErr error
+		if step.item != nil {
+			record, moveErr = a.performOrganizeMove(ws.Path, *step.item)
+		} else {
+			record.Copied, moveErr = a.moveFileInWorkspace(ws.Path, record.FileID, record.From, record.To)
+		}
 		if moveErr != nil {
-			// 回滚已执行的移动，保持一致性
+			a.setOrganizeMoveState(opID, seq, data.MoveStateFailed, moveErr.Error())
+			// 回滚已执行的移动，保持一致性；全部回滚成功则整理未留下任何变化
+			rolledBack := true
 			for i := len(executed) - 1; i >= 0; i-- {
-				_ = a.rollbackOrganizeMove(executed[i])
+				if _, err := a.moveFileInWorkspace(ws.Path, executed[i].FileID, executed[i].To, executed[i].From); err != nil {
+					rolledBack = false
+					a.setOrganizeMoveState(opID, i, data.MoveStateFailed, err.Error())
+					continue
+				}
+				a.setOrganizeMoveState(opID, i, data.MoveStateRolledBack, "")
+			}
+			if !rolledBack {
+				return nil, fmt.Errorf("%w（部分文件未能回滚，可在未完成的整理中继续或回滚）", moveErr)
+			}
+			if err := a.db.DeleteOperation(a.ctx, opID); err != nil && a.logger != nil {
+				a.logger.Warn("删除已回滚的整理记录失败", zap.Int64("operation_id", opID), zap.Error(err))
 			}
 			return nil, moveErr
 		}
+		a.setOrganizeMoveState(opID, seq, data.MoveStateDone, "")
 		executed = append(executed, record)
+		if record.Copied {
+			copied++
+		}
 	}
 
-	payload := api.OrganizeOperationPayload{
-		WorkspaceID: a.currentWorkspace.ID,
-		Moves:       executed,
+	a.ensureFolderRecords(ws, payload.CreatedDirs)
+	var removed []string
+	if run.removeEmpty {
+		payload.Moves = executed
+		removed = a.removeEmptyFolders(ws, removableSourceFolders(payload))
 	}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("序列化整理记录失败: %w", err)
+
+	// 记录跨设备复制的文件与删除的空文件夹，撤销时据此处理
+	if copied > 0 || len(removed) > 0 {
+		payload.Moves = executed
+		payload.RemovedDirs = removed
+		raw, err := json.Marshal(payload)
+		if err == nil {
+			err = a.db.SetOperationPayload(a.ctx, opID, string(raw))
+		}
+		if err != nil && a.logger != nil {
+			a.logger.Warn("更新整理记录失败", zap.Int64("operation_id", opID), zap.Error(err))
+		}
 	}
 
-	opID, err := a.db.InsertOperation(a.ctx, "organize", string(data))
-	if err != nil {
-		return nil, fmt.Errorf("写入整理记录失败: %w", err)
+	// 文件均已移动完成，状态更新失败时记录会留在未完成列表中，继续执行即可补齐
+	if err := a.db.FinishOperation(a.ctx, opID, data.OperationApplied); err != nil && a.logger != nil {
+		a.logger.Warn("更新整理记录状态失败", zap.Int64("operation_id", opID), zap.Error(err))
 	}
 
 	if a.logger != nil {
-		a.logger.Info("一键整理完成",
-			zap.Int("moved", len(executed)),
+		a.logger.Info("整理完成",
+			zap.String("action", run.action),
+			zap.Int("moved", plan.Summary.MoveCount),
+			zap.Int("quarantined", len(executed)-plan.Summary.MoveCount),
+			zap.Int("copied", copied),
+			zap.Int("removed_folders", len(removed)),
 			zap.Int64("operation_id", opID),
 		)
 	}
 
 	return &api.OrganizeResult{
-		Preview:     *plan,
-		OperationID: opID,
+		Preview:        *plan,
+		OperationID:    opID,
+		RemovedFolders: removed,
 	}, nil
 }
 
-// UndoOrganize 撤销整理
+// UndoOrganize 撤销整理（操作日志中的 Undo 的整理专用入口）
 func (a *App) UndoOrganize(operationID int64) (*api.OrganizeUndoResult, error) {
 	if a.db == nil {
 		return nil, errors.New("数据库尚未准备就绪")
 	}
-	if a.currentWorkspace == nil {
-		return nil, errors.New("尚未选择工作区")
-	}
-	if operationID <= 0 {
-		return nil, errors.New("无效的操作 ID")
-	}
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
 
 	op, err := a.db.GetOperation(a.ctx, operationID)
 	if err != nil {
 		return nil, err
 	}
-	if op.Type != "organize" {
+	if op.Type != data.OperationOrganize {
 		return nil, errors.New("操作类型不匹配，无法撤销")
 	}
 
-	var payload api.OrganizeOperationPayload
-	if err := json.Unmarshal([]byte(op.Payload), &payload); err != nil {
-		return nil, fmt.Errorf("解析整理记录失败: %w", err)
-	}
-	if payload.WorkspaceID != a.currentWorkspace.ID {
-		return nil, errors.New("当前工作区与整理记录不一致，请先切换到原工作区")
-	}
-
-	result := &api.OrganizeUndoResult{}
-	for i := len(payload.Moves) - 1; i >= 0; i-- {
-		if err := a.rollbackOrganizeMove(payload.Moves[i]); err != nil {
-			result.Failed++
-			if a.logger != nil {
-				a.logger.Warn("撤销整理失败",
-					zap.Int64("file_id", payload.Moves[i].FileID),
-					zap.Error(err),
-				)
-			}
-		} else {
-			result.Restored++
-		}
+	replay, err := a.replayOperation(operationID, true)
+	if err != nil {
+		return nil, err
 	}
-
-	// 撤销成功后删除记录，失败则保留便于重试
-	if result.Failed == 0 {
-		_ = a.db.DeleteOperation(a.ctx, operationID)
+	result := &api.OrganizeUndoResult{
+		Restored:       replay.Succeeded + replay.Skipped,
+		Failed:         replay.Failed + len(replay.Conflicts),
+		Message:        replay.Message,
+		RemovedFolders: replay.RemovedFolders,
 	}
-
 	return result, nil
 }
 
 // buildOrganizePlan 根据请求生成整理计划（不触磁盘）
-func (a *App) buildOrganizePlan(req api.OrganizeRequest) (*api.OrganizePreview, error) {
+//
+// 先为全部文件计算目标路径，再按请求的冲突策略逐个解决冲突，预览即为实际执行的内容。
+// viewRoot 不为空时目标路径相对该整理视图目录，全部相关文件都会生成链接；
+// skip 不为空时跳过其返回 true 的文件（自动整理用来跳过仍在写入的文件）。
+// 工作区由调用方传入，生成计划期间不再读取当前工作区。
+func (a *App) buildOrganizePlan(ws *data.Workspace, req api.OrganizeRequest, viewRoot string, skip func(file data.FileRecord) bool) (*api.OrganizePreview, error) {
 	if a.db == nil {
 		return nil, errors.New("数据库尚未准备就绪")
 	}
-	if a.currentWorkspace == nil {
+	if ws == nil {
 		return nil, errors.New("尚未选择工作区")
 	}
-	if len(req.Levels) == 0 {
-		return nil, errors.New("至少需要一个层级")
+	if len(req.Levels) == 0 && strings.TrimSpace(req.Template) == "" {
+		return nil, errors.New("至少需要一个层级或路径模板")
 	}
 
 	required := make(map[int64]struct{})
+	hasFallback := false
 	for idx, level := range req.Levels {
-		if len(level.TagIDs) == 0 {
-			return nil, fmt.Errorf("第 %d 级至少选择一个标签", idx+1)
+		tagIDs, err := validateOrganizeLevel(idx, level)
+		if err != nil {
+			return nil, err
 		}
-		for _, tagID := range level.TagIDs {
-			if tagID <= 0 {
-				return nil, fmt.Errorf("第 %d 级存在无效的标签 ID", idx+1)
-			}
+		for _, tagID := range tagIDs {
 			required[tagID] = struct{}{}
 		}
+		// 只有标签层级的兜底目录会把没有相关标签的文件纳入整理
+		if len(tagIDs) > 0 && level.Fallback != "" {
+			hasFallback = true
+		}
+	}
+	sourceFolder, err := cleanWorkspaceDir(req.SourceFolder)
+	if err != nil {
+		return nil, fmt.Errorf("整理范围无效: %w", err)
+	}
+	if sourceFolder != "" && !dirExists(filepath.Join(ws.Path, filepath.FromSlash(sourceFolder))) {
+		return nil, fmt.Errorf("文件夹不存在: %s", sourceFolder)
+	}
+	targetRoot := ws.Path
+	if viewRoot != "" {
+		targetRoot = viewRoot
+	}
+	resolver, err := newOrganizeResolver(a, ws.ID, req.ConflictStrategy, req.QuarantineFolder, targetRoot)
+	if err != nil {
+		return nil, err
 	}
 
 	// 准备标签名称映射
@@ -1782,17 +2100,22 @@ func (a *App) buildOrganizePlan(req api.OrganizeRequest) (*api.OrganizePreview,
 		}
 	}
 
+	tmpl, groups, err := compileOrganizeTemplate(req, tags)
+	if err != nil {
+		return nil, err
+	}
+
 	plan := &api.OrganizePreview{
 		Items:    make([]api.OrganizePreviewItem, 0),
 		Summary:  api.OrganizeSummary{},
-		BasePath: a.currentWorkspace.Path,
+		BasePath: targetRoot,
+		Template: tmpl.Source,
 	}
-	targetUsed := make(map[string]int64)
 
 	const batchSize = 500
 	offset := 0
 	for {
-		page, err := a.db.ListFiles(a.ctx, a.currentWorkspace.ID, batchSize, offset)
+		page, err := a.db.ListFiles(a.ctx, ws.ID, batchSize, offset)
 		if err != nil {
 			return nil, fmt.Errorf("获取文件列表失败: %w", err)
 		}
@@ -1801,19 +2124,31 @@ func (a *App) buildOrganizePlan(req api.OrganizeRequest) (*api.OrganizePreview,
 		}
 
 		for _, file := range page.Records {
-			if file.Type != data.FileTypeRegular {
+			if file.Type != data.FileTypeRegular || resolver.inQuarantine(file.Path) {
+				continue
+			}
+			if sourceFolder != "" && !withinAnyFolder(filepath.ToSlash(file.Path), []string{sourceFolder}) {
+				continue
+			}
+			if skip != nil && skip(file) {
 				continue
 			}
 
 			tagSet := make(map[int64]bool, len(file.Tags))
 			tagNames := make([]string, 0, len(file.Tags))
+			groupValues := make(map[string][]string, len(groups))
 			for _, tag := range file.Tags {
 				tagSet[tag.ID] = true
 				tagNames = append(tagNames, tag.Name)
+				for name, groupID := range groups {
+					if tag.ParentID.Valid && tag.ParentID.Int64 == groupID {
+						groupValues[name] = append(groupValues[name], tag.Name)
+					}
+				}
 			}
 
-			// 跳过完全不相关的文件
-			hasRelevant := false
+			// 跳过完全不相关的文件；层级与模板都不涉及标签（例如只按文件属性分组）或标签层级设置了兜底目录时整理全部文件
+			hasRelevant := (len(required) == 0 && len(groups) == 0) || hasFallback || len(groupValues) > 0
 			for tagID := range required {
 				if tagSet[tagID] {
 					hasRelevant = true
@@ -1830,70 +2165,60 @@ func (a *App) buildOrganizePlan(req api.OrganizeRequest) (*api.OrganizePreview,
 				Tags:         tagNames,
 			}
 
+			info := &organizeFile{
+				relPath: item.OriginalPath,
+				absPath: filepath.Join(ws.Path, file.Path),
+				size:    file.Size,
+				modTime: file.ModTime,
+				tagSet:  tagSet,
+			}
 			var missing []string
+			levelNames := make([]string, 0, len(req.Levels))
 			for _, level := range req.Levels {
-				for _, tagID := range level.TagIDs {
-					if !tagSet[tagID] {
-						missing = append(missing, tagNameMap[tagID])
-					}
-				}
+				name, levelMissing := organizeLevelFolder(level, info, tagNameMap)
+				missing = append(missing, levelMissing...)
+				levelNames = append(levelNames, name)
 			}
 			if len(missing) > 0 {
 				item.Status = "skip_missing_tags"
 				item.MissingTags = missing
-				plan.Summary.SkipCount++
-				plan.Summary.Total++
 				plan.Items = append(plan.Items, item)
 				continue
 			}
 
-			var segments []string
-			for _, level := range req.Levels {
-				names := make([]string, 0, len(level.TagIDs))
-				for _, tagID := range level.TagIDs {
-					names = append(names, sanitizeFolderSegment(tagNameMap[tagID]))
-				}
-				segments = append(segments, fmt.Sprintf("[%s]", strings.Join(names, "][")))
+			ext := filepath.Ext(file.Name)
+			values := pathtemplate.Values{
+				Levels:    levelNames,
+				TagGroups: make(map[string]string, len(groupValues)),
+				Name:      strings.TrimSuffix(file.Name, ext),
+				Ext:       ext,
+				ModTime:   file.ModTime.Local(),
 			}
-
-			folderPath := filepath.Join(segments...)
-			targetRelPath := filepath.ToSlash(filepath.Join(folderPath, file.Name))
-			item.TargetPath = targetRelPath
-
-			if targetRelPath == item.OriginalPath {
-				item.Status = "already_in_place"
-				plan.Summary.AlreadyInPlace++
-				plan.Summary.Total++
-				plan.Items = append(plan.Items, item)
-				continue
+			for name, tagValues := range groupValues {
+				values.TagGroups[name] = strings.Join(tagValues, "、")
 			}
-
-			if owner, ok := targetUsed[targetRelPath]; ok && owner != file.ID {
-				item.Status = "conflict"
-				item.Message = "目标路径与其他文件冲突"
-				plan.Summary.ConflictCount++
-				plan.Summary.Total++
+			targetRelPath, missingGroups := tmpl.Render(values, sanitizeFolderSegment)
+			if len(missingGroups) > 0 {
+				item.Status = "skip_missing_tags"
+				item.MissingTags = missingGroups
 				plan.Items = append(plan.Items, item)
 				continue
 			}
+			// 模板已拒绝空目录名与 . / ..，这里再确认目标是工作区内规范的相对路径
+			if _, err := cleanWorkspaceDir(targetRelPath); err != nil || path.Clean(targetRelPath) != targetRelPath {
+				return nil, fmt.Errorf("生成的目标路径无效: %q（%s）", targetRelPath, item.OriginalPath)
+			}
+			item.TargetPath = targetRelPath
 
-			targetAbs := filepath.Join(a.currentWorkspace.Path, filepath.FromSlash(targetRelPath))
-			if _, err := os.Stat(targetAbs); err == nil {
-				item.Status = "conflict"
-				item.Message = "目标位置已有同名文件"
-				plan.Summary.ConflictCount++
-				plan.Summary.Total++
+			if viewRoot == "" && targetRelPath == item.OriginalPath {
+				item.Status = "already_in_place"
 				plan.Items = append(plan.Items, item)
 				continue
-			} else if err != nil && !errors.Is(err, os.ErrNotExist) {
-				return nil, fmt.Errorf("检查目标路径失败: %w", err)
 			}
 
 			item.Status = "move"
-			plan.Summary.MoveCount++
-			plan.Summary.Total++
 			plan.Items = append(plan.Items, item)
-			targetUsed[targetRelPath] = file.ID
+			resolver.addCandidate(len(plan.Items)-1, item, file.ModTime, file.Size)
 		}
 
 		if len(page.Records) < batchSize {
@@ -1902,15 +2227,56 @@ func (a *App) buildOrganizePlan(req api.OrganizeRequest) (*api.OrganizePreview,
 		offset += len(page.Records)
 	}
 
+	if err := resolver.resolve(plan.Items); err != nil {
+		return nil, err
+	}
+	for _, item := range plan.Items {
+		if item.Status == "move" && item.QuarantinePath != "" {
+			plan.QuarantineDir = resolver.quarantineDir
+			break
+		}
+	}
+	summarizeOrganizePlan(plan)
 	return plan, nil
 }
 
-// performOrganizeMove 执行单个文件移动
-func (a *App) performOrganizeMove(item api.OrganizePreviewItem) (api.OrganizeMoveRecord, error) {
-	if a.currentWorkspace == nil {
-		return api.OrganizeMoveRecord{}, errors.New("尚未选择工作区")
+// summarizeOrganizePlan 按条目状态重新统计汇总
+func summarizeOrganizePlan(plan *api.OrganizePreview) {
+	summary := api.OrganizeSummary{Total: len(plan.Items)}
+	for _, item := range plan.Items {
+		switch item.Status {
+		case "move":
+			summary.MoveCount++
+		case "conflict":
+			summary.ConflictCount++
+		case "skip_missing_tags", "skip_conflict":
+			summary.SkipCount++
+		case "already_in_place":
+			summary.AlreadyInPlace++
+		}
+		if item.Resolution != "" {
+			summary.ResolvedCount++
+		}
 	}
+	plan.Summary = summary
+}
+
+// organizeRun 执行整理计划的选项
+type organizeRun struct {
+	action      string // organizeActionOrganize/organizeActionFlatten/organizeActionAuto
+	removeEmpty bool
+	keepFolder  string // 整理范围的根文件夹，清理空文件夹时保留
+	ruleID      int64  // 由自动整理规则触发时的规则 ID
+}
+
+// organizeStep 整理中的一步移动：item 为空时表示把目标位置原有的文件移入隔离区
+type organizeStep struct {
+	item   *api.OrganizePreviewItem
+	record api.OrganizeMoveRecord
+}
 
+// performOrganizeMove 在 root 工作区内执行单个文件移动
+func (a *App) performOrganizeMove(root string, item api.OrganizePreviewItem) (api.OrganizeMoveRecord, error) {
 	file, err := a.db.GetFileByID(a.ctx, item.FileID)
 	if err != nil {
 		return api.OrganizeMoveRecord{}, fmt.Errorf("获取文件信息失败: %w", err)
@@ -1919,20 +2285,27 @@ func (a *App) performOrganizeMove(item api.OrganizePreviewItem) (api.OrganizeMov
 		return api.OrganizeMoveRecord{}, fmt.Errorf("文件路径已变化，需重新生成预览: %s", file.Path)
 	}
 
-	srcAbs := filepath.Join(a.currentWorkspace.Path, filepath.FromSlash(item.OriginalPath))
-	dstAbs := filepath.Join(a.currentWorkspace.Path, filepath.FromSlash(item.TargetPath))
+	srcAbs := filepath.Join(root, filepath.FromSlash(item.OriginalPath))
+	dstAbs := filepath.Join(root, filepath.FromSlash(item.TargetPath))
+	// os.Rename 会静默覆盖已有文件，预览之后目标位置新出现的文件需要拦下
+	if dstInfo, err := os.Stat(dstAbs); err == nil {
+		if srcInfo, err := os.Stat(srcAbs); err != nil || !os.SameFile(srcInfo, dstInfo) {
+			return api.OrganizeMoveRecord{}, fmt.Errorf("目标位置已存在文件，需重新生成预览: %s", item.TargetPath)
+		}
+	}
 	if err := os.MkdirAll(filepath.Dir(dstAbs), 0o755); err != nil {
 		return api.OrganizeMoveRecord{}, fmt.Errorf("创建目标目录失败: %w", err)
 	}
 
-	if err := os.Rename(srcAbs, dstAbs); err != nil {
+	copied, err := a.moveFile(srcAbs, dstAbs, item.OriginalPath)
+	if err != nil {
 		return api.OrganizeMoveRecord{}, fmt.Errorf("移动文件失败: %w", err)
 	}
 
 	newName := filepath.Base(dstAbs)
 	newRel := filepath.ToSlash(item.TargetPath)
 	if err := a.db.UpdateFileName(a.ctx, file.ID, newName, newRel); err != nil {
-		_ = os.Rename(dstAbs, srcAbs)
+		_, _ = a.moveFile(dstAbs, srcAbs, item.TargetPath)
 		return api.OrganizeMoveRecord{}, fmt.Errorf("更新数据库失败: %w", err)
 	}
 
@@ -1940,28 +2313,10 @@ func (a *App) performOrganizeMove(item api.OrganizePreviewItem) (api.OrganizeMov
 		FileID: file.ID,
 		From:   item.OriginalPath,
 		To:     item.TargetPath,
+		Copied: copied,
 	}, nil
 }
 
-// rollbackOrganizeMove 回滚单个文件移动
-func (a *App) rollbackOrganizeMove(record api.OrganizeMoveRecord) error {
-	if a.currentWorkspace == nil {
-		return errors.New("尚未选择工作区")
-	}
-
-	srcAbs := filepath.Join(a.currentWorkspace.Path, filepath.FromSlash(record.To))
-	dstAbs := filepath.Join(a.currentWorkspace.Path, filepath.FromSlash(record.From))
-	if err := os.MkdirAll(filepath.Dir(dstAbs), 0o755); err != nil {
-		return fmt.Errorf("创建回滚目录失败: %w", err)
-	}
-	if err := os.Rename(srcAbs, dstAbs); err != nil {
-		return fmt.Errorf("回滚移动失败: %w", err)
-	}
-
-	newName := filepath.Base(dstAbs)
-	return a.db.UpdateFileName(a.ctx, record.FileID, newName, filepath.ToSlash(record.From))
-}
-
 // sanitizeFolderSegment 清理标签名为安全的目录段
 func sanitizeFolderSegment(name string) string {
 	clean := strings.TrimSpace(name)
@@ -2032,9 +2387,27 @@ func toAPIFilePage(page *data.FilePage) *api.FilePage {
 	if page == nil {
 		return &api.FilePage{}
 	}
-	records := make([]api.FileRecord, 0, len(page.Records))
-	for _, record := range page.Records {
-		records = append(records, api.FileRecord{
+	return &api.FilePage{
+		Total:   page.Total,
+		Records: toAPIFileRecords(page.Records),
+	}
+}
+
+func toAPIFileCursorPage(page *data.FileCursorPage) *api.FileCursorPage {
+	if page == nil {
+		return &api.FileCursorPage{}
+	}
+	return &api.FileCursorPage{
+		Records:    toAPIFileRecords(page.Records),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+}
+
+func toAPIFileRecords(records []data.FileRecord) []api.FileRecord {
+	result := make([]api.FileRecord, 0, len(records))
+	for _, record := range records {
+		result = append(result, api.FileRecord{
 			ID:          record.ID,
 			WorkspaceID: record.WorkspaceID,
 			Path:        record.Path,
@@ -2047,11 +2420,7 @@ func toAPIFilePage(page *data.FilePage) *api.FilePage {
 			Tags:        toAPITags(record.Tags),
 		})
 	}
-
-	return &api.FilePage{
-		Total:   page.Total,
-		Records: records,
-	}
+	return result
 }
 
 func toAPITags(tags []data.Tag) []api.Tag {
@@ -2245,7 +2614,7 @@ func (a *App) SearchFilesByTags(params api.FileSearchParams) (*api.FilePage, err
 	if a.currentWorkspace == nil {
 		return nil, errors.New("尚未选择工作区")
 	}
-	if len(params.TagIDs) == 0 {
+	if len(params.TagIDs) == 0 && strings.TrimSpace(params.TagQuery) == "" {
 		return nil, errors.New("至少需要选择一个标签")
 	}
 
@@ -2253,24 +2622,39 @@ func (a *App) SearchFilesByTags(params api.FileSearchParams) (*api.FilePage, err
 		a.logger.Info("按标签搜索文件",
 			zap.Int64("workspace_id", a.currentWorkspace.ID),
 			zap.Int64s("tag_ids", params.TagIDs),
+			zap.String("tag_query", params.TagQuery),
 			zap.String("folder_path", params.FolderPath),
 			zap.Bool("include_subfolders", params.IncludeSubfolders),
 		)
 	}
 
-	page, err := a.db.ListFilesByTags(
-		a.ctx,
-		a.currentWorkspace.ID,
-		params.TagIDs,
-		params.FolderPath,
-		params.IncludeSubfolders,
-		params.Limit,
-		params.Offset,
-	)
+	return a.SearchFiles(params)
+}
+
+// SearchFiles 按组合条件搜索当前工作区文件（标签、文件名、扩展名、大小、修改时间、类型均可选）
+func (a *App) SearchFiles(params api.FileSearchParams) (*api.FilePage, error) {
+	if a.ctx == nil {
+		return nil, errors.New("应用尚未初始化")
+	}
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	query, err := toFileQuery(a.currentWorkspace.ID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := a.db.SearchFiles(a.ctx, query)
 	if err != nil {
 		if a.logger != nil {
-			a.logger.Error("按标签搜索文件失败",
+			a.logger.Error("搜索文件失败",
 				zap.Int64("workspace_id", a.currentWorkspace.ID),
+				zap.String("tag_query", params.TagQuery),
+				zap.String("name_query", params.NameQuery),
 				zap.Error(err),
 			)
 		}
@@ -2280,6 +2664,261 @@ func (a *App) SearchFilesByTags(params api.FileSearchParams) (*api.FilePage, err
 	return toAPIFilePage(page), nil
 }
 
+// SearchFilesPage 按组合条件游标分页搜索当前工作区文件（使用 params.Cursor，忽略 Offset）
+func (a *App) SearchFilesPage(params api.FileSearchParams) (*api.FileCursorPage, error) {
+	if a.ctx == nil {
+		return nil, errors.New("应用尚未初始化")
+	}
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	query, err := toFileQuery(a.currentWorkspace.ID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := a.db.SearchFilesByCursor(a.ctx, query, params.Cursor, params.IncludeTotal)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("搜索文件失败",
+				zap.Int64("workspace_id", a.currentWorkspace.ID),
+				zap.String("tag_query", params.TagQuery),
+				zap.String("name_query", params.NameQuery),
+				zap.Bool("with_cursor", params.Cursor != ""),
+				zap.Error(err),
+			)
+		}
+		return nil, err
+	}
+
+	return toAPIFileCursorPage(page), nil
+}
+
+// GetSearchFacets 按与 SearchFilesByTags 相同的条件统计结果集中各标签、类型、扩展名的文件数
+func (a *App) GetSearchFacets(params api.FileSearchParams) (*api.SearchFacets, error) {
+	if a.ctx == nil {
+		return nil, errors.New("应用尚未初始化")
+	}
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	query, err := toFileQuery(a.currentWorkspace.ID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := a.db.SearchFacets(a.ctx, query)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("统计搜索分面失败",
+				zap.Int64("workspace_id", a.currentWorkspace.ID),
+				zap.String("tag_query", params.TagQuery),
+				zap.Error(err),
+			)
+		}
+		return nil, err
+	}
+
+	result := &api.SearchFacets{
+		Total:      facets.Total,
+		Tags:       make([]api.TagFacet, 0, len(facets.Tags)),
+		Types:      make([]api.FacetCount, 0, len(facets.Types)),
+		Extensions: make([]api.FacetCount, 0, len(facets.Extensions)),
+	}
+	for _, tag := range facets.Tags {
+		result.Tags = append(result.Tags, api.TagFacet{TagID: tag.TagID, Count: tag.Count})
+	}
+	for _, item := range facets.Types {
+		result.Types = append(result.Types, api.FacetCount{Value: item.Value, Count: item.Count})
+	}
+	for _, item := range facets.Extensions {
+		result.Extensions = append(result.Extensions, api.FacetCount{Value: item.Value, Count: item.Count})
+	}
+	return result, nil
+}
+
+// GetFolderChildren 返回文件夹的直接子文件夹及递归统计，folderPath 为空表示工作区根目录
+func (a *App) GetFolderChildren(folderPath string) ([]api.FolderNode, error) {
+	if a.ctx == nil {
+		return nil, errors.New("应用尚未初始化")
+	}
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	nodes, err := a.db.FolderChildren(a.ctx, a.currentWorkspace.ID, folderPath)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("获取子文件夹失败",
+				zap.Int64("workspace_id", a.currentWorkspace.ID),
+				zap.String("folder_path", folderPath),
+				zap.Error(err),
+			)
+		}
+		return nil, err
+	}
+
+	result := make([]api.FolderNode, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, api.FolderNode{
+			Path:           node.Path,
+			Name:           node.Name,
+			SubfolderCount: node.SubfolderCount,
+			FileCount:      node.FileCount,
+			TotalSize:      node.TotalSize,
+			TaggedCount:    node.TaggedCount,
+		})
+	}
+	return result, nil
+}
+
+// GetTaggingProgress 统计文件夹及其直接子文件夹的打标签进度，第一项为该文件夹汇总
+//
+// tagGroupID 大于 0 时，仅统计拥有该分组中标签的文件为已打标签。
+func (a *App) GetTaggingProgress(folderPath string, tagGroupID int64) ([]api.FolderTagProgress, error) {
+	if a.ctx == nil {
+		return nil, errors.New("应用尚未初始化")
+	}
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	items, err := a.db.TagProgressByFolder(a.ctx, a.currentWorkspace.ID, folderPath, tagGroupID)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("统计打标签进度失败",
+				zap.Int64("workspace_id", a.currentWorkspace.ID),
+				zap.String("folder_path", folderPath),
+				zap.Error(err),
+			)
+		}
+		return nil, err
+	}
+
+	result := make([]api.FolderTagProgress, 0, len(items))
+	for _, item := range items {
+		result = append(result, api.FolderTagProgress{
+			FolderPath: item.FolderPath,
+			Total:      item.Total,
+			Tagged:     item.Tagged,
+		})
+	}
+	return result, nil
+}
+
+// toFileQuery 将前端搜索参数转换为数据层查询
+func toFileQuery(workspaceID int64, params api.FileSearchParams) (data.FileQuery, error) {
+	query := data.FileQuery{
+		WorkspaceID:       workspaceID,
+		TagIDs:            params.TagIDs,
+		TagQuery:          params.TagQuery,
+		FolderPath:        params.FolderPath,
+		IncludeSubfolders: params.IncludeSubfolders,
+		NameQuery:         params.NameQuery,
+		Extensions:        params.Extensions,
+		MinSize:           params.MinSize,
+		MaxSize:           params.MaxSize,
+		FileType:          params.FileType,
+		Untagged:          params.Untagged,
+		MinTagCount:       params.MinTagCount,
+		MaxTagCount:       params.MaxTagCount,
+		MissingTagGroupID: params.MissingTagGroupID,
+		Limit:             params.Limit,
+		Offset:            params.Offset,
+	}
+
+	var err error
+	if query.Sort, err = toFileSort(params.SortBy, params.SortOrder); err != nil {
+		return data.FileQuery{}, err
+	}
+	if query.ModifiedAfter, err = parseSearchTime(params.ModifiedAfter, false); err != nil {
+		return data.FileQuery{}, fmt.Errorf("修改时间下限格式无效: %w", err)
+	}
+	if query.ModifiedBefore, err = parseSearchTime(params.ModifiedBefore, true); err != nil {
+		return data.FileQuery{}, fmt.Errorf("修改时间上限格式无效: %w", err)
+	}
+	return query, nil
+}
+
+// toFileSort 校验并转换排序参数，排序字段为空时由数据层使用默认顺序
+func toFileSort(sortBy, sortOrder string) (data.FileSort, error) {
+	sort := data.FileSort{By: strings.TrimSpace(sortBy)}
+	switch sort.By {
+	case "", data.SortByPath, data.SortByName, data.SortBySize, data.SortByModTime,
+		data.SortByCreatedAt, data.SortByTagCount, data.SortByExtension, data.SortByRelevance:
+	default:
+		return data.FileSort{}, fmt.Errorf("无效的排序字段: %s", sortBy)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(sortOrder)) {
+	case "", "asc":
+	case "desc":
+		sort.Desc = true
+	default:
+		return data.FileSort{}, fmt.Errorf("无效的排序方向: %s", sortOrder)
+	}
+	return sort, nil
+}
+
+// parseSearchTime 解析搜索时间，仅有日期时按本地时区处理；作为上限时包含当天
+func parseSearchTime(value string, upperBound bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if upperBound {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// ValidateTagQuery 校验标签表达式，语法错误时返回出错位置便于前端高亮
+func (a *App) ValidateTagQuery(query string) (*api.TagQueryCheck, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	tags, err := a.db.ValidateTagQuery(a.ctx, query)
+	if err != nil {
+		var syntaxErr *tagquery.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return &api.TagQueryCheck{
+				Message: syntaxErr.Error(),
+				Offset:  syntaxErr.Offset,
+				Length:  syntaxErr.Length,
+			}, nil
+		}
+		return nil, err
+	}
+
+	apiTags := make([]api.Tag, 0, len(tags))
+	for _, tag := range tags {
+		apiTags = append(apiTags, toAPITag(tag))
+	}
+	return &api.TagQueryCheck{Valid: true, Tags: apiTags}, nil
+}
+
 // loadSettingsFromDB 从数据库加载设置
 func (a *App) loadSettingsFromDB() error {
 	if a.db == nil {
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestApp 在临时配置目录中启动应用，创建包含 files（以 / 分隔的相对路径）的工作区并完成扫描
+func newTestApp(t *testing.T, files ...string) (*App, string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	for _, name := range files {
+		writeTestFile(t, filepath.Join(root, filepath.FromSlash(name)), name)
+	}
+
+	a := NewApp()
+	a.startup(context.Background())
+	if a.db == nil {
+		t.Fatal("startup did not open the database")
+	}
+	t.Cleanup(func() { a.shutdown(context.Background()) })
+
+	if _, err := a.ScanWorkspaceFolder(root); err != nil {
+		t.Fatalf("scan workspace: %v", err)
+	}
+	return a, root
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testFileID 返回当前工作区中相对路径对应的文件 ID
+func testFileID(t *testing.T, a *App, relPath string) int64 {
+	t.Helper()
+	page, err := a.db.ListFiles(a.ctx, a.currentWorkspace.ID, 1000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range page.Records {
+		if filepath.ToSlash(file.Path) == relPath {
+			return file.ID
+		}
+	}
+	t.Fatalf("file %s not found in database", relPath)
+	return 0
+}
+
+// testFilePath 返回数据库中记录的文件相对路径
+func testFilePath(t *testing.T, a *App, fileID int64) string {
+	t.Helper()
+	file, err := a.db.GetFileByID(a.ctx, fileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.ToSlash(file.Path)
+}
+
+// assertExists 断言工作区中的文件存在与否
+func assertExists(t *testing.T, root, relPath string, want bool) {
+	t.Helper()
+	_, err := os.Stat(filepath.Join(root, filepath.FromSlash(relPath)))
+	if exists := err == nil; exists != want {
+		t.Errorf("%s exists = %v, want %v", relPath, exists, want)
+	}
+}
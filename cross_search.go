@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// SearchAcrossWorkspaces 在全部或指定的工作区中执行同一搜索，结果按工作区分组并附带绝对路径
+//
+// 标签是全局的，因此同一标签查询可直接用于每个工作区；离线（目录不可访问）或查询出错的工作区
+// 会记录在 Unavailable 中，不影响其他工作区的结果。
+func (a *App) SearchAcrossWorkspaces(req api.CrossWorkspaceSearchRequest) (*api.CrossWorkspaceSearchResult, error) {
+	if a.ctx == nil {
+		return nil, errors.New("应用尚未初始化")
+	}
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	// 条件本身有误时所有工作区都会失败，提前返回错误
+	if _, err := toFileQuery(0, req.Params); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Params.TagQuery) != "" {
+		if _, err := a.db.ValidateTagQuery(a.ctx, req.Params.TagQuery); err != nil {
+			return nil, err
+		}
+	}
+
+	workspaces, err := a.db.ListWorkspaces(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api.CrossWorkspaceSearchResult{
+		Groups:      make([]api.WorkspaceSearchGroup, 0),
+		Unavailable: make([]api.UnavailableWorkspace, 0),
+	}
+
+	targets := workspaces
+	if len(req.WorkspaceIDs) > 0 {
+		byID := make(map[int64]data.Workspace, len(workspaces))
+		for _, ws := range workspaces {
+			byID[ws.ID] = ws
+		}
+		targets = make([]data.Workspace, 0, len(req.WorkspaceIDs))
+		seen := make(map[int64]bool, len(req.WorkspaceIDs))
+		for _, id := range req.WorkspaceIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			ws, ok := byID[id]
+			if !ok {
+				result.Unavailable = append(result.Unavailable, api.UnavailableWorkspace{
+					Workspace: api.Workspace{ID: id},
+					Reason:    "工作区不存在",
+				})
+				continue
+			}
+			targets = append(targets, ws)
+		}
+	}
+
+	for i := range targets {
+		ws := &targets[i]
+		if info, err := os.Stat(ws.Path); err != nil || !info.IsDir() {
+			result.Unavailable = append(result.Unavailable, api.UnavailableWorkspace{
+				Workspace: toAPIWorkspace(ws),
+				Reason:    "工作区目录不可访问（可能已离线或被移除）",
+			})
+			continue
+		}
+
+		query, _ := toFileQuery(ws.ID, req.Params)
+		page, err := a.db.SearchFiles(a.ctx, query)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Warn("跨工作区搜索失败",
+					zap.Int64("workspace_id", ws.ID),
+					zap.String("workspace_path", ws.Path),
+					zap.Error(err),
+				)
+			}
+			result.Unavailable = append(result.Unavailable, api.UnavailableWorkspace{
+				Workspace: toAPIWorkspace(ws),
+				Reason:    err.Error(),
+			})
+			continue
+		}
+		if page.Total == 0 {
+			continue
+		}
+
+		records := toAPIFileRecords(page.Records)
+		for j := range records {
+			records[j].AbsolutePath = filepath.Join(ws.Path, filepath.FromSlash(records[j].Path))
+		}
+		result.Groups = append(result.Groups, api.WorkspaceSearchGroup{
+			Workspace: toAPIWorkspace(ws),
+			Total:     page.Total,
+			Records:   records,
+		})
+		result.Total += page.Total
+	}
+
+	if a.logger != nil {
+		a.logger.Info("完成跨工作区搜索",
+			zap.Int("workspaces", len(targets)),
+			zap.Int("matched_workspaces", len(result.Groups)),
+			zap.Int("unavailable", len(result.Unavailable)),
+			zap.Int64("total", result.Total),
+		)
+	}
+
+	return result, nil
+}
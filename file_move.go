@@ -0,0 +1,211 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	goruntime "runtime"
+	"syscall"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+)
+
+// fileMoveProgressEvent 跨设备复制大文件时向前端推送进度的事件，数据为 api.FileMoveProgress
+const fileMoveProgressEvent = "file-move-progress"
+
+const (
+	// partialCopySuffix 复制中的临时文件后缀，校验通过后才改名为目标文件
+	partialCopySuffix = ".tagexplorer-partial"
+	// 小于该大小的文件不推送进度
+	progressMinSize  = 32 << 20
+	progressInterval = 200 * time.Millisecond
+)
+
+// moveFile 移动文件（绝对路径），目标位于其他设备时改为复制、校验后删除源文件；返回是否使用了复制
+//
+// 调用方负责检查目标是否已存在；label 为进度事件中显示的路径。
+func (a *App) moveFile(srcAbs, dstAbs, label string) (bool, error) {
+	err := a.rename(srcAbs, dstAbs)
+	if err == nil {
+		return false, nil
+	}
+	if !isCrossDevice(err) {
+		return false, err
+	}
+
+	start := time.Now()
+	if err := a.copyVerifyDelete(srcAbs, dstAbs, label); err != nil {
+		return true, err
+	}
+	if a.logger != nil {
+		a.logger.Info("跨设备移动文件",
+			zap.String("from", srcAbs),
+			zap.String("to", dstAbs),
+			zap.Duration("elapsed", time.Since(start)),
+		)
+	}
+	return true, nil
+}
+
+// isCrossDevice 判断 os.Rename 是否因源与目标不在同一设备而失败
+func isCrossDevice(err error) bool {
+	if errors.Is(err, syscall.EXDEV) {
+		return true
+	}
+	// Windows 跨卷移动返回 ERROR_NOT_SAME_DEVICE (17)
+	var errno syscall.Errno
+	return goruntime.GOOS == "windows" && errors.As(err, &errno) && errno == 17
+}
+
+// copyVerifyDelete 复制到目标目录的临时文件，保留权限与修改时间，校验大小和 SHA-256 后改名就位，最后删除源文件
+//
+// 删除源文件失败时删除已就位的副本，保证文件只存在于一处。
+func (a *App) copyVerifyDelete(srcAbs, dstAbs, label string) error {
+	info, err := os.Stat(srcAbs)
+	if err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return errors.New("跨设备移动仅支持普通文件")
+	}
+
+	tmp := dstAbs + partialCopySuffix
+	srcSum, err := a.copyWithProgress(srcAbs, tmp, info.Size(), label)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("复制文件失败: %w", err)
+	}
+	if err := os.Chmod(tmp, info.Mode().Perm()); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("设置文件权限失败: %w", err)
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("设置修改时间失败: %w", err)
+	}
+
+	tmpInfo, err := os.Stat(tmp)
+	if err == nil && tmpInfo.Size() != info.Size() {
+		err = fmt.Errorf("大小不一致（%d / %d）", tmpInfo.Size(), info.Size())
+	}
+	if err == nil {
+		var dstSum string
+		if dstSum, err = fileChecksum(tmp); err == nil && dstSum != srcSum {
+			err = errors.New("校验和不一致")
+		}
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("复制校验失败: %w", err)
+	}
+
+	if _, err := os.Lstat(dstAbs); err == nil {
+		_ = os.Remove(tmp)
+		return errors.New("目标位置已存在文件")
+	}
+	if err := os.Rename(tmp, dstAbs); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("移动文件失败: %w", err)
+	}
+	if err := os.Remove(srcAbs); err != nil {
+		_ = os.Remove(dstAbs)
+		return fmt.Errorf("删除源文件失败: %w", err)
+	}
+	return nil
+}
+
+// copyWithProgress 复制文件并计算源内容的 SHA-256，大文件按固定间隔推送进度
+func (a *App) copyWithProgress(srcAbs, dstAbs string, total int64, label string) (string, error) {
+	src, err := os.Open(srcAbs)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	// 上次中断遗留的临时文件直接覆盖
+	dst, err := os.OpenFile(dstAbs, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	progress := &copyProgress{app: a, label: label, total: total}
+	_, err = io.Copy(io.MultiWriter(dst, progress), io.TeeReader(src, hash))
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	progress.finish()
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyProgress 统计已复制的字节数并节流推送进度事件
+type copyProgress struct {
+	app    *App
+	label  string
+	total  int64
+	copied int64
+	last   time.Time
+}
+
+func (p *copyProgress) Write(b []byte) (int, error) {
+	p.copied += int64(len(b))
+	if p.total >= progressMinSize && time.Since(p.last) >= progressInterval {
+		p.last = time.Now()
+		p.app.emitEvent(fileMoveProgressEvent, api.FileMoveProgress{Path: p.label, Copied: p.copied, Total: p.total})
+	}
+	return len(b), nil
+}
+
+func (p *copyProgress) finish() {
+	if p.total >= progressMinSize {
+		p.app.emitEvent(fileMoveProgressEvent, api.FileMoveProgress{Path: p.label, Copied: p.copied, Total: p.total, Done: true})
+	}
+}
+
+// fileChecksum 计算文件内容的 SHA-256
+func fileChecksum(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sameFileContent 判断两个文件大小与内容是否一致
+func sameFileContent(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil || infoA.Size() != infoB.Size() || os.SameFile(infoA, infoB) {
+		return false
+	}
+	sumA, errA := fileChecksum(a)
+	sumB, errB := fileChecksum(b)
+	return errA == nil && errB == nil && sumA == sumB
+}
+
+// emitEvent 向前端推送事件；不在 Wails 运行时中（没有事件总线）时忽略
+func (a *App) emitEvent(name string, data any) {
+	if a.ctx == nil || a.ctx.Value("events") == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"tagexplorer/internal/data"
+)
+
+// crossDeviceRename 模拟源与目标位于不同设备时的重命名
+func crossDeviceRename(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+}
+
+func TestMoveFileCrossDevice(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src", "photo.jpg")
+	dst := filepath.Join(dir, "dst", "photo.jpg")
+	writeTestFile(t, src, "image content")
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2023, 8, 9, 10, 11, 12, 0, time.UTC)
+	if err := os.Chmod(src, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(src, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	srcSum, err := fileChecksum(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewApp()
+	a.rename = crossDeviceRename
+	copied, err := a.moveFile(src, dst, "photo.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !copied {
+		t.Error("moveFile reported a rename, want copy fallback")
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("source still exists: %v", err)
+	}
+	if _, err := os.Stat(dst + partialCopySuffix); !os.IsNotExist(err) {
+		t.Errorf("partial copy left behind: %v", err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("mtime = %v, want %v", info.ModTime(), modTime)
+	}
+	if sum, err := fileChecksum(dst); err != nil || sum != srcSum {
+		t.Errorf("checksum = %s, %v, want %s", sum, err, srcSum)
+	}
+}
+
+func TestMoveFileRenameError(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.txt")
+	writeTestFile(t, src, "a")
+
+	a := NewApp()
+	a.rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EACCES}
+	}
+	copied, err := a.moveFile(src, filepath.Join(dir, "b.txt"), "a.txt")
+	if err == nil || copied {
+		t.Fatalf("moveFile = %v, %v, want the rename error without copying", copied, err)
+	}
+	assertExists(t, dir, "a.txt", true)
+	assertExists(t, dir, "b.txt", false)
+}
+
+func TestRecoverInterruptedCopy(t *testing.T) {
+	tests := []struct {
+		name     string
+		resume   bool
+		complete bool // 复制已完成校验并就位，只差删除源文件
+		want     string
+		gone     string
+		status   string
+	}{
+		{"resume after copy", true, true, "to/a.txt", "a.txt", data.OperationApplied},
+		{"rollback after copy", false, true, "a.txt", "to/a.txt", data.OperationUndone},
+		{"resume during copy", true, false, "to/a.txt", "a.txt", data.OperationApplied},
+		{"rollback during copy", false, false, "a.txt", "to/a.txt", data.OperationUndone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, root := newTestApp(t, "a.txt")
+			fileID := testFileID(t, a, "a.txt")
+			opID, err := a.db.BeginOperation(a.ctx, a.currentWorkspace.ID, data.OperationOrganize, "整理 1 个文件",
+				`{"moves":[]}`, []data.OperationMove{{Seq: 0, FileID: fileID, From: "a.txt", To: "to/a.txt"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 异常退出时遗留的临时文件，复制完成时目标文件也已就位
+			writeTestFile(t, filepath.Join(root, "to", "a.txt"+partialCopySuffix), "a.t")
+			if tt.complete {
+				writeTestFile(t, filepath.Join(root, "to", "a.txt"), "a.txt")
+			}
+
+			var recoverErr error
+			if tt.resume {
+				_, recoverErr = a.ResumeOrganize(opID)
+			} else {
+				_, recoverErr = a.RollbackOrganize(opID)
+			}
+			if recoverErr != nil {
+				t.Fatal(recoverErr)
+			}
+
+			if content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(tt.want))); err != nil || string(content) != "a.txt" {
+				t.Errorf("%s = %q, %v, want the original content", tt.want, content, err)
+			}
+			assertExists(t, root, tt.gone, false)
+			assertExists(t, root, "to/a.txt"+partialCopySuffix, false)
+			if got := testFilePath(t, a, fileID); got != tt.want {
+				t.Errorf("database path = %s, want %s", got, tt.want)
+			}
+			op, err := a.db.GetOperation(a.ctx, opID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if op.Status != tt.status {
+				t.Errorf("status = %s, want %s", op.Status, tt.status)
+			}
+		})
+	}
+}
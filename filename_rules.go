@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"tagexplorer/internal/data"
+)
+
+// 目标文件系统类型
+const (
+	filesystemAuto    = "auto"
+	filesystemPosix   = "posix"
+	filesystemWindows = "windows"
+)
+
+// 文件名超长时的处理策略
+const (
+	overflowTruncate = "truncate" // 尽量写入标签，剩余数量以 +N 标记
+	overflowDBOnly   = "db_only"  // 放不下的标签只保存在数据库中，不加标记；原名本身超长时保留原文件名
+	overflowFail     = "fail"     // 放不下时拒绝重命名
+)
+
+const defaultMaxNameLength = 255
+
+// overflowMarkerPrefix 溢出标记前缀，形如 "+3"，解析文件名标签时需忽略
+const overflowMarkerPrefix = "+"
+
+// Windows 保留设备名（不区分大小写，与扩展名无关）
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// fileNameRules 目标文件系统的文件名约束
+type fileNameRules struct {
+	windows   bool
+	maxLength int
+	overflow  string
+}
+
+// tagFileName 带标签文件名的生成结果
+type tagFileName struct {
+	Name     string
+	Embedded []data.Tag // 写入文件名的标签
+	Overflow []data.Tag // 放不下、仅保存在数据库中的标签
+}
+
+// errFileNameTooLong 文件名超出限制且无法通过少写标签解决（原始文件名不会被截断）
+var errFileNameTooLong = errors.New("文件名超出目标文件系统长度限制")
+
+// fileNameRules 根据设置返回当前生效的文件名规则
+func (a *App) fileNameRules() fileNameRules {
+	rules := fileNameRules{
+		windows:   goruntime.GOOS == "windows",
+		maxLength: defaultMaxNameLength,
+		overflow:  overflowTruncate,
+	}
+	if a.settings == nil {
+		return rules
+	}
+
+	config := a.settings.TagRule
+	switch config.Filesystem {
+	case filesystemPosix:
+		rules.windows = false
+	case filesystemWindows:
+		rules.windows = true
+	}
+	if config.MaxNameLength > 0 {
+		rules.maxLength = config.MaxNameLength
+	}
+	if config.OverflowStrategy != "" {
+		rules.overflow = config.OverflowStrategy
+	}
+	return rules
+}
+
+// length 按目标文件系统的计量方式计算文件名长度
+func (r fileNameRules) length(name string) int {
+	if r.windows {
+		return len(utf16.Encode([]rune(name)))
+	}
+	return len(name)
+}
+
+// fits 判断文件名是否满足长度限制
+func (r fileNameRules) fits(name string) bool {
+	return r.length(name) <= r.maxLength
+}
+
+// safeFileName 拼接文件名，并处理保留名与结尾的点号/空格（仅 Windows 规则）
+func (r fileNameRules) safeFileName(base, ext string) string {
+	if !r.windows {
+		return base + ext
+	}
+	// 扩展名本身可能只是一个点（如 "foo."），因此对拼接后的完整文件名去除结尾的点号和空格
+	name := strings.TrimRight(base+ext, ". ")
+	stem := name
+	if idx := strings.Index(stem, "."); idx >= 0 {
+		stem = stem[:idx]
+	}
+	if windowsReservedNames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+		name = stem + "_" + name[len(stem):]
+	}
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+// buildFileNameWithTags 在文件名规则约束下生成带标签的文件名
+func (a *App) buildFileNameWithTags(originalName string, tags []data.Tag) (tagFileName, error) {
+	rules := a.fileNameRules()
+
+	ext := filepath.Ext(originalName)
+	cleanName := a.removeTagsFromFileName(strings.TrimSuffix(originalName, ext))
+
+	space, prefix := "", false
+	if a.settings != nil {
+		if a.settings.TagRule.AddSpaces {
+			space = " "
+		}
+		prefix = a.settings.TagRule.Position == "prefix"
+	}
+	compose := func(base string, embedded []data.Tag, overflow int) string {
+		if overflow > 0 && rules.overflow == overflowTruncate {
+			embedded = append(embedded[:len(embedded):len(embedded)], data.Tag{Name: overflowMarker(overflow)})
+		}
+		stem := base
+		if tagStr := a.formatTagsText(embedded); tagStr != "" {
+			if prefix {
+				stem = tagStr + space + base
+			} else {
+				stem = base + space + tagStr
+			}
+		}
+		return rules.safeFileName(stem, ext)
+	}
+
+	// 从尾部逐个去掉标签，直到文件名满足长度限制
+	for n := len(tags); n >= 0; n-- {
+		name := compose(cleanName, tags[:n], len(tags)-n)
+		if rules.fits(name) {
+			return tagFileName{Name: name, Embedded: tags[:n], Overflow: tags[n:]}, nil
+		}
+		if rules.overflow == overflowFail {
+			return tagFileName{}, fmt.Errorf("%w（%d/%d）", errFileNameTooLong, rules.length(name), rules.maxLength)
+		}
+	}
+
+	// 没有标签也放不下时不截断原名：只保存到数据库的策略保留原文件名，其余策略拒绝重命名
+	if rules.overflow == overflowDBOnly {
+		return tagFileName{Name: originalName, Overflow: tags}, nil
+	}
+	name := compose(cleanName, nil, len(tags))
+	return tagFileName{}, fmt.Errorf("%w（%d/%d）", errFileNameTooLong, rules.length(name), rules.maxLength)
+}
+
+// overflowMarker 生成溢出标记
+func overflowMarker(count int) string {
+	return overflowMarkerPrefix + strconv.Itoa(count)
+}
+
+// isOverflowMarker 判断解析出的标签是否为溢出标记
+func isOverflowMarker(name string) bool {
+	rest, ok := strings.CutPrefix(name, overflowMarkerPrefix)
+	if !ok || rest == "" {
+		return false
+	}
+	_, err := strconv.Atoi(rest)
+	return err == nil
+}
+
+// dropOverflowMarker 去掉标签块末尾的溢出标记
+//
+// 溢出标记总是写在标签块的最后，其余位置形如 "+1" 的是真实标签，需要保留。
+func dropOverflowMarker(tags []string) []string {
+	if n := len(tags); n > 0 && isOverflowMarker(tags[n-1]) {
+		return tags[:n-1]
+	}
+	return tags
+}
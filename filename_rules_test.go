@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+func tagRuleApp(rule api.TagRuleConfig) *App {
+	return &App{settings: &api.AppSettings{TagRule: rule}}
+}
+
+func TestParseTagsOverflowMarker(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     api.TagRuleConfig
+		fileName string
+		want     string
+	}{
+		{"combined marker", api.TagRuleConfig{Position: "suffix"}, "a [x, y, +2].txt", "x,y"},
+		{"combined marker-like tag", api.TagRuleConfig{Position: "suffix"}, "a [+1, x].txt", "+1,x"},
+		{"combined prefix", api.TagRuleConfig{Position: "prefix"}, "[+1, x, +3] a.txt", "+1,x"},
+		{"individual suffix", api.TagRuleConfig{Position: "suffix", Grouping: "individual"}, "a[+1][x][+2].txt", "+1,x"},
+		{"individual prefix", api.TagRuleConfig{Position: "prefix", Grouping: "individual"}, "[+1][x][+2] a.txt", "+1,x"},
+		{"individual marker-like tag", api.TagRuleConfig{Position: "suffix", Grouping: "individual"}, "a[+1][x].txt", "+1,x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(tagRuleApp(tt.rule).parseTagsFromFileName(tt.fileName), ",")
+			if got != tt.want {
+				t.Errorf("parseTagsFromFileName(%q) = %s, want %s", tt.fileName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverflowMarkerRoundTrip(t *testing.T) {
+	a := tagRuleApp(api.TagRuleConfig{Format: "square_brackets", Position: "suffix", AddSpaces: true, MaxNameLength: 24})
+	tags := []data.Tag{{Name: "+1"}, {Name: "alpha"}, {Name: "beta"}, {Name: "gamma"}}
+	result, err := a.buildFileNameWithTags("report.txt", tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Overflow) == 0 || !strings.Contains(result.Name, "+") {
+		t.Fatalf("name = %q, want some tags replaced by an overflow marker", result.Name)
+	}
+
+	var want []string
+	for _, tag := range result.Embedded {
+		want = append(want, tag.Name)
+	}
+	if got := a.parseTagsFromFileName(result.Name); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("parseTagsFromFileName(%q) = %v, want embedded tags %v", result.Name, got, want)
+	}
+}
+
+func TestSafeFileName(t *testing.T) {
+	windows := fileNameRules{windows: true, maxLength: defaultMaxNameLength}
+	tests := []struct {
+		base, ext string
+		want      string
+	}{
+		{"foo", ".", "foo"},
+		{"foo [a]", ".", "foo [a]"},
+		{"foo.", "", "foo"},
+		{"foo ", ". ", "foo"},
+		{"foo", ".txt", "foo.txt"},
+		{"CON", ".txt", "CON_.txt"},
+		{"con .tar", ".gz", "con _.tar.gz"},
+		{"NUL", "", "NUL_"},
+		{"...", "", "_"},
+	}
+	for _, tt := range tests {
+		if got := windows.safeFileName(tt.base, tt.ext); got != tt.want {
+			t.Errorf("safeFileName(%q, %q) = %q, want %q", tt.base, tt.ext, got, tt.want)
+		}
+	}
+
+	posix := fileNameRules{maxLength: defaultMaxNameLength}
+	if got := posix.safeFileName("foo", "."); got != "foo." {
+		t.Errorf("posix safeFileName = %q, want foo. unchanged", got)
+	}
+}
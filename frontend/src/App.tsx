@@ -7,6 +7,7 @@ import StatusBar from "./components/StatusBar";
 import WorkspaceSidebar from "./components/WorkspaceSidebar";
 import StartupDialog from "./components/StartupDialog";
 import OrganizeDialog from "./components/OrganizeDialog";
+import InterruptedOrganizeDialog from "./components/InterruptedOrganizeDialog";
 import {useTheme} from "./hooks/useTheme";
 import {useWorkspaceStore} from "./store/workspace";
 import {useShallow} from "zustand/react/shallow";
@@ -201,6 +202,9 @@ function App() {
         onClose={() => setShowOrganizeDialog(false)}
       />
 
+      {/* 上次异常退出遗留的整理 */}
+      <InterruptedOrganizeDialog />
+
       {/* 设置对话框 */}
       <SettingsDialog 
         isOpen={showSettingsDialog}
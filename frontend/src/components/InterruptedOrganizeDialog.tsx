@@ -0,0 +1,149 @@
+import {useCallback, useEffect, useState} from "react";
+import {AlertTriangle, Play, Undo2, X} from "lucide-react";
+import {ListInterruptedOrganizes, ResumeOrganize, RollbackOrganize} from "../../wailsjs/go/main/App";
+import {EventsOn} from "../../wailsjs/runtime/runtime";
+import {useWorkspaceStore} from "../store/workspace";
+import type {InterruptedOrganize, OperationReplayResult} from "../types/organize";
+
+// 后端发现上次异常退出遗留的整理时推送的事件
+const ORGANIZE_INTERRUPTED_EVENT = "organize-interrupted";
+
+const InterruptedOrganizeDialog = () => {
+  const fetchNextPage = useWorkspaceStore((state) => state.fetchNextPage);
+  const [items, setItems] = useState<InterruptedOrganize[]>([]);
+  const [dismissed, setDismissed] = useState(false);
+  const [busyId, setBusyId] = useState<number | null>(null);
+  const [error, setError] = useState<string>();
+  const [notice, setNotice] = useState<string>();
+
+  const loadItems = useCallback(async () => {
+    try {
+      const response = (await ListInterruptedOrganizes()) as InterruptedOrganize[];
+      setItems(response ?? []);
+      if (response && response.length > 0) {
+        setDismissed(false);
+      }
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      setError(message);
+    }
+  }, []);
+
+  // 启动时主动检查一次，事件可能在监听注册之前就已推送
+  useEffect(() => {
+    void loadItems();
+    return EventsOn(ORGANIZE_INTERRUPTED_EVENT, () => {
+      void loadItems();
+    });
+  }, [loadItems]);
+
+  const handleRecover = async (operationId: number, resume: boolean) => {
+    setBusyId(operationId);
+    setError(undefined);
+    setNotice(undefined);
+    try {
+      const result = (await (resume ? ResumeOrganize(operationId) : RollbackOrganize(operationId))) as OperationReplayResult;
+      if (result.failed > 0) {
+        setError(result.message ?? `${result.failed} 个文件处理失败，可处理后重试`);
+      } else {
+        setNotice(`${resume ? "已继续完成" : "已回滚"}：处理 ${result.succeeded} 个文件，跳过 ${result.skipped} 个`);
+      }
+      await loadItems();
+      await fetchNextPage(true);
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      setError(message);
+    } finally {
+      setBusyId(null);
+    }
+  };
+
+  if (dismissed || (items.length === 0 && !notice && !error)) return null;
+
+  return (
+    <div className="fixed inset-0 z-50 flex items-center justify-center bg-black/40 px-4">
+      <div className="relative w-full max-w-2xl max-h-[80vh] overflow-hidden rounded-xl bg-white shadow-2xl dark:bg-slate-900">
+        <div className="flex items-center justify-between border-b border-slate-200 px-6 py-4 dark:border-slate-800">
+          <div className="flex items-center gap-3">
+            <div className="flex h-9 w-9 items-center justify-center rounded-full bg-amber-100 dark:bg-amber-900/20">
+              <AlertTriangle size={18} className="text-amber-500" />
+            </div>
+            <div>
+              <p className="text-lg font-semibold text-slate-900 dark:text-white">未完成的整理</p>
+              <p className="text-xs text-slate-500">
+                上次整理过程中程序异常退出，部分文件可能已移动。请选择继续完成或回滚到整理前的位置。
+              </p>
+            </div>
+          </div>
+          <button
+            onClick={() => setDismissed(true)}
+            className="rounded-md p-2 text-slate-500 transition hover:bg-slate-100 dark:hover:bg-slate-800"
+          >
+            <X size={16} />
+          </button>
+        </div>
+
+        <div className="max-h-[60vh] space-y-3 overflow-y-auto p-6">
+          {error && (
+            <div className="rounded-lg border border-red-500/50 bg-red-500/10 px-3 py-2 text-sm text-red-600 dark:text-red-300">
+              {error}
+            </div>
+          )}
+          {notice && (
+            <div className="rounded-lg bg-green-100 px-3 py-2 text-sm text-green-700 dark:bg-green-900/30 dark:text-green-200">
+              {notice}
+            </div>
+          )}
+
+          {items.map((item) => (
+            <div
+              key={item.operation_id}
+              className="rounded-lg border border-slate-200 px-4 py-3 dark:border-slate-700"
+            >
+              <div className="flex items-start justify-between gap-4">
+                <div className="min-w-0">
+                  <p className="text-sm font-medium text-slate-900 dark:text-white">
+                    {item.summary || `整理 #${item.operation_id}`}
+                  </p>
+                  <p className="truncate text-xs text-slate-500" title={item.workspace_path}>
+                    {item.workspace_path || "工作区已不存在"} · {item.created_at}
+                  </p>
+                  <p className="mt-1 text-xs text-slate-600 dark:text-slate-300">
+                    共 {item.total} 个：已移动 {item.moved}，待移动 {item.pending}
+                    {item.missing > 0 && <span className="text-red-600 dark:text-red-300">，找不到 {item.missing}</span>}
+                  </p>
+                </div>
+                <div className="flex flex-shrink-0 gap-2">
+                  <button
+                    type="button"
+                    onClick={() => handleRecover(item.operation_id, true)}
+                    disabled={busyId !== null}
+                    className="flex items-center gap-2 rounded-md bg-brand px-3 py-2 text-sm font-medium text-white transition hover:bg-brand-dark disabled:cursor-not-allowed disabled:opacity-60"
+                  >
+                    <Play size={14} />
+                    继续
+                  </button>
+                  <button
+                    type="button"
+                    onClick={() => handleRecover(item.operation_id, false)}
+                    disabled={busyId !== null}
+                    className="flex items-center gap-2 rounded-md bg-slate-100 px-3 py-2 text-sm font-medium text-slate-700 transition hover:bg-slate-200 disabled:cursor-not-allowed disabled:opacity-60 dark:bg-slate-800 dark:text-slate-200 dark:hover:bg-slate-700"
+                  >
+                    <Undo2 size={14} />
+                    回滚
+                  </button>
+                </div>
+              </div>
+            </div>
+          ))}
+
+          {items.length === 0 && (
+            <p className="text-sm text-slate-500">没有其他未完成的整理。</p>
+          )}
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default InterruptedOrganizeDialog;
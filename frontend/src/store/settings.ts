@@ -43,6 +43,9 @@ export const useSettingsStore = create<SettingsState>()(
                 position: backendSettings.tagRule.position as any,
                 addSpaces: backendSettings.tagRule.addSpaces,
                 grouping: backendSettings.tagRule.grouping as any,
+                filesystem: backendSettings.tagRule.filesystem as any,
+                maxNameLength: backendSettings.tagRule.maxNameLength,
+                overflowStrategy: backendSettings.tagRule.overflowStrategy as any,
               },
             };
             set({ settings: frontendSettings });
@@ -79,6 +82,9 @@ export const useSettingsStore = create<SettingsState>()(
               position: newSettings.tagRule.position,
               addSpaces: newSettings.tagRule.addSpaces,
               grouping: newSettings.tagRule.grouping,
+              filesystem: newSettings.tagRule.filesystem,
+              maxNameLength: newSettings.tagRule.maxNameLength,
+              overflowStrategy: newSettings.tagRule.overflowStrategy,
             },
           });
           
@@ -123,6 +129,9 @@ export const useSettingsStore = create<SettingsState>()(
               position: updatedSettings.tagRule.position,
               addSpaces: updatedSettings.tagRule.addSpaces,
               grouping: updatedSettings.tagRule.grouping,
+              filesystem: updatedSettings.tagRule.filesystem,
+              maxNameLength: updatedSettings.tagRule.maxNameLength,
+              overflowStrategy: updatedSettings.tagRule.overflowStrategy,
             },
           });
           
@@ -161,6 +170,9 @@ export const useSettingsStore = create<SettingsState>()(
               position: DEFAULT_SETTINGS.tagRule.position,
               addSpaces: DEFAULT_SETTINGS.tagRule.addSpaces,
               grouping: DEFAULT_SETTINGS.tagRule.grouping,
+              filesystem: DEFAULT_SETTINGS.tagRule.filesystem,
+              maxNameLength: DEFAULT_SETTINGS.tagRule.maxNameLength,
+              overflowStrategy: DEFAULT_SETTINGS.tagRule.overflowStrategy,
             },
           });
           
@@ -42,3 +42,31 @@ export interface OrganizeUndoResult {
   failed: number;
   message?: string;
 }
+
+export interface InterruptedOrganize {
+  operation_id: number;
+  workspace_id: number;
+  workspace_path: string;
+  summary: string;
+  created_at: string;
+  total: number;
+  moved: number;
+  pending: number;
+  missing: number;
+}
+
+export interface OperationConflict {
+  file_id?: number;
+  path?: string;
+  message: string;
+}
+
+export interface OperationReplayResult {
+  operation_id: number;
+  status: string;
+  succeeded: number;
+  skipped: number;
+  failed: number;
+  conflicts?: OperationConflict[];
+  message?: string;
+}
@@ -9,6 +9,12 @@ export type TagFormat = 'brackets' | 'square_brackets' | 'parentheses' | 'custom
 // 标签组合方式
 export type TagGrouping = 'combined' | 'individual';
 
+// 目标文件系统
+export type FileSystemKind = 'auto' | 'posix' | 'windows';
+
+// 文件名超长处理策略：截断并标记 / 溢出标签仅存数据库 / 拒绝重命名
+export type OverflowStrategy = 'truncate' | 'db_only' | 'fail';
+
 // 标签应用规则配置
 export interface TagRuleConfig {
   // 标签格式类型
@@ -25,6 +31,12 @@ export interface TagRuleConfig {
   addSpaces: boolean;
   // 标签组合方式
   grouping: TagGrouping;
+  // 目标文件系统（决定文件名长度计算与保留名规则）
+  filesystem?: FileSystemKind;
+  // 文件名最大长度，0 表示默认 255
+  maxNameLength?: number;
+  // 文件名超长时的处理策略
+  overflowStrategy?: OverflowStrategy;
 }
 
 // 应用设置
@@ -43,6 +55,9 @@ export const DEFAULT_SETTINGS: AppSettings = {
     position: 'suffix',
     addSpaces: true,
     grouping: 'combined',
+    filesystem: 'auto',
+    maxNameLength: 255,
+    overflowStrategy: 'truncate',
   },
 };
 
@@ -3,64 +3,164 @@
 import {api} from '../models';
 import {main} from '../models';
 
-export function AddTagToFile(arg1:number,arg2:number):Promise<void>;
+export function AddTagToFile(arg1:number,arg2:number):Promise<api.TagRenameResult>;
+
+export function AddTagsToFiles(arg1:Array<number>,arg2:Array<number>):Promise<api.BatchTagResult>;
 
 export function AddWorkspaceFolder():Promise<api.ScanResult>;
 
-export function ClearAllTagsFromFile(arg1:number):Promise<void>;
+export function ApplyTagReconcile(arg1:api.TagReconcileRequest):Promise<api.TagReconcileResult>;
+
+export function AutocompleteTags(arg1:string,arg2:number):Promise<Array<api.TagSuggestion>>;
+
+export function ClearAllTagsFromFile(arg1:number):Promise<api.TagRenameResult>;
+
+export function CreateOrganizeRule(arg1:api.OrganizeRuleInput):Promise<api.OrganizeRule>;
+
+export function CreateOrganizeView(arg1:api.OrganizeViewRequest):Promise<api.OrganizeViewResult>;
+
+export function CreateSavedSearch(arg1:string,arg2:api.FileSearchParams,arg3:boolean):Promise<api.SavedSearch>;
 
 export function CreateTag(arg1:string,arg2:string,arg3:any):Promise<api.Tag>;
 
+export function DeleteOrganizeRule(arg1:number):Promise<void>;
+
+export function DeleteSavedSearch(arg1:number):Promise<void>;
+
 export function DeleteTag(arg1:number):Promise<void>;
 
+export function ExecuteFlatten(arg1:api.FlattenRequest):Promise<api.OrganizeResult>;
+
 export function ExecuteOrganize(arg1:api.OrganizeRequest):Promise<api.OrganizeResult>;
 
+export function ExecuteSavedSearch(arg1:number,arg2:string):Promise<api.FileCursorPage>;
+
+export function ExportOrganizeOperation(arg1:number,arg2:string):Promise<string>;
+
+export function ExportOrganizePreview(arg1:api.OrganizePreview,arg2:string):Promise<string>;
+
 export function GetFiles(arg1:number,arg2:number):Promise<api.FilePage>;
 
+export function GetFilesPage(arg1:string,arg2:number,arg3:string,arg4:string,arg5:boolean):Promise<api.FileCursorPage>;
+
+export function GetFilesSorted(arg1:number,arg2:number,arg3:string,arg4:string):Promise<api.FilePage>;
+
+export function GetFolderChildren(arg1:string):Promise<Array<api.FolderNode>>;
+
+export function GetOrganizeOperation(arg1:number):Promise<api.OrganizeOperationDetail>;
+
+export function GetOrganizeRuleRunPreview(arg1:number):Promise<api.OrganizePreview>;
+
 export function GetRecentItems():Promise<Array<main.RecentItem>>;
 
+export function GetSearchFacets(arg1:api.FileSearchParams):Promise<api.SearchFacets>;
+
 export function GetSettings():Promise<api.AppSettings>;
 
+export function GetTaggingProgress(arg1:string,arg2:number):Promise<Array<api.FolderTagProgress>>;
+
 export function GetThumbnail(arg1:string):Promise<string>;
 
 export function GetWorkspaceFolders():Promise<Array<api.Workspace>>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function ListInterruptedOrganizes():Promise<Array<api.InterruptedOrganize>>;
+
+export function ListOperations(arg1:number):Promise<Array<api.OperationSummary>>;
+
+export function ListOrganizeCreatedFolders():Promise<Array<string>>;
+
+export function ListOrganizeLeftoverFolders(arg1:number):Promise<Array<string>>;
+
+export function ListOrganizeOperations(arg1:number):Promise<Array<api.OrganizeOperationInfo>>;
+
+export function ListOrganizeRuleRuns(arg1:number,arg2:number):Promise<Array<api.OrganizeRuleRun>>;
+
+export function ListOrganizeRules():Promise<Array<api.OrganizeRule>>;
+
+export function ListOrganizeViews():Promise<Array<api.OrganizeView>>;
+
+export function ListSavedSearches():Promise<Array<api.SavedSearch>>;
+
 export function ListTags():Promise<Array<api.Tag>>;
 
 export function LoadWorkspaceConfig():Promise<main.WorkspaceConfig>;
 
+export function MergeTags(arg1:number,arg2:number):Promise<api.TagEditResult>;
+
 export function OpenRecentItem(arg1:string,arg2:string):Promise<api.ScanResult>;
 
+export function PreviewFlatten(arg1:api.FlattenRequest):Promise<api.OrganizePreview>;
+
 export function PreviewOrganize(arg1:api.OrganizeRequest):Promise<api.OrganizePreview>;
 
+export function RebuildOrganizeView(arg1:number):Promise<api.OrganizeViewResult>;
+
+export function ReconcileTags(arg1:number):Promise<api.TagReconcileReport>;
+
+export function Redo(arg1:number):Promise<api.OperationReplayResult>;
+
+export function RemoveOrganizeLeftoverFolders(arg1:number):Promise<Array<string>>;
+
+export function RemoveOrganizeView(arg1:number):Promise<api.OrganizeViewRemoval>;
+
 export function RemoveRecentItem(arg1:string):Promise<void>;
 
-export function RemoveTagFromFile(arg1:number,arg2:number):Promise<void>;
+export function RemoveTagFromFile(arg1:number,arg2:number):Promise<api.TagRenameResult>;
+
+export function RemoveTagsFromFiles(arg1:Array<number>,arg2:Array<number>):Promise<api.BatchTagResult>;
 
 export function RemoveWorkspaceFolder(arg1:number):Promise<void>;
 
 export function RenameFile(arg1:number,arg2:string):Promise<void>;
 
-export function RenameFileWithTags(arg1:number):Promise<void>;
+export function RenameFileWithTags(arg1:number):Promise<api.TagRenameResult>;
+
+export function RenameTag(arg1:number,arg2:string):Promise<api.TagEditResult>;
+
+export function ResumeOrganize(arg1:number):Promise<api.OperationReplayResult>;
+
+export function RollbackOrganize(arg1:number):Promise<api.OperationReplayResult>;
+
+export function RunOrganizeRule(arg1:number,arg2:boolean):Promise<api.OrganizeRuleRun>;
 
 export function SaveWorkspaceConfig(arg1:string,arg2:Array<string>):Promise<string>;
 
 export function ScanWorkspaceFolder(arg1:string):Promise<api.ScanResult>;
 
+export function SearchAcrossWorkspaces(arg1:api.CrossWorkspaceSearchRequest):Promise<api.CrossWorkspaceSearchResult>;
+
+export function SearchFiles(arg1:api.FileSearchParams):Promise<api.FilePage>;
+
 export function SearchFilesByTags(arg1:api.FileSearchParams):Promise<api.FilePage>;
 
+export function SearchFilesPage(arg1:api.FileSearchParams):Promise<api.FileCursorPage>;
+
 export function SelectWorkspace():Promise<api.ScanResult>;
 
 export function SetActiveWorkspace(arg1:number):Promise<void>;
 
+export function SetTagsForFiles(arg1:Array<number>,arg2:Array<number>):Promise<api.BatchTagResult>;
+
 export function ShowStartupDialog():Promise<string>;
 
+export function SuggestTags(arg1:number,arg2:number):Promise<Array<api.SuggestedTag>>;
+
+export function Undo(arg1:number):Promise<api.OperationReplayResult>;
+
 export function UndoOrganize(arg1:number):Promise<api.OrganizeUndoResult>;
 
+export function UpdateOrganizeRule(arg1:number,arg2:api.OrganizeRuleInput):Promise<api.OrganizeRule>;
+
+export function UpdateSavedSearch(arg1:number,arg2:string,arg3:api.FileSearchParams):Promise<api.SavedSearch>;
+
 export function UpdateSettings(arg1:api.AppSettings):Promise<void>;
 
 export function UpdateTagColor(arg1:number,arg2:string):Promise<void>;
 
 export function UpdateWorkspaceConfig(arg1:string,arg2:string,arg3:Array<string>):Promise<void>;
+
+export function ValidateOrganizeTemplate(arg1:api.OrganizeRequest):Promise<api.OrganizeTemplateCheck>;
+
+export function ValidateTagQuery(arg1:string):Promise<api.TagQueryCheck>;
@@ -6,38 +6,114 @@ export function AddTagToFile(arg1, arg2) {
   return window['go']['main']['App']['AddTagToFile'](arg1, arg2);
 }
 
+export function AddTagsToFiles(arg1, arg2) {
+  return window['go']['main']['App']['AddTagsToFiles'](arg1, arg2);
+}
+
 export function AddWorkspaceFolder() {
   return window['go']['main']['App']['AddWorkspaceFolder']();
 }
 
+export function ApplyTagReconcile(arg1) {
+  return window['go']['main']['App']['ApplyTagReconcile'](arg1);
+}
+
+export function AutocompleteTags(arg1, arg2) {
+  return window['go']['main']['App']['AutocompleteTags'](arg1, arg2);
+}
+
 export function ClearAllTagsFromFile(arg1) {
   return window['go']['main']['App']['ClearAllTagsFromFile'](arg1);
 }
 
+export function CreateOrganizeRule(arg1) {
+  return window['go']['main']['App']['CreateOrganizeRule'](arg1);
+}
+
+export function CreateOrganizeView(arg1) {
+  return window['go']['main']['App']['CreateOrganizeView'](arg1);
+}
+
+export function CreateSavedSearch(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateSavedSearch'](arg1, arg2, arg3);
+}
+
 export function CreateTag(arg1, arg2, arg3) {
   return window['go']['main']['App']['CreateTag'](arg1, arg2, arg3);
 }
 
+export function DeleteOrganizeRule(arg1) {
+  return window['go']['main']['App']['DeleteOrganizeRule'](arg1);
+}
+
+export function DeleteSavedSearch(arg1) {
+  return window['go']['main']['App']['DeleteSavedSearch'](arg1);
+}
+
 export function DeleteTag(arg1) {
   return window['go']['main']['App']['DeleteTag'](arg1);
 }
 
+export function ExecuteFlatten(arg1) {
+  return window['go']['main']['App']['ExecuteFlatten'](arg1);
+}
+
 export function ExecuteOrganize(arg1) {
   return window['go']['main']['App']['ExecuteOrganize'](arg1);
 }
 
+export function ExecuteSavedSearch(arg1, arg2) {
+  return window['go']['main']['App']['ExecuteSavedSearch'](arg1, arg2);
+}
+
+export function ExportOrganizeOperation(arg1, arg2) {
+  return window['go']['main']['App']['ExportOrganizeOperation'](arg1, arg2);
+}
+
+export function ExportOrganizePreview(arg1, arg2) {
+  return window['go']['main']['App']['ExportOrganizePreview'](arg1, arg2);
+}
+
 export function GetFiles(arg1, arg2) {
   return window['go']['main']['App']['GetFiles'](arg1, arg2);
 }
 
+export function GetFilesPage(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetFilesPage'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetFilesSorted(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetFilesSorted'](arg1, arg2, arg3, arg4);
+}
+
+export function GetFolderChildren(arg1) {
+  return window['go']['main']['App']['GetFolderChildren'](arg1);
+}
+
+export function GetOrganizeOperation(arg1) {
+  return window['go']['main']['App']['GetOrganizeOperation'](arg1);
+}
+
+export function GetOrganizeRuleRunPreview(arg1) {
+  return window['go']['main']['App']['GetOrganizeRuleRunPreview'](arg1);
+}
+
 export function GetRecentItems() {
   return window['go']['main']['App']['GetRecentItems']();
 }
 
+export function GetSearchFacets(arg1) {
+  return window['go']['main']['App']['GetSearchFacets'](arg1);
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetTaggingProgress(arg1, arg2) {
+  return window['go']['main']['App']['GetTaggingProgress'](arg1, arg2);
+}
+
 export function GetThumbnail(arg1) {
   return window['go']['main']['App']['GetThumbnail'](arg1);
 }
@@ -50,6 +126,42 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ListInterruptedOrganizes() {
+  return window['go']['main']['App']['ListInterruptedOrganizes']();
+}
+
+export function ListOperations(arg1) {
+  return window['go']['main']['App']['ListOperations'](arg1);
+}
+
+export function ListOrganizeCreatedFolders() {
+  return window['go']['main']['App']['ListOrganizeCreatedFolders']();
+}
+
+export function ListOrganizeLeftoverFolders(arg1) {
+  return window['go']['main']['App']['ListOrganizeLeftoverFolders'](arg1);
+}
+
+export function ListOrganizeOperations(arg1) {
+  return window['go']['main']['App']['ListOrganizeOperations'](arg1);
+}
+
+export function ListOrganizeRuleRuns(arg1, arg2) {
+  return window['go']['main']['App']['ListOrganizeRuleRuns'](arg1, arg2);
+}
+
+export function ListOrganizeRules() {
+  return window['go']['main']['App']['ListOrganizeRules']();
+}
+
+export function ListOrganizeViews() {
+  return window['go']['main']['App']['ListOrganizeViews']();
+}
+
+export function ListSavedSearches() {
+  return window['go']['main']['App']['ListSavedSearches']();
+}
+
 export function ListTags() {
   return window['go']['main']['App']['ListTags']();
 }
@@ -58,14 +170,42 @@ export function LoadWorkspaceConfig() {
   return window['go']['main']['App']['LoadWorkspaceConfig']();
 }
 
+export function MergeTags(arg1, arg2) {
+  return window['go']['main']['App']['MergeTags'](arg1, arg2);
+}
+
 export function OpenRecentItem(arg1, arg2) {
   return window['go']['main']['App']['OpenRecentItem'](arg1, arg2);
 }
 
+export function PreviewFlatten(arg1) {
+  return window['go']['main']['App']['PreviewFlatten'](arg1);
+}
+
 export function PreviewOrganize(arg1) {
   return window['go']['main']['App']['PreviewOrganize'](arg1);
 }
 
+export function RebuildOrganizeView(arg1) {
+  return window['go']['main']['App']['RebuildOrganizeView'](arg1);
+}
+
+export function ReconcileTags(arg1) {
+  return window['go']['main']['App']['ReconcileTags'](arg1);
+}
+
+export function Redo(arg1) {
+  return window['go']['main']['App']['Redo'](arg1);
+}
+
+export function RemoveOrganizeLeftoverFolders(arg1) {
+  return window['go']['main']['App']['RemoveOrganizeLeftoverFolders'](arg1);
+}
+
+export function RemoveOrganizeView(arg1) {
+  return window['go']['main']['App']['RemoveOrganizeView'](arg1);
+}
+
 export function RemoveRecentItem(arg1) {
   return window['go']['main']['App']['RemoveRecentItem'](arg1);
 }
@@ -74,6 +214,10 @@ export function RemoveTagFromFile(arg1, arg2) {
   return window['go']['main']['App']['RemoveTagFromFile'](arg1, arg2);
 }
 
+export function RemoveTagsFromFiles(arg1, arg2) {
+  return window['go']['main']['App']['RemoveTagsFromFiles'](arg1, arg2);
+}
+
 export function RemoveWorkspaceFolder(arg1) {
   return window['go']['main']['App']['RemoveWorkspaceFolder'](arg1);
 }
@@ -86,6 +230,22 @@ export function RenameFileWithTags(arg1) {
   return window['go']['main']['App']['RenameFileWithTags'](arg1);
 }
 
+export function RenameTag(arg1, arg2) {
+  return window['go']['main']['App']['RenameTag'](arg1, arg2);
+}
+
+export function ResumeOrganize(arg1) {
+  return window['go']['main']['App']['ResumeOrganize'](arg1);
+}
+
+export function RollbackOrganize(arg1) {
+  return window['go']['main']['App']['RollbackOrganize'](arg1);
+}
+
+export function RunOrganizeRule(arg1, arg2) {
+  return window['go']['main']['App']['RunOrganizeRule'](arg1, arg2);
+}
+
 export function SaveWorkspaceConfig(arg1, arg2) {
   return window['go']['main']['App']['SaveWorkspaceConfig'](arg1, arg2);
 }
@@ -94,10 +254,22 @@ export function ScanWorkspaceFolder(arg1) {
   return window['go']['main']['App']['ScanWorkspaceFolder'](arg1);
 }
 
+export function SearchAcrossWorkspaces(arg1) {
+  return window['go']['main']['App']['SearchAcrossWorkspaces'](arg1);
+}
+
+export function SearchFiles(arg1) {
+  return window['go']['main']['App']['SearchFiles'](arg1);
+}
+
 export function SearchFilesByTags(arg1) {
   return window['go']['main']['App']['SearchFilesByTags'](arg1);
 }
 
+export function SearchFilesPage(arg1) {
+  return window['go']['main']['App']['SearchFilesPage'](arg1);
+}
+
 export function SelectWorkspace() {
   return window['go']['main']['App']['SelectWorkspace']();
 }
@@ -106,14 +278,34 @@ export function SetActiveWorkspace(arg1) {
   return window['go']['main']['App']['SetActiveWorkspace'](arg1);
 }
 
+export function SetTagsForFiles(arg1, arg2) {
+  return window['go']['main']['App']['SetTagsForFiles'](arg1, arg2);
+}
+
 export function ShowStartupDialog() {
   return window['go']['main']['App']['ShowStartupDialog']();
 }
 
+export function SuggestTags(arg1, arg2) {
+  return window['go']['main']['App']['SuggestTags'](arg1, arg2);
+}
+
+export function Undo(arg1) {
+  return window['go']['main']['App']['Undo'](arg1);
+}
+
 export function UndoOrganize(arg1) {
   return window['go']['main']['App']['UndoOrganize'](arg1);
 }
 
+export function UpdateOrganizeRule(arg1, arg2) {
+  return window['go']['main']['App']['UpdateOrganizeRule'](arg1, arg2);
+}
+
+export function UpdateSavedSearch(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateSavedSearch'](arg1, arg2, arg3);
+}
+
 export function UpdateSettings(arg1) {
   return window['go']['main']['App']['UpdateSettings'](arg1);
 }
@@ -125,3 +317,11 @@ export function UpdateTagColor(arg1, arg2) {
 export function UpdateWorkspaceConfig(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateWorkspaceConfig'](arg1, arg2, arg3);
 }
+
+export function ValidateOrganizeTemplate(arg1) {
+  return window['go']['main']['App']['ValidateOrganizeTemplate'](arg1);
+}
+
+export function ValidateTagQuery(arg1) {
+  return window['go']['main']['App']['ValidateTagQuery'](arg1);
+}
@@ -22,6 +22,9 @@ export namespace api {
 	    position: string;
 	    addSpaces: boolean;
 	    grouping: string;
+	    filesystem?: string;
+	    maxNameLength?: number;
+	    overflowStrategy?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new TagRuleConfig(source);
@@ -34,6 +37,9 @@ export namespace api {
 	        this.position = source["position"];
 	        this.addSpaces = source["addSpaces"];
 	        this.grouping = source["grouping"];
+	        this.filesystem = source["filesystem"];
+	        this.maxNameLength = source["maxNameLength"];
+	        this.overflowStrategy = source["overflowStrategy"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -56,6 +62,7 @@ export namespace api {
 	}
 	export class AppSettings {
 	    tagRule: TagRuleConfig;
+	    autoOrganizeDryRunOnly?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppSettings(source);
@@ -64,6 +71,7 @@ export namespace api {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.tagRule = this.convertValues(source["tagRule"], TagRuleConfig);
+	        this.autoOrganizeDryRunOnly = source["autoOrganizeDryRunOnly"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -84,7 +92,186 @@ export namespace api {
 		    return a;
 		}
 	}
+	export class TagRenameResult {
+	    file_id: number;
+	    old_name: string;
+	    new_name: string;
+	    status: string;
+	    embedded_tags: number;
+	    overflow_tags?: string[];
+	    message?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagRenameResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file_id = source["file_id"];
+	        this.old_name = source["old_name"];
+	        this.new_name = source["new_name"];
+	        this.status = source["status"];
+	        this.embedded_tags = source["embedded_tags"];
+	        this.overflow_tags = source["overflow_tags"];
+	        this.message = source["message"];
+	    }
+	}
+	export class BatchTagResult {
+	    operation_id: number;
+	    requested: number;
+	    changed: number;
+	    renamed: number;
+	    rename_failed: number;
+	    items: TagRenameResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchTagResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation_id = source["operation_id"];
+	        this.requested = source["requested"];
+	        this.changed = source["changed"];
+	        this.renamed = source["renamed"];
+	        this.rename_failed = source["rename_failed"];
+	        this.items = this.convertValues(source["items"], TagRenameResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileSearchParams {
+	    tag_ids: number[];
+	    tag_query: string;
+	    folder_path: string;
+	    include_subfolders: boolean;
+	    limit: number;
+	    offset: number;
+	    name_query?: string;
+	    extensions?: string[];
+	    min_size?: number;
+	    max_size?: number;
+	    modified_after?: string;
+	    modified_before?: string;
+	    file_type?: string;
+	    untagged?: boolean;
+	    min_tag_count?: number;
+	    max_tag_count?: number;
+	    missing_tag_group_id?: number;
+	    sort_by?: string;
+	    sort_order?: string;
+	    cursor?: string;
+	    include_total?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileSearchParams(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag_ids = source["tag_ids"];
+	        this.tag_query = source["tag_query"];
+	        this.folder_path = source["folder_path"];
+	        this.include_subfolders = source["include_subfolders"];
+	        this.limit = source["limit"];
+	        this.offset = source["offset"];
+	        this.name_query = source["name_query"];
+	        this.extensions = source["extensions"];
+	        this.min_size = source["min_size"];
+	        this.max_size = source["max_size"];
+	        this.modified_after = source["modified_after"];
+	        this.modified_before = source["modified_before"];
+	        this.file_type = source["file_type"];
+	        this.untagged = source["untagged"];
+	        this.min_tag_count = source["min_tag_count"];
+	        this.max_tag_count = source["max_tag_count"];
+	        this.missing_tag_group_id = source["missing_tag_group_id"];
+	        this.sort_by = source["sort_by"];
+	        this.sort_order = source["sort_order"];
+	        this.cursor = source["cursor"];
+	        this.include_total = source["include_total"];
+	    }
+	}
+	export class CrossWorkspaceSearchRequest {
+	    workspace_ids: number[];
+	    params: FileSearchParams;
+	
+	    static createFrom(source: any = {}) {
+	        return new CrossWorkspaceSearchRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workspace_ids = source["workspace_ids"];
+	        this.params = this.convertValues(source["params"], FileSearchParams);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UnavailableWorkspace {
+	    workspace: Workspace;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UnavailableWorkspace(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workspace = this.convertValues(source["workspace"], Workspace);
+	        this.reason = source["reason"];
+	    }
 	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Tag {
 	    id: number;
 	    name: string;
@@ -92,45 +279,1104 @@ export namespace api {
 	    parent_id?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new Tag(source);
+	        return new Tag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.parent_id = source["parent_id"];
+	    }
+	}
+	export class FileRecord {
+	    id: number;
+	    workspace_id: number;
+	    path: string;
+	    name: string;
+	    size: number;
+	    type: string;
+	    mod_time: string;
+	    created_at: string;
+	    hash: string;
+	    tags: Tag[];
+	    absolute_path?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.workspace_id = source["workspace_id"];
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.type = source["type"];
+	        this.mod_time = source["mod_time"];
+	        this.created_at = source["created_at"];
+	        this.hash = source["hash"];
+	        this.tags = this.convertValues(source["tags"], Tag);
+	        this.absolute_path = source["absolute_path"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Workspace {
+	    id: number;
+	    path: string;
+	    name: string;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Workspace(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.created_at = source["created_at"];
+	    }
+	}
+	export class WorkspaceSearchGroup {
+	    workspace: Workspace;
+	    total: number;
+	    records: FileRecord[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkspaceSearchGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workspace = this.convertValues(source["workspace"], Workspace);
+	        this.total = source["total"];
+	        this.records = this.convertValues(source["records"], FileRecord);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CrossWorkspaceSearchResult {
+	    total: number;
+	    groups: WorkspaceSearchGroup[];
+	    unavailable: UnavailableWorkspace[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CrossWorkspaceSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.groups = this.convertValues(source["groups"], WorkspaceSearchGroup);
+	        this.unavailable = this.convertValues(source["unavailable"], UnavailableWorkspace);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class FacetCount {
+	    value: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FacetCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.count = source["count"];
+	    }
+	}
+	export class FileCursorPage {
+	    records: FileRecord[];
+	    next_cursor: string;
+	    total?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileCursorPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.records = this.convertValues(source["records"], FileRecord);
+	        this.next_cursor = source["next_cursor"];
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FilePage {
+	    total: number;
+	    records: FileRecord[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FilePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.records = this.convertValues(source["records"], FileRecord);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class FlattenRequest {
+	    folders: string[];
+	    target: string;
+	    conflict_strategy?: string;
+	    quarantine_folder?: string;
+	    remove_empty_folders?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FlattenRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.folders = source["folders"];
+	        this.target = source["target"];
+	        this.conflict_strategy = source["conflict_strategy"];
+	        this.quarantine_folder = source["quarantine_folder"];
+	        this.remove_empty_folders = source["remove_empty_folders"];
+	    }
+	}
+	export class FolderNode {
+	    path: string;
+	    name: string;
+	    subfolder_count: number;
+	    file_count: number;
+	    total_size: number;
+	    tagged_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.subfolder_count = source["subfolder_count"];
+	        this.file_count = source["file_count"];
+	        this.total_size = source["total_size"];
+	        this.tagged_count = source["tagged_count"];
+	    }
+	}
+	export class FolderTagProgress {
+	    folder_path: string;
+	    total: number;
+	    tagged: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderTagProgress(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.folder_path = source["folder_path"];
+	        this.total = source["total"];
+	        this.tagged = source["tagged"];
+	    }
+	}
+	export class InterruptedOrganize {
+	    operation_id: number;
+	    workspace_id: number;
+	    workspace_path: string;
+	    summary: string;
+	    created_at: string;
+	    total: number;
+	    moved: number;
+	    pending: number;
+	    missing: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new InterruptedOrganize(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation_id = source["operation_id"];
+	        this.workspace_id = source["workspace_id"];
+	        this.workspace_path = source["workspace_path"];
+	        this.summary = source["summary"];
+	        this.created_at = source["created_at"];
+	        this.total = source["total"];
+	        this.moved = source["moved"];
+	        this.pending = source["pending"];
+	        this.missing = source["missing"];
+	    }
+	}
+	export class OperationConflict {
+	    file_id: number;
+	    path?: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OperationConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file_id = source["file_id"];
+	        this.path = source["path"];
+	        this.message = source["message"];
+	    }
+	}
+	export class OperationReplayResult {
+	    operation_id: number;
+	    status: string;
+	    succeeded: number;
+	    skipped: number;
+	    failed: number;
+	    conflicts?: OperationConflict[];
+	    message?: string;
+	    removed_folders?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OperationReplayResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation_id = source["operation_id"];
+	        this.status = source["status"];
+	        this.succeeded = source["succeeded"];
+	        this.skipped = source["skipped"];
+	        this.failed = source["failed"];
+	        this.conflicts = this.convertValues(source["conflicts"], OperationConflict);
+	        this.message = source["message"];
+	        this.removed_folders = source["removed_folders"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OperationSummary {
+	    id: number;
+	    type: string;
+	    action: string;
+	    summary: string;
+	    status: string;
+	    workspace_id?: number;
+	    can_undo: boolean;
+	    can_redo: boolean;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OperationSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.type = source["type"];
+	        this.action = source["action"];
+	        this.summary = source["summary"];
+	        this.status = source["status"];
+	        this.workspace_id = source["workspace_id"];
+	        this.can_undo = source["can_undo"];
+	        this.can_redo = source["can_redo"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+	export class OrganizeLevel {
+	    kind?: string;
+	    tag_ids: number[];
+	    mode?: string;
+	    fallback?: string;
+	    date_format?: string;
+	    size_buckets?: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeLevel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.tag_ids = source["tag_ids"];
+	        this.mode = source["mode"];
+	        this.fallback = source["fallback"];
+	        this.date_format = source["date_format"];
+	        this.size_buckets = source["size_buckets"];
+	    }
+	}
+	export class OrganizeOperationMove {
+	    seq: number;
+	    file_id: number;
+	    from: string;
+	    to: string;
+	    copied?: boolean;
+	    state?: string;
+	    message?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeOperationMove(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.seq = source["seq"];
+	        this.file_id = source["file_id"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.copied = source["copied"];
+	        this.state = source["state"];
+	        this.message = source["message"];
+	    }
+	}
+	export class OrganizeOperationInfo {
+	    id: number;
+	    workspace_id: number;
+	    action: string;
+	    rule_id?: number;
+	    summary: string;
+	    status: string;
+	    can_undo: boolean;
+	    can_redo: boolean;
+	    move_count: number;
+	    copied_count: number;
+	    created_dirs: number;
+	    removed_dirs: number;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeOperationInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.workspace_id = source["workspace_id"];
+	        this.action = source["action"];
+	        this.rule_id = source["rule_id"];
+	        this.summary = source["summary"];
+	        this.status = source["status"];
+	        this.can_undo = source["can_undo"];
+	        this.can_redo = source["can_redo"];
+	        this.move_count = source["move_count"];
+	        this.copied_count = source["copied_count"];
+	        this.created_dirs = source["created_dirs"];
+	        this.removed_dirs = source["removed_dirs"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+	export class OrganizeOperationDetail {
+	    operation: OrganizeOperationInfo;
+	    moves: OrganizeOperationMove[];
+	    created_folders: string[];
+	    removed_folders: string[];
+	    remove_empty_folders: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeOperationDetail(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation = this.convertValues(source["operation"], OrganizeOperationInfo);
+	        this.moves = this.convertValues(source["moves"], OrganizeOperationMove);
+	        this.created_folders = source["created_folders"];
+	        this.removed_folders = source["removed_folders"];
+	        this.remove_empty_folders = source["remove_empty_folders"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class OrganizeSummary {
+	    total: number;
+	    move_count: number;
+	    conflict_count: number;
+	    skip_count: number;
+	    already_in_place: number;
+	    resolved_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.move_count = source["move_count"];
+	        this.conflict_count = source["conflict_count"];
+	        this.skip_count = source["skip_count"];
+	        this.already_in_place = source["already_in_place"];
+	        this.resolved_count = source["resolved_count"];
+	    }
+	}
+	export class OrganizePreviewItem {
+	    file_id: number;
+	    original_path: string;
+	    target_path: string;
+	    status: string;
+	    missing_tags?: string[];
+	    tags?: string[];
+	    message?: string;
+	    resolution?: string;
+	    replaced_path?: string;
+	    replaced_file_id?: number;
+	    quarantine_path?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizePreviewItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file_id = source["file_id"];
+	        this.original_path = source["original_path"];
+	        this.target_path = source["target_path"];
+	        this.status = source["status"];
+	        this.missing_tags = source["missing_tags"];
+	        this.tags = source["tags"];
+	        this.message = source["message"];
+	        this.resolution = source["resolution"];
+	        this.replaced_path = source["replaced_path"];
+	        this.replaced_file_id = source["replaced_file_id"];
+	        this.quarantine_path = source["quarantine_path"];
+	    }
+	}
+	export class OrganizePreview {
+	    items: OrganizePreviewItem[];
+	    summary: OrganizeSummary;
+	    base_path: string;
+	    template: string;
+	    quarantine_dir?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizePreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], OrganizePreviewItem);
+	        this.summary = this.convertValues(source["summary"], OrganizeSummary);
+	        this.base_path = source["base_path"];
+	        this.template = source["template"];
+	        this.quarantine_dir = source["quarantine_dir"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class OrganizeRequest {
+	    levels: OrganizeLevel[];
+	    template?: string;
+	    conflict_strategy?: string;
+	    quarantine_folder?: string;
+	    remove_empty_folders?: boolean;
+	    source_folder?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.levels = this.convertValues(source["levels"], OrganizeLevel);
+	        this.template = source["template"];
+	        this.conflict_strategy = source["conflict_strategy"];
+	        this.quarantine_folder = source["quarantine_folder"];
+	        this.remove_empty_folders = source["remove_empty_folders"];
+	        this.source_folder = source["source_folder"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OrganizeResult {
+	    preview: OrganizePreview;
+	    operation_id: number;
+	    removed_folders?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preview = this.convertValues(source["preview"], OrganizePreview);
+	        this.operation_id = source["operation_id"];
+	        this.removed_folders = source["removed_folders"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OrganizeRule {
+	    id: number;
+	    workspace_id: number;
+	    name: string;
+	    source_folder: string;
+	    request: OrganizeRequest;
+	    interval_minutes: number;
+	    on_new_files: boolean;
+	    dry_run: boolean;
+	    enabled: boolean;
+	    last_run_at?: string;
+	    last_status?: string;
+	    last_message?: string;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.workspace_id = source["workspace_id"];
+	        this.name = source["name"];
+	        this.source_folder = source["source_folder"];
+	        this.request = this.convertValues(source["request"], OrganizeRequest);
+	        this.interval_minutes = source["interval_minutes"];
+	        this.on_new_files = source["on_new_files"];
+	        this.dry_run = source["dry_run"];
+	        this.enabled = source["enabled"];
+	        this.last_run_at = source["last_run_at"];
+	        this.last_status = source["last_status"];
+	        this.last_message = source["last_message"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OrganizeRuleInput {
+	    name: string;
+	    source_folder: string;
+	    request: OrganizeRequest;
+	    interval_minutes: number;
+	    on_new_files: boolean;
+	    dry_run: boolean;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeRuleInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.source_folder = source["source_folder"];
+	        this.request = this.convertValues(source["request"], OrganizeRequest);
+	        this.interval_minutes = source["interval_minutes"];
+	        this.on_new_files = source["on_new_files"];
+	        this.dry_run = source["dry_run"];
+	        this.enabled = source["enabled"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OrganizeRuleRun {
+	    id: number;
+	    rule_id: number;
+	    trigger: string;
+	    dry_run: boolean;
+	    status: string;
+	    operation_id?: number;
+	    move_count: number;
+	    conflict_count: number;
+	    skip_count: number;
+	    message?: string;
+	    has_preview: boolean;
+	    started_at: string;
+	    finished_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeRuleRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.rule_id = source["rule_id"];
+	        this.trigger = source["trigger"];
+	        this.dry_run = source["dry_run"];
+	        this.status = source["status"];
+	        this.operation_id = source["operation_id"];
+	        this.move_count = source["move_count"];
+	        this.conflict_count = source["conflict_count"];
+	        this.skip_count = source["skip_count"];
+	        this.message = source["message"];
+	        this.has_preview = source["has_preview"];
+	        this.started_at = source["started_at"];
+	        this.finished_at = source["finished_at"];
+	    }
+	}
+	
+	export class OrganizeTemplateCheck {
+	    valid: boolean;
+	    message?: string;
+	    offset: number;
+	    length: number;
+	    template: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeTemplateCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.valid = source["valid"];
+	        this.message = source["message"];
+	        this.offset = source["offset"];
+	        this.length = source["length"];
+	        this.template = source["template"];
+	    }
+	}
+	export class OrganizeUndoResult {
+	    restored: number;
+	    failed: number;
+	    message?: string;
+	    removed_folders?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeUndoResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.restored = source["restored"];
+	        this.failed = source["failed"];
+	        this.message = source["message"];
+	        this.removed_folders = source["removed_folders"];
+	    }
+	}
+	export class OrganizeView {
+	    id: number;
+	    workspace_id: number;
+	    path: string;
+	    link_type: string;
+	    organize: OrganizeRequest;
+	    link_count: number;
+	    built_at?: string;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.workspace_id = source["workspace_id"];
+	        this.path = source["path"];
+	        this.link_type = source["link_type"];
+	        this.organize = this.convertValues(source["organize"], OrganizeRequest);
+	        this.link_count = source["link_count"];
+	        this.built_at = source["built_at"];
+	        this.created_at = source["created_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OrganizeViewRemoval {
+	    removed: number;
+	    kept?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeViewRemoval(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.removed = source["removed"];
+	        this.kept = source["kept"];
+	    }
+	}
+	export class OrganizeViewRequest {
+	    organize: OrganizeRequest;
+	    path: string;
+	    link_type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeViewRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.organize = this.convertValues(source["organize"], OrganizeRequest);
+	        this.path = source["path"];
+	        this.link_type = source["link_type"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OrganizeViewResult {
+	    view: OrganizeView;
+	    preview: OrganizePreview;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrganizeViewResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.view = this.convertValues(source["view"], OrganizeView);
+	        this.preview = this.convertValues(source["preview"], OrganizePreview);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SavedSearch {
+	    id: number;
+	    workspace_id?: number;
+	    name: string;
+	    params: FileSearchParams;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedSearch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.workspace_id = source["workspace_id"];
+	        this.name = source["name"];
+	        this.params = this.convertValues(source["params"], FileSearchParams);
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScanResult {
+	    workspace: Workspace;
+	    file_count: number;
+	    directory_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workspace = this.convertValues(source["workspace"], Workspace);
+	        this.file_count = source["file_count"];
+	        this.directory_count = source["directory_count"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TagFacet {
+	    tag_id: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagFacet(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.color = source["color"];
-	        this.parent_id = source["parent_id"];
+	        this.tag_id = source["tag_id"];
+	        this.count = source["count"];
 	    }
 	}
-	export class FileRecord {
-	    id: number;
-	    workspace_id: number;
-	    path: string;
-	    name: string;
-	    size: number;
-	    type: string;
-	    mod_time: string;
-	    created_at: string;
-	    hash: string;
-	    tags: Tag[];
+	export class SearchFacets {
+	    total: number;
+	    tags: TagFacet[];
+	    types: FacetCount[];
+	    extensions: FacetCount[];
 	
 	    static createFrom(source: any = {}) {
-	        return new FileRecord(source);
+	        return new SearchFacets(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.workspace_id = source["workspace_id"];
-	        this.path = source["path"];
-	        this.name = source["name"];
-	        this.size = source["size"];
-	        this.type = source["type"];
-	        this.mod_time = source["mod_time"];
-	        this.created_at = source["created_at"];
-	        this.hash = source["hash"];
-	        this.tags = this.convertValues(source["tags"], Tag);
+	        this.total = source["total"];
+	        this.tags = this.convertValues(source["tags"], TagFacet);
+	        this.types = this.convertValues(source["types"], FacetCount);
+	        this.extensions = this.convertValues(source["extensions"], FacetCount);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -151,18 +1397,26 @@ export namespace api {
 		    return a;
 		}
 	}
-	export class FilePage {
+	export class TagSuggestionReason {
+	    kind: string;
+	    message: string;
+	    count: number;
 	    total: number;
-	    records: FileRecord[];
+	    related_tag?: Tag;
+	    keyword?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FilePage(source);
+	        return new TagSuggestionReason(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.message = source["message"];
+	        this.count = source["count"];
 	        this.total = source["total"];
-	        this.records = this.convertValues(source["records"], FileRecord);
+	        this.related_tag = this.convertValues(source["related_tag"], Tag);
+	        this.keyword = source["keyword"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -183,97 +1437,174 @@ export namespace api {
 		    return a;
 		}
 	}
-	
-	export class FileSearchParams {
-	    tag_ids: number[];
-	    folder_path: string;
-	    include_subfolders: boolean;
-	    limit: number;
-	    offset: number;
+	export class SuggestedTag {
+	    tag: Tag;
+	    score: number;
+	    reasons: TagSuggestionReason[];
 	
 	    static createFrom(source: any = {}) {
-	        return new FileSearchParams(source);
+	        return new SuggestedTag(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.tag_ids = source["tag_ids"];
-	        this.folder_path = source["folder_path"];
-	        this.include_subfolders = source["include_subfolders"];
-	        this.limit = source["limit"];
-	        this.offset = source["offset"];
+	        this.tag = this.convertValues(source["tag"], Tag);
+	        this.score = source["score"];
+	        this.reasons = this.convertValues(source["reasons"], TagSuggestionReason);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class OrganizeLevel {
-	    tag_ids: number[];
+	
+	export class TagEditResult {
+	    operation_id: number;
+	    changed: number;
+	    renamed: number;
+	    rename_failed: number;
+	    items: TagRenameResult[];
 	
 	    static createFrom(source: any = {}) {
-	        return new OrganizeLevel(source);
+	        return new TagEditResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.tag_ids = source["tag_ids"];
+	        this.operation_id = source["operation_id"];
+	        this.changed = source["changed"];
+	        this.renamed = source["renamed"];
+	        this.rename_failed = source["rename_failed"];
+	        this.items = this.convertValues(source["items"], TagRenameResult);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class OrganizeSummary {
-	    total: number;
-	    move_count: number;
-	    conflict_count: number;
-	    skip_count: number;
-	    already_in_place: number;
+	
+	export class TagQueryCheck {
+	    valid: boolean;
+	    message?: string;
+	    offset: number;
+	    length: number;
+	    tags?: Tag[];
 	
 	    static createFrom(source: any = {}) {
-	        return new OrganizeSummary(source);
+	        return new TagQueryCheck(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.total = source["total"];
-	        this.move_count = source["move_count"];
-	        this.conflict_count = source["conflict_count"];
-	        this.skip_count = source["skip_count"];
-	        this.already_in_place = source["already_in_place"];
+	        this.valid = source["valid"];
+	        this.message = source["message"];
+	        this.offset = source["offset"];
+	        this.length = source["length"];
+	        this.tags = this.convertValues(source["tags"], Tag);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class OrganizePreviewItem {
+	export class TagReconcileApplyItem {
 	    file_id: number;
-	    original_path: string;
-	    target_path: string;
-	    status: string;
-	    missing_tags?: string[];
-	    tags?: string[];
+	    strategy: string;
+	    success: boolean;
 	    message?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new OrganizePreviewItem(source);
+	        return new TagReconcileApplyItem(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.file_id = source["file_id"];
-	        this.original_path = source["original_path"];
-	        this.target_path = source["target_path"];
-	        this.status = source["status"];
-	        this.missing_tags = source["missing_tags"];
-	        this.tags = source["tags"];
+	        this.strategy = source["strategy"];
+	        this.success = source["success"];
 	        this.message = source["message"];
 	    }
 	}
-	export class OrganizePreview {
-	    items: OrganizePreviewItem[];
-	    summary: OrganizeSummary;
-	    base_path: string;
+	export class TagReconcileItem {
+	    file_id: number;
+	    path: string;
+	    name: string;
+	    disk_tags: string[];
+	    db_tags: string[];
+	    only_on_disk: string[];
+	    only_in_db: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new OrganizePreview(source);
+	        return new TagReconcileItem(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.items = this.convertValues(source["items"], OrganizePreviewItem);
-	        this.summary = this.convertValues(source["summary"], OrganizeSummary);
-	        this.base_path = source["base_path"];
+	        this.file_id = source["file_id"];
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.disk_tags = source["disk_tags"];
+	        this.db_tags = source["db_tags"];
+	        this.only_on_disk = source["only_on_disk"];
+	        this.only_in_db = source["only_in_db"];
+	    }
+	}
+	export class TagReconcileReport {
+	    workspace_id: number;
+	    scanned_count: number;
+	    items: TagReconcileItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TagReconcileReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workspace_id = source["workspace_id"];
+	        this.scanned_count = source["scanned_count"];
+	        this.items = this.convertValues(source["items"], TagReconcileItem);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -294,17 +1625,34 @@ export namespace api {
 		    return a;
 		}
 	}
+	export class TagReconcileResolution {
+	    file_id: number;
+	    strategy: string;
 	
-	export class OrganizeRequest {
-	    levels: OrganizeLevel[];
+	    static createFrom(source: any = {}) {
+	        return new TagReconcileResolution(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file_id = source["file_id"];
+	        this.strategy = source["strategy"];
+	    }
+	}
+	export class TagReconcileRequest {
+	    workspace_id: number;
+	    strategy: string;
+	    resolutions: TagReconcileResolution[];
 	
 	    static createFrom(source: any = {}) {
-	        return new OrganizeRequest(source);
+	        return new TagReconcileRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.levels = this.convertValues(source["levels"], OrganizeLevel);
+	        this.workspace_id = source["workspace_id"];
+	        this.strategy = source["strategy"];
+	        this.resolutions = this.convertValues(source["resolutions"], TagReconcileResolution);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -325,18 +1673,21 @@ export namespace api {
 		    return a;
 		}
 	}
-	export class OrganizeResult {
-	    preview: OrganizePreview;
-	    operation_id: number;
+	
+	export class TagReconcileResult {
+	    applied: number;
+	    failed: number;
+	    items: TagReconcileApplyItem[];
 	
 	    static createFrom(source: any = {}) {
-	        return new OrganizeResult(source);
+	        return new TagReconcileResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.preview = this.convertValues(source["preview"], OrganizePreview);
-	        this.operation_id = source["operation_id"];
+	        this.applied = source["applied"];
+	        this.failed = source["failed"];
+	        this.items = this.convertValues(source["items"], TagReconcileApplyItem);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -358,54 +1709,19 @@ export namespace api {
 		}
 	}
 	
-	export class OrganizeUndoResult {
-	    restored: number;
-	    failed: number;
-	    message?: string;
-	
-	    static createFrom(source: any = {}) {
-	        return new OrganizeUndoResult(source);
-	    }
-	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.restored = source["restored"];
-	        this.failed = source["failed"];
-	        this.message = source["message"];
-	    }
-	}
-	export class Workspace {
-	    id: number;
-	    path: string;
-	    name: string;
-	    created_at: string;
-	
-	    static createFrom(source: any = {}) {
-	        return new Workspace(source);
-	    }
 	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.path = source["path"];
-	        this.name = source["name"];
-	        this.created_at = source["created_at"];
-	    }
-	}
-	export class ScanResult {
-	    workspace: Workspace;
-	    file_count: number;
-	    directory_count: number;
+	export class TagSuggestion {
+	    tag: Tag;
+	    match: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ScanResult(source);
+	        return new TagSuggestion(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.workspace = this.convertValues(source["workspace"], Workspace);
-	        this.file_count = source["file_count"];
-	        this.directory_count = source["directory_count"];
+	        this.tag = this.convertValues(source["tag"], Tag);
+	        this.match = source["match"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -428,6 +1744,7 @@ export namespace api {
 	}
 	
 	
+	
 
 }
 
@@ -453,13 +1770,49 @@ export namespace main {
 	        this.opened_at = source["opened_at"];
 	    }
 	}
+	export class SavedSearchConfig {
+	    name: string;
+	    folder?: string;
+	    params: api.FileSearchParams;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedSearchConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.folder = source["folder"];
+	        this.params = this.convertValues(source["params"], api.FileSearchParams);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class WorkspaceConfig {
 	    name: string;
 	    folders: string[];
 	    // Go type: time
 	    created_at: any;
 	    version: string;
+	    saved_searches?: SavedSearchConfig[];
 	    file_path?: string;
+	    skipped_searches?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new WorkspaceConfig(source);
@@ -471,7 +1824,9 @@ export namespace main {
 	        this.folders = source["folders"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.version = source["version"];
+	        this.saved_searches = this.convertValues(source["saved_searches"], SavedSearchConfig);
 	        this.file_path = source["file_path"];
+	        this.skipped_searches = source["skipped_searches"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -4,8 +4,10 @@ go 1.24.0
 
 require (
 	github.com/disintegration/imaging v1.6.2
+	github.com/mozillazg/go-pinyin v0.21.0
 	github.com/wailsapp/wails/v2 v2.11.0
 	go.uber.org/zap v1.27.1
+	golang.org/x/text v0.22.0
 	modernc.org/sqlite v1.40.1
 )
 
@@ -42,7 +44,6 @@ require (
 	golang.org/x/image v0.12.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
 	modernc.org/libc v1.66.10 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
@@ -35,6 +35,8 @@ type FileRecord struct {
 	CreatedAt   string `json:"created_at"`
 	Hash        string `json:"hash"`
 	Tags        []Tag  `json:"tags"`
+
+	AbsolutePath string `json:"absolute_path,omitempty"` // 仅跨工作区搜索时填充
 }
 
 // FilePage 描述分页结果
@@ -43,6 +45,13 @@ type FilePage struct {
 	Records []FileRecord `json:"records"`
 }
 
+// FileCursorPage 描述游标分页结果
+type FileCursorPage struct {
+	Records    []FileRecord `json:"records"`
+	NextCursor string       `json:"next_cursor"`     // 为空表示已到末尾
+	Total      *int64       `json:"total,omitempty"` // 仅在请求统计时返回
+}
+
 // TagRuleConfig 标签应用规则配置
 type TagRuleConfig struct {
 	Format       string        `json:"format"`       // 标签格式类型
@@ -50,6 +59,10 @@ type TagRuleConfig struct {
 	Position     string        `json:"position"`     // 标签位置 prefix/suffix
 	AddSpaces    bool          `json:"addSpaces"`    // 是否添加空格
 	Grouping     string        `json:"grouping"`     // 标签组合方式 combined/individual
+	// 文件名安全规则（为空时使用默认值）
+	Filesystem       string `json:"filesystem,omitempty"`       // 目标文件系统 auto/posix/windows
+	MaxNameLength    int    `json:"maxNameLength,omitempty"`    // 文件名最大长度（posix 按字节，windows 按 UTF-16 单元），0 表示 255
+	OverflowStrategy string `json:"overflowStrategy,omitempty"` // 文件名超长时的策略 truncate/db_only/fail
 }
 
 // CustomFormat 自定义标签格式
@@ -62,36 +75,185 @@ type CustomFormat struct {
 // AppSettings 应用设置
 type AppSettings struct {
 	TagRule TagRuleConfig `json:"tagRule"`
+	// AutoOrganizeDryRunOnly 为 true 时自动整理规则只生成预览，不移动任何文件
+	AutoOrganizeDryRunOnly bool `json:"autoOrganizeDryRunOnly,omitempty"`
 }
 
 // FileSearchParams 文件搜索参数
 type FileSearchParams struct {
 	TagIDs            []int64 `json:"tag_ids"`            // 要筛选的标签ID列表
+	TagQuery          string  `json:"tag_query"`          // 布尔标签表达式，如 (客户A OR 客户B) AND 2024 AND NOT 草稿
 	FolderPath        string  `json:"folder_path"`        // 文件夹路径（相对路径），为空则搜索整个工作区
 	IncludeSubfolders bool    `json:"include_subfolders"` // 是否包含子文件夹
 	Limit             int     `json:"limit"`
 	Offset            int     `json:"offset"`
+
+	NameQuery      string   `json:"name_query,omitempty"`      // 文件名子串，包含 * 或 ? 时按通配符匹配
+	Extensions     []string `json:"extensions,omitempty"`      // 扩展名集合，如 ["jpg", "png"]
+	MinSize        *int64   `json:"min_size,omitempty"`        // 最小大小（字节，含）
+	MaxSize        *int64   `json:"max_size,omitempty"`        // 最大大小（字节，含）
+	ModifiedAfter  string   `json:"modified_after,omitempty"`  // 修改时间下限（含），RFC3339 或 2006-01-02
+	ModifiedBefore string   `json:"modified_before,omitempty"` // 修改时间上限，RFC3339（不含）或 2006-01-02（含当天）
+	FileType       string   `json:"file_type,omitempty"`       // file/dir/all，为空时只查文件
+
+	Untagged          bool  `json:"untagged,omitempty"`             // 仅查询没有任何标签的文件
+	MinTagCount       *int  `json:"min_tag_count,omitempty"`        // 标签数量下限（含）
+	MaxTagCount       *int  `json:"max_tag_count,omitempty"`        // 标签数量上限（含），与下限相同表示恰好 N 个
+	MissingTagGroupID int64 `json:"missing_tag_group_id,omitempty"` // 没有该分组（父标签及其子标签）中的任何标签
+
+	SortBy    string `json:"sort_by,omitempty"`    // name/size/mod_time/created_at/tag_count/extension/path/relevance，为空时有关键字按相关度、否则按路径
+	SortOrder string `json:"sort_order,omitempty"` // asc/desc，默认 asc
+
+	Cursor       string `json:"cursor,omitempty"`        // 游标分页：上一页返回的 next_cursor
+	IncludeTotal bool   `json:"include_total,omitempty"` // 游标分页时是否统计总数
+}
+
+// CrossWorkspaceSearchRequest 跨工作区搜索请求
+type CrossWorkspaceSearchRequest struct {
+	WorkspaceIDs []int64          `json:"workspace_ids"` // 为空表示全部已知工作区
+	Params       FileSearchParams `json:"params"`        // Limit/Offset 对每个工作区分别生效
+}
+
+// WorkspaceSearchGroup 单个工作区的搜索结果
+type WorkspaceSearchGroup struct {
+	Workspace Workspace    `json:"workspace"`
+	Total     int64        `json:"total"`
+	Records   []FileRecord `json:"records"`
+}
+
+// UnavailableWorkspace 因离线或出错而未能搜索的工作区
+type UnavailableWorkspace struct {
+	Workspace Workspace `json:"workspace"`
+	Reason    string    `json:"reason"`
+}
+
+// CrossWorkspaceSearchResult 跨工作区搜索结果，仅包含有匹配项的工作区
+type CrossWorkspaceSearchResult struct {
+	Total       int64                  `json:"total"`
+	Groups      []WorkspaceSearchGroup `json:"groups"`
+	Unavailable []UnavailableWorkspace `json:"unavailable"`
+}
+
+// TagFacet 当前结果集中拥有某标签的文件数
+type TagFacet struct {
+	TagID int64 `json:"tag_id"`
+	Count int64 `json:"count"`
+}
+
+// FacetCount 按取值统计的文件数
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchFacets 搜索结果的分面统计，用于逐步缩小筛选范围
+type SearchFacets struct {
+	Total      int64        `json:"total"`
+	Tags       []TagFacet   `json:"tags"`
+	Types      []FacetCount `json:"types"`
+	Extensions []FacetCount `json:"extensions"` // 扩展名为空字符串表示无扩展名（或目录）
+}
+
+// FolderNode 文件夹树节点，统计值均按递归计算（仅普通文件）
+type FolderNode struct {
+	Path           string `json:"path"` // 相对工作区的路径
+	Name           string `json:"name"`
+	SubfolderCount int64  `json:"subfolder_count"` // 直接子文件夹数量，用于判断能否展开
+	FileCount      int64  `json:"file_count"`
+	TotalSize      int64  `json:"total_size"`
+	TaggedCount    int64  `json:"tagged_count"`
+}
+
+// FolderTagProgress 文件夹打标签进度，如 "9,870 个文件中已有 1,204 个打了标签"
+type FolderTagProgress struct {
+	FolderPath string `json:"folder_path"` // 相对路径，空字符串表示工作区根目录
+	Total      int64  `json:"total"`
+	Tagged     int64  `json:"tagged"`
+}
+
+// SavedSearch 保存的搜索（智能文件夹）
+type SavedSearch struct {
+	ID          int64            `json:"id"`
+	WorkspaceID *int64           `json:"workspace_id,omitempty"` // 为空表示全局可用
+	Name        string           `json:"name"`
+	Params      FileSearchParams `json:"params"`
+	CreatedAt   string           `json:"created_at"`
+	UpdatedAt   string           `json:"updated_at"`
+}
+
+// TagSuggestion 标签联想结果
+type TagSuggestion struct {
+	Tag   Tag    `json:"tag"`
+	Match string `json:"match"` // exact/prefix/initials/contains
+}
+
+// TagSuggestionReason 标签推荐理由
+type TagSuggestionReason struct {
+	Kind       string `json:"kind"` // cooccurrence/sibling/similar_name
+	Message    string `json:"message"`
+	Count      int64  `json:"count"` // total 个相关文件中有 count 个使用了该标签
+	Total      int64  `json:"total"`
+	RelatedTag *Tag   `json:"related_tag,omitempty"` // 共现来源对应的现有标签
+	Keyword    string `json:"keyword,omitempty"`     // 名称相似来源匹配的关键字
+}
+
+// SuggestedTag 为文件推荐的标签
+type SuggestedTag struct {
+	Tag     Tag                   `json:"tag"`
+	Score   float64               `json:"score"`
+	Reasons []TagSuggestionReason `json:"reasons"`
 }
 
-// OrganizeLevel 描述单层需要匹配的标签（同级可以配置多个标签）
+// TagQueryCheck 标签表达式校验结果，Offset/Length 以字符（UTF-16 单元）计
+type TagQueryCheck struct {
+	Valid   bool   `json:"valid"`
+	Message string `json:"message,omitempty"`
+	Offset  int    `json:"offset"`
+	Length  int    `json:"length"`
+	Tags    []Tag  `json:"tags,omitempty"` // 表达式引用到的标签
+}
+
+// OrganizeLevel 描述单层的分组依据：需要匹配的标签（同级可以配置多个标签）或文件属性
 type OrganizeLevel struct {
-	TagIDs []int64 `json:"tag_ids"`
+	// 分组依据：tag（默认）/extension（扩展名）/category（文件类别）/mtime（修改时间）/
+	// exif_date（EXIF 拍摄时间）/size（大小区间）/parent（所在文件夹名）
+	Kind     string  `json:"kind,omitempty"`
+	TagIDs   []int64 `json:"tag_ids"`
+	Mode     string  `json:"mode,omitempty"`     // all（默认，需全部具备）/first（第一个匹配）/each（全部匹配到的）
+	Fallback string  `json:"fallback,omitempty"` // 不满足该级时使用的目录，例如「未分类」；为空则跳过文件
+	// mtime、exif_date 的目录名格式（Go 时间格式），默认 2006-01，按年分组可用 2006
+	DateFormat string `json:"date_format,omitempty"`
+	// size 的区间边界（字节，升序），默认 1MB/10MB/100MB/1GB
+	SizeBuckets []int64 `json:"size_buckets,omitempty"`
 }
 
 // OrganizeRequest 代表整理请求
 type OrganizeRequest struct {
-	Levels []OrganizeLevel `json:"levels"`
+	Levels   []OrganizeLevel `json:"levels"`
+	Template string          `json:"template,omitempty"` // 目标路径模板，为空时按层级生成 {level1}/…/{name}{ext}
+	// 冲突处理方式：block（默认，拒绝执行）/skip/suffix/keep_newer/keep_larger/quarantine
+	ConflictStrategy string `json:"conflict_strategy,omitempty"`
+	QuarantineFolder string `json:"quarantine_folder,omitempty"` // 被替换文件存放的目录，默认「_隔离区」
+	// 删除因整理而变空的原文件夹；撤销时同样删除整理创建且已变空的文件夹
+	RemoveEmptyFolders bool `json:"remove_empty_folders,omitempty"`
+	// 只整理该文件夹（相对工作区，含子文件夹）中的文件，为空表示整个工作区；该文件夹本身不会被当作空文件夹删除
+	SourceFolder string `json:"source_folder,omitempty"`
 }
 
 // OrganizePreviewItem 代表一次整理中的单个文件预览
 type OrganizePreviewItem struct {
 	FileID       int64    `json:"file_id"`
-	OriginalPath string   `json:"original_path"` // 相对路径，包含文件名
-	TargetPath   string   `json:"target_path"`   // 相对路径，包含文件名
-	Status       string   `json:"status"`        // move/conflict/skip_missing_tags/already_in_place
-	MissingTags  []string `json:"missing_tags,omitempty"`
+	OriginalPath string   `json:"original_path"`          // 相对路径，包含文件名
+	TargetPath   string   `json:"target_path"`            // 相对路径，包含文件名
+	Status       string   `json:"status"`                 // move/conflict/skip_missing_tags/skip_conflict/already_in_place
+	MissingTags  []string `json:"missing_tags,omitempty"` // 缺少的标签或文件属性（如「拍摄时间」）
 	Tags         []string `json:"tags,omitempty"`
 	Message      string   `json:"message,omitempty"`
+	Resolution   string   `json:"resolution,omitempty"` // 冲突处理结果：skip/suffix/replace/quarantine
+	// 目标位置原有的文件及其将被移入的隔离区路径（Resolution 为 replace 时）
+	ReplacedPath   string `json:"replaced_path,omitempty"`
+	ReplacedFileID int64  `json:"replaced_file_id,omitempty"`
+	QuarantinePath string `json:"quarantine_path,omitempty"`
 }
 
 // OrganizeSummary 汇总统计
@@ -101,6 +263,7 @@ type OrganizeSummary struct {
 	ConflictCount  int `json:"conflict_count"`
 	SkipCount      int `json:"skip_count"`
 	AlreadyInPlace int `json:"already_in_place"`
+	ResolvedCount  int `json:"resolved_count"` // 按冲突策略处理的文件数
 }
 
 // OrganizePreview 预览结果
@@ -108,30 +271,344 @@ type OrganizePreview struct {
 	Items    []OrganizePreviewItem `json:"items"`
 	Summary  OrganizeSummary       `json:"summary"`
 	BasePath string                `json:"base_path"`
+	Template string                `json:"template"` // 实际使用的路径模板
+	// 本次整理使用的隔离区目录，没有文件移入隔离区时为空
+	QuarantineDir string `json:"quarantine_dir,omitempty"`
+}
+
+// OrganizeTemplateCheck 路径模板校验结果，Offset/Length 以字符（UTF-16 单元）计
+type OrganizeTemplateCheck struct {
+	Valid    bool   `json:"valid"`
+	Message  string `json:"message,omitempty"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	Template string `json:"template"` // 实际使用的路径模板
 }
 
 // OrganizeMoveRecord 用于记录一次整理的单个移动
 type OrganizeMoveRecord struct {
 	FileID int64  `json:"file_id"`
-	From   string `json:"from"` // 相对路径（包含文件名）
-	To     string `json:"to"`   // 相对路径（包含文件名）
+	From   string `json:"from"`             // 相对路径（包含文件名）
+	To     string `json:"to"`               // 相对路径（包含文件名）
+	Copied bool   `json:"copied,omitempty"` // 跨设备，以复制后删除源文件的方式完成
 }
 
 // OrganizeOperationPayload 存储在 operations.payload 中，便于撤销
 type OrganizeOperationPayload struct {
-	WorkspaceID int64                `json:"workspace_id"`
-	Moves       []OrganizeMoveRecord `json:"moves"`
+	Action             string               `json:"action,omitempty"`  // organize/flatten/auto_organize，旧记录为空表示 organize
+	RuleID             int64                `json:"rule_id,omitempty"` // 自动整理规则 ID
+	WorkspaceID        int64                `json:"workspace_id"`
+	Moves              []OrganizeMoveRecord `json:"moves"`
+	CreatedDirs        []string             `json:"created_dirs,omitempty"` // 本次整理新建的文件夹（相对路径，由浅到深）
+	RemovedDirs        []string             `json:"removed_dirs,omitempty"` // 执行后删除的空文件夹
+	RemoveEmptyFolders bool                 `json:"remove_empty_folders,omitempty"`
+	KeepFolder         string               `json:"keep_folder,omitempty"`    // 整理范围的根文件夹，不会被当作空文件夹删除
+	QuarantineDir      string               `json:"quarantine_dir,omitempty"` // 本次整理创建的隔离区目录，撤销后变空时删除
+}
+
+// OrganizeOperationInfo 整理操作历史中的一条记录
+type OrganizeOperationInfo struct {
+	ID          int64  `json:"id"`
+	WorkspaceID int64  `json:"workspace_id"`
+	Action      string `json:"action"`            // organize/flatten/auto_organize
+	RuleID      int64  `json:"rule_id,omitempty"` // 自动整理规则 ID
+	Summary     string `json:"summary"`
+	Status      string `json:"status"` // running/applied/undone
+	CanUndo     bool   `json:"can_undo"`
+	CanRedo     bool   `json:"can_redo"`
+	MoveCount   int    `json:"move_count"`
+	CopiedCount int    `json:"copied_count"` // 跨设备复制的文件数
+	CreatedDirs int    `json:"created_dirs"` // 新建的文件夹数
+	RemovedDirs int    `json:"removed_dirs"` // 删除的空文件夹数
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// OrganizeOperationMove 整理操作详情中的单个移动
+type OrganizeOperationMove struct {
+	Seq     int    `json:"seq"`
+	FileID  int64  `json:"file_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Copied  bool   `json:"copied,omitempty"`
+	State   string `json:"state,omitempty"` // 仅执行中的操作：pending/done/failed/rolled_back
+	Message string `json:"message,omitempty"`
+}
+
+// OrganizeOperationDetail 整理操作的完整记录
+type OrganizeOperationDetail struct {
+	Operation          OrganizeOperationInfo   `json:"operation"`
+	Moves              []OrganizeOperationMove `json:"moves"`
+	CreatedFolders     []string                `json:"created_folders"`
+	RemovedFolders     []string                `json:"removed_folders"`
+	RemoveEmptyFolders bool                    `json:"remove_empty_folders"`
 }
 
 // OrganizeResult 执行整理后的结果
 type OrganizeResult struct {
-	Preview     OrganizePreview `json:"preview"`
-	OperationID int64           `json:"operation_id"`
+	Preview        OrganizePreview `json:"preview"`
+	OperationID    int64           `json:"operation_id"`
+	RemovedFolders []string        `json:"removed_folders,omitempty"` // 删除的空文件夹
+}
+
+// FlattenRequest 展平请求：把若干文件夹（通常是整理创建的）中的文件移动到同一个文件夹
+type FlattenRequest struct {
+	Folders            []string `json:"folders"` // 相对工作区的文件夹，包含其子文件夹中的文件
+	Target             string   `json:"target"`  // 目标文件夹，为空表示工作区根目录
+	ConflictStrategy   string   `json:"conflict_strategy,omitempty"`
+	QuarantineFolder   string   `json:"quarantine_folder,omitempty"`
+	RemoveEmptyFolders bool     `json:"remove_empty_folders,omitempty"`
 }
 
 // OrganizeUndoResult 撤销整理的结果
 type OrganizeUndoResult struct {
-	Restored int    `json:"restored"`
-	Failed   int    `json:"failed"`
+	Restored       int      `json:"restored"`
+	Failed         int      `json:"failed"`
+	Message        string   `json:"message,omitempty"`
+	RemovedFolders []string `json:"removed_folders,omitempty"`
+}
+
+// OrganizeViewRequest 创建整理视图的请求：按整理计划在工作区外生成链接目录树，不移动原文件
+type OrganizeViewRequest struct {
+	Organize OrganizeRequest `json:"organize"`
+	Path     string          `json:"path"`      // 视图目录的绝对路径，须位于工作区之外且不存在或为空
+	LinkType string          `json:"link_type"` // symlink（默认）/hardlink（须与工作区位于同一设备）
+}
+
+// OrganizeView 已保存的整理视图
+type OrganizeView struct {
+	ID          int64           `json:"id"`
+	WorkspaceID int64           `json:"workspace_id"`
+	Path        string          `json:"path"`
+	LinkType    string          `json:"link_type"`
+	Organize    OrganizeRequest `json:"organize"`
+	LinkCount   int             `json:"link_count"`
+	BuiltAt     string          `json:"built_at,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+// OrganizeViewResult 生成整理视图的结果，预览中 move 状态的条目即生成的链接，目标路径相对视图目录
+type OrganizeViewResult struct {
+	View    OrganizeView    `json:"view"`
+	Preview OrganizePreview `json:"preview"`
+}
+
+// OrganizeViewRemoval 删除整理视图的结果
+type OrganizeViewRemoval struct {
+	Removed int      `json:"removed"`        // 删除的链接数
+	Kept    []string `json:"kept,omitempty"` // 非本程序生成或无法安全删除而保留的文件（相对视图目录）
+}
+
+// OrganizeRuleInput 新建或修改自动整理规则
+type OrganizeRuleInput struct {
+	Name            string          `json:"name"`
+	SourceFolder    string          `json:"source_folder"`    // 相对工作区的收件箱文件夹
+	Request         OrganizeRequest `json:"request"`          // 整理方式，整理范围固定为收件箱文件夹
+	IntervalMinutes int             `json:"interval_minutes"` // 定时整理的间隔（分钟），0 表示不定时
+	OnNewFiles      bool            `json:"on_new_files"`     // 收件箱出现新文件（写入完成）后整理
+	DryRun          bool            `json:"dry_run"`          // 只生成预览，不移动文件
+	Enabled         bool            `json:"enabled"`
+}
+
+// OrganizeRule 自动整理规则
+type OrganizeRule struct {
+	ID              int64           `json:"id"`
+	WorkspaceID     int64           `json:"workspace_id"`
+	Name            string          `json:"name"`
+	SourceFolder    string          `json:"source_folder"`
+	Request         OrganizeRequest `json:"request"`
+	IntervalMinutes int             `json:"interval_minutes"`
+	OnNewFiles      bool            `json:"on_new_files"`
+	DryRun          bool            `json:"dry_run"`
+	Enabled         bool            `json:"enabled"`
+	LastRunAt       string          `json:"last_run_at,omitempty"`
+	LastStatus      string          `json:"last_status,omitempty"`
+	LastMessage     string          `json:"last_message,omitempty"`
+	CreatedAt       string          `json:"created_at"`
+	UpdatedAt       string          `json:"updated_at"`
+}
+
+// OrganizeRuleRun 自动整理规则的一次运行（organize-rule-run 事件的数据）
+type OrganizeRuleRun struct {
+	ID            int64  `json:"id"`
+	RuleID        int64  `json:"rule_id"`
+	Trigger       string `json:"trigger"` // schedule/new_files/manual
+	DryRun        bool   `json:"dry_run"`
+	Status        string `json:"status"`                 // applied/dry_run/no_changes/blocked/failed
+	OperationID   int64  `json:"operation_id,omitempty"` // 已执行时对应的整理操作，可在操作日志中撤销
+	MoveCount     int    `json:"move_count"`
+	ConflictCount int    `json:"conflict_count"`
+	SkipCount     int    `json:"skip_count"`
+	Message       string `json:"message,omitempty"`
+	HasPreview    bool   `json:"has_preview"` // 只预览或因冲突未执行时保存了预览
+	StartedAt     string `json:"started_at"`
+	FinishedAt    string `json:"finished_at"`
+}
+
+// FileMoveProgress 跨设备移动大文件时的复制进度（file-move-progress 事件）
+type FileMoveProgress struct {
+	Path   string `json:"path"` // 工作区内的相对路径
+	Copied int64  `json:"copied"`
+	Total  int64  `json:"total"`
+	Done   bool   `json:"done"`
+}
+
+// InterruptedOrganize 异常退出后未完成的整理，计数按磁盘实际状态统计
+type InterruptedOrganize struct {
+	OperationID   int64  `json:"operation_id"`
+	WorkspaceID   int64  `json:"workspace_id"`
+	WorkspacePath string `json:"workspace_path"`
+	Summary       string `json:"summary"`
+	CreatedAt     string `json:"created_at"`
+	Total         int    `json:"total"`
+	Moved         int    `json:"moved"`   // 已在目标位置
+	Pending       int    `json:"pending"` // 仍在原位置
+	Missing       int    `json:"missing"` // 两处都找不到
+}
+
+// TagReconcileItem 描述文件名标签与数据库标签不一致的文件
+type TagReconcileItem struct {
+	FileID     int64    `json:"file_id"`
+	Path       string   `json:"path"`
+	Name       string   `json:"name"`
+	DiskTags   []string `json:"disk_tags"`    // 文件名中解析出的标签
+	DBTags     []string `json:"db_tags"`      // file_tags 中记录的标签
+	OnlyOnDisk []string `json:"only_on_disk"` // 仅存在于文件名中的标签
+	OnlyInDB   []string `json:"only_in_db"`   // 仅存在于数据库中的标签
+}
+
+// TagReconcileReport 标签一致性分析结果
+type TagReconcileReport struct {
+	WorkspaceID  int64              `json:"workspace_id"`
+	ScannedCount int                `json:"scanned_count"`
+	Items        []TagReconcileItem `json:"items"`
+}
+
+// TagReconcileResolution 单个文件的修复策略
+type TagReconcileResolution struct {
+	FileID   int64  `json:"file_id"`
+	Strategy string `json:"strategy"` // disk/db/union，为空则使用请求的默认策略
+}
+
+// TagReconcileRequest 应用标签修复的请求
+type TagReconcileRequest struct {
+	WorkspaceID int64                    `json:"workspace_id"`
+	Strategy    string                   `json:"strategy"`    // 批量默认策略 disk/db/union
+	Resolutions []TagReconcileResolution `json:"resolutions"` // 需要修复的文件，可逐个指定策略
+}
+
+// TagReconcileApplyItem 单个文件的修复结果
+type TagReconcileApplyItem struct {
+	FileID   int64  `json:"file_id"`
+	Strategy string `json:"strategy"`
+	Success  bool   `json:"success"`
 	Message  string `json:"message,omitempty"`
 }
+
+// TagReconcileResult 应用标签修复的汇总结果
+type TagReconcileResult struct {
+	Applied int                     `json:"applied"`
+	Failed  int                     `json:"failed"`
+	Items   []TagReconcileApplyItem `json:"items"`
+}
+
+// FileChangeRecord 操作日志中单个文件的可逆变化
+type FileChangeRecord struct {
+	FileID int64   `json:"file_id"`
+	Before []int64 `json:"before,omitempty"` // 操作前的标签 ID，仅标签类操作记录
+	After  []int64 `json:"after,omitempty"`  // 操作后的标签 ID
+	From   string  `json:"from,omitempty"`   // 重命名前的相对路径，未重命名时为空
+	To     string  `json:"to,omitempty"`     // 重命名后的相对路径
+	Copied bool    `json:"copied,omitempty"` // 跨设备，以复制后删除源文件的方式完成
+}
+
+// FileOperationPayload 文件标签变更与重命名存储在 operations.payload 中的内容
+type FileOperationPayload struct {
+	WorkspaceID int64              `json:"workspace_id"`
+	Action      string             `json:"action"` // add/remove/set/clear/reconcile/rename/apply_tags/format_rename
+	TagIDs      []int64            `json:"tag_ids,omitempty"`
+	Changes     []FileChangeRecord `json:"changes"`
+}
+
+// TagSnapshot 标签删除或合并前的快照
+type TagSnapshot struct {
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Color    string  `json:"color"`
+	ParentID *int64  `json:"parent_id,omitempty"`
+	ChildIDs []int64 `json:"child_ids,omitempty"`
+}
+
+// TagEditPayload 标签重命名、合并、删除存储在 operations.payload 中的内容
+type TagEditPayload struct {
+	WorkspaceID int64              `json:"workspace_id"` // 执行时的工作区，文件重命名只发生在该工作区
+	Action      string             `json:"action"`       // rename/merge/delete
+	Tag         TagSnapshot        `json:"tag"`
+	NewName     string             `json:"new_name,omitempty"`      // rename
+	TargetTagID int64              `json:"target_tag_id,omitempty"` // merge
+	Changes     []FileChangeRecord `json:"changes"`
+}
+
+// BatchTagResult 批量标签操作的结果，Items 为标签发生变化的文件的重命名结果
+type BatchTagResult struct {
+	OperationID  int64             `json:"operation_id"` // 无变化时为 0
+	Requested    int               `json:"requested"`
+	Changed      int               `json:"changed"`
+	Renamed      int               `json:"renamed"`
+	RenameFailed int               `json:"rename_failed"`
+	Items        []TagRenameResult `json:"items"`
+}
+
+// TagEditResult 标签重命名、合并、删除的结果
+type TagEditResult struct {
+	OperationID  int64             `json:"operation_id"`
+	Changed      int               `json:"changed"` // 标签发生变化的文件数
+	Renamed      int               `json:"renamed"`
+	RenameFailed int               `json:"rename_failed"`
+	Items        []TagRenameResult `json:"items"`
+}
+
+// OperationSummary 操作日志列表项
+type OperationSummary struct {
+	ID          int64  `json:"id"`
+	Type        string `json:"type"`   // organize/tag/rename/tag_edit
+	Action      string `json:"action"` // 具体动作，整理操作为 organize 或 flatten
+	Summary     string `json:"summary"`
+	Status      string `json:"status"` // applied/undone
+	WorkspaceID *int64 `json:"workspace_id,omitempty"`
+	CanUndo     bool   `json:"can_undo"`
+	CanRedo     bool   `json:"can_redo"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// OperationConflict 撤销或重做前置条件不满足的文件
+type OperationConflict struct {
+	FileID  int64  `json:"file_id"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// OperationReplayResult 撤销或重做的结果，存在冲突时不做任何修改
+type OperationReplayResult struct {
+	OperationID int64               `json:"operation_id"`
+	Status      string              `json:"status"` // 处理后的操作状态 applied/undone
+	Succeeded   int                 `json:"succeeded"`
+	Skipped     int                 `json:"skipped"` // 已处于目标状态的文件
+	Failed      int                 `json:"failed"`
+	Conflicts   []OperationConflict `json:"conflicts,omitempty"`
+	Message     string              `json:"message,omitempty"`
+	// 整理撤销/重做后删除的空文件夹
+	RemovedFolders []string `json:"removed_folders,omitempty"`
+}
+
+// TagRenameResult 描述按标签重命名单个文件的结果
+type TagRenameResult struct {
+	FileID       int64    `json:"file_id"`
+	OldName      string   `json:"old_name"`
+	NewName      string   `json:"new_name"`
+	Status       string   `json:"status"`                  // renamed/unchanged/failed
+	EmbeddedTags int      `json:"embedded_tags"`           // 写入文件名的标签数量
+	OverflowTags []string `json:"overflow_tags,omitempty"` // 因长度限制仅保存在数据库中的标签
+	Message      string   `json:"message,omitempty"`
+}
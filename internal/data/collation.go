@@ -0,0 +1,41 @@
+package data
+
+import (
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"modernc.org/sqlite"
+)
+
+// NaturalCollation 自然排序规则名：数字按数值比较（img2 < img10），中文按拼音排序，忽略大小写
+const NaturalCollation = "natural_zh"
+
+// nameCollator 非并发安全，统一通过互斥锁访问
+var (
+	nameCollatorMu sync.Mutex
+	nameCollator   = collate.New(language.Chinese, collate.Numeric, collate.IgnoreCase)
+	nameKeyBuffer  collate.Buffer
+)
+
+func init() {
+	// 注册到 modernc.org/sqlite 驱动，之后打开的连接均可使用 COLLATE natural_zh
+	sqlite.MustRegisterCollationUtf8(NaturalCollation, compareNatural)
+}
+
+// compareNatural 按自然排序规则比较两个字符串
+func compareNatural(left, right string) int {
+	nameCollatorMu.Lock()
+	defer nameCollatorMu.Unlock()
+	return nameCollator.CompareString(left, right)
+}
+
+// NameSortKey 生成与 natural_zh 排序一致的二进制排序键，写入 files.name_key 以便走索引排序
+func NameSortKey(name string) []byte {
+	nameCollatorMu.Lock()
+	defer nameCollatorMu.Unlock()
+	key := nameCollator.KeyFromString(&nameKeyBuffer, name)
+	result := append([]byte(nil), key...)
+	nameKeyBuffer.Reset()
+	return result
+}
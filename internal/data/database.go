@@ -129,6 +129,11 @@ func (d *Database) InitDB(ctx context.Context) error {
 			mod_time DATETIME,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			hash TEXT,
+			ext TEXT NOT NULL DEFAULT '',
+			name_key BLOB,
+			parent_path TEXT,
+			name_pinyin TEXT COLLATE NOCASE,
+			name_initials TEXT COLLATE NOCASE,
 			FOREIGN KEY(workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
 		);`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS idx_files_workspace_path ON files(workspace_id, path);`,
@@ -138,6 +143,8 @@ func (d *Database) InitDB(ctx context.Context) error {
 			name TEXT NOT NULL UNIQUE,
 			color TEXT,
 			parent_id INTEGER,
+			name_pinyin TEXT COLLATE NOCASE,
+			name_initials TEXT COLLATE NOCASE,
 			FOREIGN KEY(parent_id) REFERENCES tags(id) ON DELETE SET NULL
 		);`,
 		`CREATE TABLE IF NOT EXISTS file_tags (
@@ -147,14 +154,20 @@ func (d *Database) InitDB(ctx context.Context) error {
 			FOREIGN KEY(file_id) REFERENCES files(id) ON DELETE CASCADE,
 			FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
 		);`,
-		`CREATE TABLE IF NOT EXISTS operations (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			type TEXT NOT NULL CHECK(type IN ('organize','tag')),
-			payload TEXT NOT NULL,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		);`,
+		operationsTableSQL(),
+		// 执行中操作的逐个移动步骤（预写意图），操作结束后清除；不设外键，避免迁移重建 operations 时失效
+		`CREATE TABLE IF NOT EXISTS operation_moves (
+			operation_id INTEGER NOT NULL,
+			seq INTEGER NOT NULL,
+			file_id INTEGER NOT NULL,
+			from_path TEXT NOT NULL,
+			to_path TEXT NOT NULL,
+			state TEXT NOT NULL DEFAULT 'pending' CHECK(state IN ('pending', 'done', 'failed', 'rolled_back')),
+			message TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY(operation_id, seq)
+		) WITHOUT ROWID;`,
 		`CREATE INDEX IF NOT EXISTS idx_tags_parent ON tags(parent_id);`,
-		`CREATE INDEX IF NOT EXISTS idx_operations_type ON operations(type);`,
 		`CREATE TABLE IF NOT EXISTS settings (
 			key TEXT PRIMARY KEY,
 			value TEXT NOT NULL,
@@ -168,6 +181,97 @@ func (d *Database) InitDB(ctx context.Context) error {
 			opened_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_recent_items_opened_at ON recent_items(opened_at DESC);`,
+		`CREATE TABLE IF NOT EXISTS saved_searches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			workspace_id INTEGER,
+			name TEXT NOT NULL,
+			query TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY(workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_searches_scope_name ON saved_searches(IFNULL(workspace_id, 0), name);`,
+		// 整理视图：按整理计划在工作区外生成的链接目录树，request 为序列化后的整理请求
+		`CREATE TABLE IF NOT EXISTS organize_views (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			workspace_id INTEGER NOT NULL,
+			path TEXT NOT NULL UNIQUE,
+			link_type TEXT NOT NULL CHECK(link_type IN ('symlink', 'hardlink')),
+			request TEXT NOT NULL,
+			link_count INTEGER NOT NULL DEFAULT 0,
+			built_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY(workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		);`,
+		// 自动整理规则：按计划或出现新文件时整理工作区内的收件箱文件夹，request 为序列化后的整理请求
+		`CREATE TABLE IF NOT EXISTS organize_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			workspace_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			source_folder TEXT NOT NULL,
+			request TEXT NOT NULL,
+			interval_minutes INTEGER NOT NULL DEFAULT 0,
+			on_new_files INTEGER NOT NULL DEFAULT 0,
+			dry_run INTEGER NOT NULL DEFAULT 0,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			snapshot TEXT NOT NULL DEFAULT '',
+			last_run_at DATETIME,
+			last_status TEXT NOT NULL DEFAULT '',
+			last_message TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY(workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_organize_rules_workspace_name ON organize_rules(workspace_id, name);`,
+		// 自动整理规则的运行记录，preview 仅在只预览或因冲突未执行时保存
+		`CREATE TABLE IF NOT EXISTS organize_rule_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			rule_id INTEGER NOT NULL,
+			trigger TEXT NOT NULL CHECK(trigger IN ('schedule', 'new_files', 'manual')),
+			dry_run INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL CHECK(status IN ('applied', 'dry_run', 'no_changes', 'blocked', 'failed')),
+			operation_id INTEGER,
+			move_count INTEGER NOT NULL DEFAULT 0,
+			conflict_count INTEGER NOT NULL DEFAULT 0,
+			skip_count INTEGER NOT NULL DEFAULT 0,
+			message TEXT NOT NULL DEFAULT '',
+			preview TEXT NOT NULL DEFAULT '',
+			started_at DATETIME NOT NULL,
+			finished_at DATETIME NOT NULL,
+			FOREIGN KEY(rule_id) REFERENCES organize_rules(id) ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_organize_rule_runs_rule ON organize_rule_runs(rule_id, id);`,
+		// 标签使用统计与两两共现次数，由 file_tags 上的触发器增量维护，供标签推荐使用
+		`CREATE TABLE IF NOT EXISTS tag_stats (
+			tag_id INTEGER PRIMARY KEY,
+			file_count INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+		);`,
+		`CREATE TABLE IF NOT EXISTS tag_pairs (
+			tag_id INTEGER NOT NULL,
+			other_id INTEGER NOT NULL,
+			file_count INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY(tag_id, other_id),
+			FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+			FOREIGN KEY(other_id) REFERENCES tags(id) ON DELETE CASCADE
+		) WITHOUT ROWID;`,
+		`CREATE TRIGGER IF NOT EXISTS trg_file_tags_insert AFTER INSERT ON file_tags BEGIN
+			INSERT INTO tag_stats(tag_id, file_count) VALUES (NEW.tag_id, 1)
+				ON CONFLICT(tag_id) DO UPDATE SET file_count = file_count + 1;
+			INSERT INTO tag_pairs(tag_id, other_id, file_count)
+				SELECT NEW.tag_id, ft.tag_id, 1 FROM file_tags ft WHERE ft.file_id = NEW.file_id AND ft.tag_id != NEW.tag_id
+				UNION ALL
+				SELECT ft.tag_id, NEW.tag_id, 1 FROM file_tags ft WHERE ft.file_id = NEW.file_id AND ft.tag_id != NEW.tag_id
+				ON CONFLICT(tag_id, other_id) DO UPDATE SET file_count = file_count + 1;
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS trg_file_tags_delete AFTER DELETE ON file_tags BEGIN
+			UPDATE tag_stats SET file_count = file_count - 1 WHERE tag_id = OLD.tag_id;
+			UPDATE tag_pairs SET file_count = file_count - 1
+				WHERE (tag_id = OLD.tag_id AND other_id IN (SELECT tag_id FROM file_tags WHERE file_id = OLD.file_id))
+				   OR (other_id = OLD.tag_id AND tag_id IN (SELECT tag_id FROM file_tags WHERE file_id = OLD.file_id));
+			DELETE FROM tag_stats WHERE tag_id = OLD.tag_id AND file_count <= 0;
+			DELETE FROM tag_pairs WHERE (tag_id = OLD.tag_id OR other_id = OLD.tag_id) AND file_count <= 0;
+		END;`,
 	}
 
 	for _, stmt := range statements {
@@ -176,7 +280,7 @@ func (d *Database) InitDB(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	return d.migrate(ctx)
 }
 
 // Close 关闭数据库连接
@@ -264,12 +368,15 @@ func (d *Database) CreateTag(ctx context.Context, name, color string, parentID *
 		}
 	}()
 
+	namePinyin, nameInitials := PinyinKeys(name)
 	result, err := tx.ExecContext(
 		ctx,
-		`INSERT INTO tags(name, color, parent_id) VALUES(?, ?, ?)`,
+		`INSERT INTO tags(name, color, parent_id, name_pinyin, name_initials) VALUES(?, ?, ?, ?, ?)`,
 		name,
 		color,
 		parent,
+		namePinyin,
+		nameInitials,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("创建标签失败: %w", err)
@@ -409,22 +516,24 @@ func (d *Database) ClearAllTagsFromFile(ctx context.Context, fileID int64) error
 	return nil
 }
 
-// ListFiles 根据工作区分页查询文件
+// ListFiles 根据工作区分页查询文件（按 ID 顺序，适合批量遍历）
 func (d *Database) ListFiles(ctx context.Context, workspaceID int64, limit, offset int) (*FilePage, error) {
+	return d.ListFilesSorted(ctx, workspaceID, FileSort{}, limit, offset)
+}
+
+// ListFilesSorted 根据工作区分页查询文件，支持指定排序
+func (d *Database) ListFilesSorted(ctx context.Context, workspaceID int64, sort FileSort, limit, offset int) (*FilePage, error) {
 	if d == nil || d.conn == nil {
 		return nil, errors.New("数据库对象尚未初始化")
 	}
 	if workspaceID <= 0 {
 		return nil, errors.New("缺少有效的工作区 ID")
 	}
-	if limit <= 0 {
-		limit = 200
-	}
-	if limit > 2000 {
-		limit = 2000
-	}
-	if offset < 0 {
-		offset = 0
+	limit, offset = normalizePaging(limit, offset)
+
+	orderBy, err := sort.orderBy(sortByID)
+	if err != nil {
+		return nil, err
 	}
 
 	var total int64
@@ -436,54 +545,15 @@ func (d *Database) ListFiles(ctx context.Context, workspaceID int64, limit, offs
 		return nil, fmt.Errorf("统计文件数量失败: %w", err)
 	}
 
-	rows, err := d.conn.QueryContext(
-		ctx,
-		`SELECT id, workspace_id, path, name, size, type, mod_time, created_at, hash
-		 FROM files
-		 WHERE workspace_id = ?
-		 ORDER BY id
-		 LIMIT ? OFFSET ?`,
-		workspaceID, limit, offset,
-	)
+	query := fmt.Sprintf(`
+		SELECT f.id, f.workspace_id, f.path, f.name, f.size, f.type, f.mod_time, f.created_at, f.hash
+		FROM files f
+		WHERE f.workspace_id = ?
+		ORDER BY %s
+		LIMIT ? OFFSET ?`, orderBy)
+	records, err := d.queryFileRecords(ctx, query, []any{workspaceID, limit, offset}, limit)
 	if err != nil {
-		return nil, fmt.Errorf("查询文件列表失败: %w", err)
-	}
-	defer rows.Close()
-
-	records := make([]FileRecord, 0, limit)
-	fileIDs := make([]int64, 0, limit)
-	for rows.Next() {
-		var record FileRecord
-		if err := rows.Scan(
-			&record.ID,
-			&record.WorkspaceID,
-			&record.Path,
-			&record.Name,
-			&record.Size,
-			&record.Type,
-			&record.ModTime,
-			&record.CreatedAt,
-			&record.Hash,
-		); err != nil {
-			return nil, fmt.Errorf("解析文件记录失败: %w", err)
-		}
-		records = append(records, record)
-		fileIDs = append(fileIDs, record.ID)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("遍历文件记录失败: %w", err)
-	}
-
-	if len(fileIDs) > 0 {
-		tagMap, err := d.getTagsForFiles(ctx, fileIDs)
-		if err != nil {
-			return nil, err
-		}
-		for i := range records {
-			if tags, ok := tagMap[records[i].ID]; ok {
-				records[i].Tags = tags
-			}
-		}
+		return nil, err
 	}
 
 	return &FilePage{
@@ -549,8 +619,9 @@ func (d *Database) NewFileImportSession(ctx context.Context, workspaceID int64)
 
 	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO files(
-			workspace_id, path, name, size, type, mod_time, created_at, hash
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+			workspace_id, path, name, size, type, mod_time, created_at, hash, ext, name_key, parent_path,
+			name_pinyin, name_initials
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 	`)
 	if err != nil {
 		_ = tx.Rollback()
@@ -572,6 +643,7 @@ func (s *FileImportSession) Insert(batch []FileMetadata) error {
 	}
 
 	for _, item := range batch {
+		namePinyin, nameInitials := filePinyinKeys(item.Name, item.Type)
 		if _, err := s.stmt.ExecContext(
 			s.ctx,
 			item.WorkspaceID,
@@ -582,6 +654,11 @@ func (s *FileImportSession) Insert(batch []FileMetadata) error {
 			item.ModTime,
 			item.CreatedAt,
 			item.Hash,
+			FileExt(item.Name, item.Type),
+			NameSortKey(item.Name),
+			ParentPath(item.Path),
+			namePinyin,
+			nameInitials,
 		); err != nil {
 			return fmt.Errorf("写入文件记录失败: %w", err)
 		}
@@ -630,6 +707,58 @@ func (s *FileImportSession) Close() error {
 	return nil
 }
 
+// InsertFileRecords 写入尚未记录的文件（按路径去重，已有记录保持不变），返回与 items 对应的新记录 ID，已存在的为 0
+func (d *Database) InsertFileRecords(ctx context.Context, items []FileMetadata) ([]int64, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO files(
+			workspace_id, path, name, size, type, mod_time, created_at, hash, ext, name_key, parent_path,
+			name_pinyin, name_initials
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("准备插入语句失败: %w", err)
+	}
+	defer stmt.Close()
+
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		namePinyin, nameInitials := filePinyinKeys(item.Name, item.Type)
+		result, err := stmt.ExecContext(ctx,
+			item.WorkspaceID, item.Path, item.Name, item.Size, item.Type, item.ModTime, item.CreatedAt, item.Hash,
+			FileExt(item.Name, item.Type), NameSortKey(item.Name), ParentPath(item.Path),
+			namePinyin, nameInitials,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("写入文件记录失败: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+			if ids[i], err = result.LastInsertId(); err != nil {
+				return nil, fmt.Errorf("获取文件记录 ID 失败: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+	return ids, nil
+}
+
 // GetFileByID 根据ID获取文件信息
 func (d *Database) GetFileByID(ctx context.Context, fileID int64) (*FileRecord, error) {
 	if d == nil || d.conn == nil {
@@ -647,6 +776,7 @@ func (d *Database) GetFileByID(ctx context.Context, fileID int64) (*FileRecord,
 	)
 
 	var record FileRecord
+	var modTime sql.NullTime
 	if err := row.Scan(
 		&record.ID,
 		&record.WorkspaceID,
@@ -654,7 +784,7 @@ func (d *Database) GetFileByID(ctx context.Context, fileID int64) (*FileRecord,
 		&record.Name,
 		&record.Size,
 		&record.Type,
-		&record.ModTime,
+		&modTime,
 		&record.CreatedAt,
 		&record.Hash,
 	); err != nil {
@@ -663,6 +793,7 @@ func (d *Database) GetFileByID(ctx context.Context, fileID int64) (*FileRecord,
 		}
 		return nil, fmt.Errorf("查询文件失败: %w", err)
 	}
+	record.ModTime = modTime.Time // 没有修改时间的记录保持零值
 
 	// 获取文件的标签
 	tagMap, err := d.getTagsForFiles(ctx, []int64{fileID})
@@ -676,6 +807,29 @@ func (d *Database) GetFileByID(ctx context.Context, fileID int64) (*FileRecord,
 	return &record, nil
 }
 
+// GetFileIDByPath 按工作区内的相对路径查找文件 ID，未收录时返回 0
+func (d *Database) GetFileIDByPath(ctx context.Context, workspaceID int64, relPath string) (int64, error) {
+	if d == nil || d.conn == nil {
+		return 0, errors.New("数据库对象尚未初始化")
+	}
+
+	var id int64
+	err := d.conn.QueryRowContext(
+		ctx,
+		`SELECT id FROM files WHERE workspace_id = ? AND (path = ? OR path = ?) LIMIT 1`,
+		workspaceID,
+		relPath,
+		filepath.FromSlash(relPath),
+	).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("查询文件失败: %w", err)
+	}
+	return id, nil
+}
+
 // UpdateFileName 更新文件名和路径
 func (d *Database) UpdateFileName(ctx context.Context, fileID int64, newName, newPath string) error {
 	if d == nil || d.conn == nil {
@@ -691,10 +845,14 @@ func (d *Database) UpdateFileName(ctx context.Context, fileID int64, newName, ne
 		return errors.New("新路径不能为空")
 	}
 
+	// 重命名仅针对普通文件，拼音按去掉扩展名后的主文件名生成
+	namePinyin, nameInitials := filePinyinKeys(newName, FileTypeRegular)
 	result, err := d.conn.ExecContext(
 		ctx,
-		`UPDATE files SET name = ?, path = ? WHERE id = ?`,
-		newName, newPath, fileID,
+		`UPDATE files SET name = ?, path = ?, ext = CASE WHEN type = 'dir' THEN '' ELSE ? END, name_key = ?, parent_path = ?,
+			name_pinyin = ?, name_initials = ? WHERE id = ?`,
+		newName, newPath, FileExt(newName, FileTypeRegular), NameSortKey(newName), ParentPath(newPath),
+		namePinyin, nameInitials, fileID,
 	)
 	if err != nil {
 		return fmt.Errorf("更新文件名失败: %w", err)
@@ -812,144 +970,61 @@ func (d *Database) GetWorkspaceByID(ctx context.Context, workspaceID int64) (*Wo
 
 // ListFilesByTags 根据标签ID和文件夹路径查询文件
 func (d *Database) ListFilesByTags(ctx context.Context, workspaceID int64, tagIDs []int64, folderPath string, includeSubfolders bool, limit, offset int) (*FilePage, error) {
-	if d == nil || d.conn == nil {
-		return nil, errors.New("数据库对象尚未初始化")
-	}
-	if workspaceID <= 0 {
-		return nil, errors.New("缺少有效的工作区 ID")
-	}
 	if len(tagIDs) == 0 {
 		return nil, errors.New("至少需要一个标签ID")
 	}
-	if limit <= 0 {
-		limit = 200
-	}
-	if limit > 2000 {
-		limit = 2000
-	}
-	if offset < 0 {
-		offset = 0
-	}
-
-	// 构建标签ID占位符
-	tagPlaceholders := make([]string, len(tagIDs))
-	tagArgs := make([]any, len(tagIDs))
-	for i, id := range tagIDs {
-		tagPlaceholders[i] = "?"
-		tagArgs[i] = id
-	}
-	tagIDsStr := strings.Join(tagPlaceholders, ",")
-
-	// 构建路径条件
-	var pathCondition string
-	var pathArgs []any
-	if folderPath != "" {
-		// 规范化路径分隔符
-		normalizedPath := strings.ReplaceAll(folderPath, "\\", "/")
-		if includeSubfolders {
-			// 包含子文件夹：路径以 folderPath 开头
-			pathCondition = " AND (f.path = ? OR f.path LIKE ?)"
-			pathArgs = []any{normalizedPath, normalizedPath + "/%"}
-		} else {
-			// 不包含子文件夹：只匹配直接子项
-			pathCondition = " AND (f.path LIKE ? AND f.path NOT LIKE ?)"
-			pathArgs = []any{normalizedPath + "/%", normalizedPath + "/%/%"}
-		}
-	}
 
-	// 构建基础查询条件：文件必须拥有所有指定的标签
-	baseCondition := fmt.Sprintf(`
-		f.workspace_id = ? 
-		AND f.type = 'file'
-		AND f.id IN (
-			SELECT file_id FROM file_tags 
-			WHERE tag_id IN (%s) 
-			GROUP BY file_id 
-			HAVING COUNT(DISTINCT tag_id) = ?
-		)%s`, tagIDsStr, pathCondition)
-
-	// 构建参数列表
-	countArgs := []any{workspaceID}
-	countArgs = append(countArgs, tagArgs...)
-	countArgs = append(countArgs, len(tagIDs))
-	countArgs = append(countArgs, pathArgs...)
-
-	// 统计总数
-	countQuery := fmt.Sprintf(`SELECT COUNT(1) FROM files f WHERE %s`, baseCondition)
-	var total int64
-	if err := d.conn.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
-		return nil, fmt.Errorf("统计文件数量失败: %w", err)
-	}
-
-	// 查询文件列表
-	queryArgs := append(countArgs, limit, offset)
-	query := fmt.Sprintf(`
-		SELECT f.id, f.workspace_id, f.path, f.name, f.size, f.type, f.mod_time, f.created_at, f.hash
-		FROM files f
-		WHERE %s
-		ORDER BY f.path
-		LIMIT ? OFFSET ?`, baseCondition)
+	return d.SearchFiles(ctx, FileQuery{
+		WorkspaceID:       workspaceID,
+		TagIDs:            tagIDs,
+		FolderPath:        folderPath,
+		IncludeSubfolders: includeSubfolders,
+		Limit:             limit,
+		Offset:            offset,
+	})
+}
 
-	rows, err := d.conn.QueryContext(ctx, query, queryArgs...)
-	if err != nil {
-		return nil, fmt.Errorf("查询文件列表失败: %w", err)
+// BatchAddTagsToFile 批量为文件添加标签（根据标签名称）
+func (d *Database) BatchAddTagsToFile(ctx context.Context, fileID int64, tagNames []string) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
 	}
-	defer rows.Close()
-
-	records := make([]FileRecord, 0, limit)
-	fileIDs := make([]int64, 0, limit)
-	for rows.Next() {
-		var record FileRecord
-		if err := rows.Scan(
-			&record.ID,
-			&record.WorkspaceID,
-			&record.Path,
-			&record.Name,
-			&record.Size,
-			&record.Type,
-			&record.ModTime,
-			&record.CreatedAt,
-			&record.Hash,
-		); err != nil {
-			return nil, fmt.Errorf("解析文件记录失败: %w", err)
-		}
-		records = append(records, record)
-		fileIDs = append(fileIDs, record.ID)
+	if fileID <= 0 {
+		return errors.New("无效的文件 ID")
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("遍历文件记录失败: %w", err)
+	if len(tagNames) == 0 {
+		return nil
 	}
 
-	// 获取文件的标签
-	if len(fileIDs) > 0 {
-		tagMap, err := d.getTagsForFiles(ctx, fileIDs)
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
 		if err != nil {
-			return nil, err
-		}
-		for i := range records {
-			if tags, ok := tagMap[records[i].ID]; ok {
-				records[i].Tags = tags
-			}
+			_ = tx.Rollback()
 		}
+	}()
+
+	if err = addTagNamesToFileTx(ctx, tx, fileID, tagNames); err != nil {
+		return err
 	}
 
-	return &FilePage{
-		Total:   total,
-		Records: records,
-	}, nil
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	return nil
 }
 
-// BatchAddTagsToFile 批量为文件添加标签（根据标签名称）
-func (d *Database) BatchAddTagsToFile(ctx context.Context, fileID int64, tagNames []string) error {
+// ReplaceFileTagsByName 用给定的标签名称整体替换文件的标签（单事务）
+func (d *Database) ReplaceFileTagsByName(ctx context.Context, fileID int64, tagNames []string) error {
 	if d == nil || d.conn == nil {
 		return errors.New("数据库对象尚未初始化")
 	}
 	if fileID <= 0 {
 		return errors.New("无效的文件 ID")
 	}
-	if len(tagNames) == 0 {
-		return nil
-	}
 
 	tx, err := d.conn.BeginTx(ctx, nil)
 	if err != nil {
@@ -961,6 +1036,23 @@ func (d *Database) BatchAddTagsToFile(ctx context.Context, fileID int64, tagName
 		}
 	}()
 
+	if _, err = tx.ExecContext(ctx, `DELETE FROM file_tags WHERE file_id = ?`, fileID); err != nil {
+		return fmt.Errorf("清除文件标签失败: %w", err)
+	}
+
+	if err = addTagNamesToFileTx(ctx, tx, fileID, tagNames); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	return nil
+}
+
+// addTagNamesToFileTx 在事务内按名称获取或创建标签并关联到文件
+func addTagNamesToFileTx(ctx context.Context, tx *sql.Tx, fileID int64, tagNames []string) error {
 	for _, tagName := range tagNames {
 		tagName = strings.TrimSpace(tagName)
 		if tagName == "" {
@@ -970,10 +1062,14 @@ func (d *Database) BatchAddTagsToFile(ctx context.Context, fileID int64, tagName
 		// 获取或创建标签
 		var tagID int64
 		row := tx.QueryRowContext(ctx, `SELECT id FROM tags WHERE name = ? COLLATE NOCASE`, tagName)
-		err = row.Scan(&tagID)
+		err := row.Scan(&tagID)
 		if errors.Is(err, sql.ErrNoRows) {
 			// 标签不存在，创建新标签
-			result, createErr := tx.ExecContext(ctx, `INSERT INTO tags(name, color) VALUES(?, ?)`, tagName, "#94a3b8")
+			namePinyin, nameInitials := PinyinKeys(tagName)
+			result, createErr := tx.ExecContext(ctx,
+				`INSERT INTO tags(name, color, name_pinyin, name_initials) VALUES(?, ?, ?, ?)`,
+				tagName, "#94a3b8", namePinyin, nameInitials,
+			)
 			if createErr != nil {
 				return fmt.Errorf("创建标签失败: %w", createErr)
 			}
@@ -986,16 +1082,10 @@ func (d *Database) BatchAddTagsToFile(ctx context.Context, fileID int64, tagName
 		}
 
 		// 关联标签到文件
-		_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO file_tags(file_id, tag_id) VALUES(?, ?)`, fileID, tagID)
-		if err != nil {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO file_tags(file_id, tag_id) VALUES(?, ?)`, fileID, tagID); err != nil {
 			return fmt.Errorf("关联标签到文件失败: %w", err)
 		}
 	}
-
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("提交事务失败: %w", err)
-	}
-
 	return nil
 }
 
@@ -1126,70 +1216,3 @@ func (d *Database) RemoveRecentItem(ctx context.Context, path string) error {
 
 	return nil
 }
-
-// Operation 表示一条操作记录
-type Operation struct {
-	ID        int64     `json:"id"`
-	Type      string    `json:"type"`
-	Payload   string    `json:"payload"`
-	CreatedAt time.Time `json:"created_at"`
-}
-
-// InsertOperation 写入操作记录
-func (d *Database) InsertOperation(ctx context.Context, opType, payload string) (int64, error) {
-	if d == nil || d.conn == nil {
-		return 0, errors.New("数据库对象尚未初始化")
-	}
-	if opType == "" {
-		return 0, errors.New("操作类型不能为空")
-	}
-	if payload == "" {
-		return 0, errors.New("操作内容不能为空")
-	}
-
-	result, err := d.conn.ExecContext(ctx, `INSERT INTO operations(type, payload) VALUES(?, ?)`, opType, payload)
-	if err != nil {
-		return 0, fmt.Errorf("写入操作记录失败: %w", err)
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("获取操作记录 ID 失败: %w", err)
-	}
-	return id, nil
-}
-
-// GetOperation 读取操作记录
-func (d *Database) GetOperation(ctx context.Context, id int64) (*Operation, error) {
-	if d == nil || d.conn == nil {
-		return nil, errors.New("数据库对象尚未初始化")
-	}
-	if id <= 0 {
-		return nil, errors.New("无效的操作 ID")
-	}
-
-	row := d.conn.QueryRowContext(ctx, `SELECT id, type, payload, created_at FROM operations WHERE id = ?`, id)
-	var op Operation
-	if err := row.Scan(&op.ID, &op.Type, &op.Payload, &op.CreatedAt); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("操作记录不存在")
-		}
-		return nil, fmt.Errorf("查询操作记录失败: %w", err)
-	}
-	return &op, nil
-}
-
-// DeleteOperation 删除操作记录
-func (d *Database) DeleteOperation(ctx context.Context, id int64) error {
-	if d == nil || d.conn == nil {
-		return errors.New("数据库对象尚未初始化")
-	}
-	if id <= 0 {
-		return errors.New("无效的操作 ID")
-	}
-
-	if _, err := d.conn.ExecContext(ctx, `DELETE FROM operations WHERE id = ?`, id); err != nil {
-		return fmt.Errorf("删除操作记录失败: %w", err)
-	}
-	return nil
-}
@@ -0,0 +1,233 @@
+package data
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FileCursorPage 游标分页结果
+type FileCursorPage struct {
+	Records    []FileRecord
+	NextCursor string // 为空表示已到末尾
+	Total      *int64 // 仅在请求统计时返回
+}
+
+// errInvalidCursor 游标无法解析或与当前排序不一致
+var errInvalidCursor = errors.New("分页游标无效，请从第一页重新加载")
+
+// fileCursor 记录上一页最后一条记录的排序键与 ID，下一页从其之后继续
+type fileCursor struct {
+	By   string        `json:"by"`
+	Desc bool          `json:"desc,omitempty"`
+	Keys []cursorValue `json:"keys,omitempty"`
+	ID   int64         `json:"id"`
+}
+
+// cursorValue 保留排序键的原始类型，保证与数据库中的值按相同规则比较
+type cursorValue struct {
+	Kind string `json:"k"` // i 整数 / s 文本 / b 二进制 / n 空值
+	Int  int64  `json:"i,omitempty"`
+	Text string `json:"s,omitempty"`
+	Blob []byte `json:"b,omitempty"`
+}
+
+func newCursorValue(value any) (cursorValue, error) {
+	switch v := value.(type) {
+	case nil:
+		return cursorValue{Kind: "n"}, nil
+	case int64:
+		return cursorValue{Kind: "i", Int: v}, nil
+	case string:
+		return cursorValue{Kind: "s", Text: v}, nil
+	case []byte:
+		return cursorValue{Kind: "b", Blob: append([]byte(nil), v...)}, nil
+	default:
+		return cursorValue{}, fmt.Errorf("不支持的排序键类型 %T", value)
+	}
+}
+
+func (v cursorValue) value() any {
+	switch v.Kind {
+	case "i":
+		return v.Int
+	case "s":
+		return v.Text
+	case "b":
+		if v.Blob == nil {
+			return []byte{}
+		}
+		return v.Blob
+	default:
+		return nil
+	}
+}
+
+func encodeFileCursor(c fileCursor) (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("生成分页游标失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeFileCursor(s string) (fileCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return fileCursor{}, errInvalidCursor
+	}
+	var c fileCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID <= 0 {
+		return fileCursor{}, errInvalidCursor
+	}
+	return c, nil
+}
+
+// ListFilesByCursor 按游标分页查询工作区全部文件与目录，排序为空时按 ID
+func (d *Database) ListFilesByCursor(ctx context.Context, workspaceID int64, sort FileSort, cursor string, limit int, withTotal bool) (*FileCursorPage, error) {
+	if sort.By == "" {
+		sort.By = sortByID
+	}
+	return d.SearchFilesByCursor(ctx, FileQuery{
+		WorkspaceID: workspaceID,
+		FileType:    FileTypeAll,
+		Sort:        sort,
+		Limit:       limit,
+	}, cursor, withTotal)
+}
+
+// SearchFilesByCursor 按组合条件游标分页查询文件（忽略 Offset）
+//
+// 下一页通过 (排序键, id) 行值比较定位，深分页耗时与页码无关；
+// 总数统计需要扫描全部匹配记录，仅在 withTotal 为 true 时执行。
+func (d *Database) SearchFilesByCursor(ctx context.Context, q FileQuery, cursor string, withTotal bool) (*FileCursorPage, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if q.WorkspaceID <= 0 {
+		return nil, errors.New("缺少有效的工作区 ID")
+	}
+	limit, _ := normalizePaging(q.Limit, 0)
+
+	sortBy := q.sortBy()
+	columns, err := q.sortColumns()
+	if err != nil {
+		return nil, err
+	}
+	orderBy, orderArgs := orderByClause(columns, q.Sort.Desc)
+
+	conds, err := d.buildFileConditions(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &FileCursorPage{}
+	if withTotal {
+		countQuery := fmt.Sprintf(`SELECT COUNT(1) FROM files f WHERE %s`, conds.where())
+		var total int64
+		if err := d.conn.QueryRowContext(ctx, countQuery, conds.args...).Scan(&total); err != nil {
+			return nil, fmt.Errorf("统计文件数量失败: %w", err)
+		}
+		page.Total = &total
+	}
+
+	exprs := make([]string, 0, len(columns)+1)
+	var exprArgs []any
+	for _, column := range columns {
+		exprs = append(exprs, column.expr)
+		exprArgs = append(exprArgs, column.args...)
+	}
+	exprs = append(exprs, "f.id")
+
+	if cursor != "" {
+		c, err := decodeFileCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.By != sortBy || c.Desc != q.Sort.Desc || len(c.Keys) != len(columns) {
+			return nil, errInvalidCursor
+		}
+
+		op := ">"
+		if q.Sort.Desc {
+			op = "<"
+		}
+		placeholders := make([]string, 0, len(exprs))
+		args := append([]any{}, exprArgs...)
+		for _, key := range c.Keys {
+			placeholders = append(placeholders, "?")
+			args = append(args, key.value())
+		}
+		placeholders = append(placeholders, "?")
+		args = append(args, c.ID)
+		conds.add(fmt.Sprintf("(%s) %s (%s)", strings.Join(exprs, ", "), op, strings.Join(placeholders, ", ")), args...)
+	}
+
+	// 多取一条用于判断是否还有下一页
+	query := fmt.Sprintf(`
+		SELECT f.id, f.workspace_id, f.path, f.name, f.size, f.type, f.mod_time, f.created_at, f.hash
+		FROM files f
+		WHERE %s
+		ORDER BY %s
+		LIMIT ?`, conds.where(), orderBy)
+	args := append(append(append([]any{}, conds.args...), orderArgs...), limit+1)
+	records, err := d.queryFileRecords(ctx, query, args, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > limit {
+		records = records[:limit]
+		last := records[len(records)-1]
+		next := fileCursor{By: sortBy, Desc: q.Sort.Desc, ID: last.ID}
+		if next.Keys, err = d.fileSortKeys(ctx, columns, last.ID); err != nil {
+			return nil, err
+		}
+		if page.NextCursor, err = encodeFileCursor(next); err != nil {
+			return nil, err
+		}
+	}
+	page.Records = records
+	return page, nil
+}
+
+// fileSortKeys 读取指定文件在数据库中的原始排序键
+func (d *Database) fileSortKeys(ctx context.Context, columns []sortColumn, fileID int64) ([]cursorValue, error) {
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(columns))
+	var args []any
+	for _, column := range columns {
+		key := column.key
+		if key == "" {
+			key = column.expr
+		}
+		keys = append(keys, key)
+		args = append(args, column.args...)
+	}
+
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	query := fmt.Sprintf(`SELECT %s FROM files f WHERE f.id = ?`, strings.Join(keys, ", "))
+	if err := d.conn.QueryRowContext(ctx, query, append(args, fileID)...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("读取排序键失败: %w", err)
+	}
+
+	result := make([]cursorValue, 0, len(values))
+	for _, value := range values {
+		v, err := newCursorValue(value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
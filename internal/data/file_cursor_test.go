@@ -0,0 +1,238 @@
+package data
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileCursorRoundTrip(t *testing.T) {
+	tests := []fileCursor{
+		{By: sortByID, ID: 1},
+		{By: SortByName, Keys: []cursorValue{{Kind: "s", Text: "报告 10.pdf"}}, ID: 42},
+		{By: SortBySize, Desc: true, Keys: []cursorValue{{Kind: "i", Int: -7}}, ID: 9},
+		{By: SortByModTime, Keys: []cursorValue{{Kind: "n"}}, ID: 3},
+		{By: SortByExtension, Keys: []cursorValue{{Kind: "s", Text: ".txt"}, {Kind: "b", Blob: []byte{0, 0xff, 'a'}}}, ID: 1 << 40},
+	}
+	for _, want := range tests {
+		t.Run(want.By, func(t *testing.T) {
+			encoded, err := encodeFileCursor(want)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			got, err := decodeFileCursor(encoded)
+			if err != nil {
+				t.Fatalf("decode(%q): %v", encoded, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCursorValue(t *testing.T) {
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{nil, nil},
+		{int64(5), int64(5)},
+		{"a", "a"},
+		{[]byte("b"), []byte("b")},
+		{[]byte{}, []byte{}},
+	}
+	for _, tt := range tests {
+		v, err := newCursorValue(tt.in)
+		if err != nil {
+			t.Fatalf("newCursorValue(%#v): %v", tt.in, err)
+		}
+		encoded, err := encodeFileCursor(fileCursor{By: SortByName, Keys: []cursorValue{v}, ID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := decodeFileCursor(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := decoded.Keys[0].value(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("value after round trip = %#v, want %#v", got, tt.want)
+		}
+	}
+	if _, err := newCursorValue(1.5); err == nil {
+		t.Error("newCursorValue(float64) should fail")
+	}
+}
+
+func TestDecodeFileCursorRejectsMalformed(t *testing.T) {
+	valid, err := encodeFileCursor(fileCursor{By: SortByName, Keys: []cursorValue{{Kind: "s", Text: "a"}}, ID: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.RawURLEncoding.DecodeString(valid)
+	truncated := base64.RawURLEncoding.EncodeToString(raw[:len(raw)-1])
+	flipped := append([]byte(nil), raw...)
+	flipped[0] ^= 0xff
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", valid + "="},
+		{"truncated json", truncated},
+		{"corrupted json", base64.RawURLEncoding.EncodeToString(flipped)},
+		{"not an object", base64.RawURLEncoding.EncodeToString([]byte(`[1,2]`))},
+		{"missing id", base64.RawURLEncoding.EncodeToString([]byte(`{"by":"name"}`))},
+		{"zero id", base64.RawURLEncoding.EncodeToString([]byte(`{"by":"name","id":0}`))},
+		{"negative id", base64.RawURLEncoding.EncodeToString([]byte(`{"by":"name","id":-3}`))},
+		{"wrong id type", base64.RawURLEncoding.EncodeToString([]byte(`{"by":"name","id":"5"}`))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeFileCursor(tt.cursor); !errors.Is(err, errInvalidCursor) {
+				t.Errorf("decode(%q) error = %v, want errInvalidCursor", tt.cursor, err)
+			}
+		})
+	}
+}
+
+// openCursorTestDB 创建临时数据库并写入 count 个文件
+func openCursorTestDB(t *testing.T, count int) (*Database, int64) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.InitDB(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ws, err := db.UpsertWorkspace(ctx, t.TempDir(), "ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := make([]FileMetadata, 0, count)
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("file%d.txt", i)
+		items = append(items, FileMetadata{
+			WorkspaceID: ws.ID,
+			Path:        name,
+			Name:        name,
+			Size:        int64(i % 3), // 制造重复的排序键，验证 id 兜底
+			Type:        FileTypeRegular,
+			ModTime:     base.Add(time.Duration(i%4) * time.Hour),
+			CreatedAt:   base,
+		})
+	}
+	session, err := db.NewFileImportSession(ctx, ws.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if err := session.Insert(items); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return db, ws.ID
+}
+
+func TestSearchFilesByCursorPaging(t *testing.T) {
+	const count = 23
+	db, wsID := openCursorTestDB(t, count)
+	ctx := context.Background()
+	// 部分记录没有修改时间，游标需要能越过这些空值
+	if _, err := db.conn.ExecContext(ctx, `UPDATE files SET mod_time = NULL WHERE id % 5 = 0`); err != nil {
+		t.Fatal(err)
+	}
+
+	sorts := []FileSort{
+		{By: SortByName},
+		{By: SortBySize, Desc: true},
+		{By: SortByModTime},
+		{By: SortByModTime, Desc: true},
+		{By: SortByExtension, Desc: true},
+	}
+	for _, sort := range sorts {
+		t.Run(fmt.Sprintf("%s desc=%v", sort.By, sort.Desc), func(t *testing.T) {
+			want, err := db.ListFilesSorted(ctx, wsID, sort, count, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []int64
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > count {
+					t.Fatal("cursor paging did not terminate")
+				}
+				page, err := db.ListFilesByCursor(ctx, wsID, sort, cursor, 5, false)
+				if err != nil {
+					t.Fatalf("page %d: %v", pages, err)
+				}
+				for _, record := range page.Records {
+					got = append(got, record.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				cursor = page.NextCursor
+			}
+
+			if len(got) != len(want.Records) {
+				t.Fatalf("got %d records, want %d", len(got), len(want.Records))
+			}
+			for i, record := range want.Records {
+				if got[i] != record.ID {
+					t.Fatalf("record %d = %d, want %d (offset order)", i, got[i], record.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchFilesByCursorRejectsMismatch(t *testing.T) {
+	db, wsID := openCursorTestDB(t, 4)
+	ctx := context.Background()
+
+	page, err := db.ListFilesByCursor(ctx, wsID, FileSort{By: SortByName}, "", 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.NextCursor == "" {
+		t.Fatal("expected a next cursor")
+	}
+	forged, err := encodeFileCursor(fileCursor{By: SortByName, ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		sort   FileSort
+		cursor string
+	}{
+		{"different field", FileSort{By: SortBySize}, page.NextCursor},
+		{"different direction", FileSort{By: SortByName, Desc: true}, page.NextCursor},
+		{"missing keys", FileSort{By: SortByName}, forged},
+		{"garbage", FileSort{By: SortByName}, "garbage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := db.ListFilesByCursor(ctx, wsID, tt.sort, tt.cursor, 2, false)
+			if !errors.Is(err, errInvalidCursor) {
+				t.Errorf("error = %v, want errInvalidCursor", err)
+			}
+		})
+	}
+}
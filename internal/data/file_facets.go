@@ -0,0 +1,87 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// TagFacet 当前结果集中拥有某标签的文件数
+type TagFacet struct {
+	TagID int64
+	Count int64
+}
+
+// FacetCount 按取值统计的文件数（类型、扩展名）
+type FacetCount struct {
+	Value string
+	Count int64
+}
+
+// FileFacets 当前结果集的分面统计
+type FileFacets struct {
+	Total      int64
+	Tags       []TagFacet
+	Types      []FacetCount
+	Extensions []FacetCount
+}
+
+// SearchFacets 统计匹配条件的文件在各标签、类型、扩展名上的分布（忽略排序与分页）
+//
+// 结果集只计算一次（MATERIALIZED），三类统计通过 UNION ALL 在同一条聚合查询中完成。
+func (d *Database) SearchFacets(ctx context.Context, q FileQuery) (*FileFacets, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if q.WorkspaceID <= 0 {
+		return nil, errors.New("缺少有效的工作区 ID")
+	}
+
+	conds, err := d.buildFileConditions(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		WITH matched AS MATERIALIZED (
+			SELECT f.id, f.type, f.ext FROM files f WHERE %s
+		)
+		SELECT 'tag', ft.tag_id, NULL, COUNT(1) FROM matched m JOIN file_tags ft ON ft.file_id = m.id GROUP BY ft.tag_id
+		UNION ALL
+		SELECT 'type', NULL, m.type, COUNT(1) FROM matched m GROUP BY m.type
+		UNION ALL
+		SELECT 'ext', NULL, m.ext, COUNT(1) FROM matched m GROUP BY m.ext
+		ORDER BY 4 DESC, 2, 3`, conds.where())
+
+	rows, err := d.conn.QueryContext(ctx, query, conds.args...)
+	if err != nil {
+		return nil, fmt.Errorf("统计分面失败: %w", err)
+	}
+	defer rows.Close()
+
+	facets := &FileFacets{}
+	for rows.Next() {
+		var kind string
+		var tagID sql.NullInt64
+		var value sql.NullString
+		var count int64
+		if err := rows.Scan(&kind, &tagID, &value, &count); err != nil {
+			return nil, fmt.Errorf("读取分面统计失败: %w", err)
+		}
+		switch kind {
+		case "tag":
+			facets.Tags = append(facets.Tags, TagFacet{TagID: tagID.Int64, Count: count})
+		case "type":
+			facets.Types = append(facets.Types, FacetCount{Value: value.String, Count: count})
+			facets.Total += count
+		case "ext":
+			facets.Extensions = append(facets.Extensions, FacetCount{Value: value.String, Count: count})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历分面统计失败: %w", err)
+	}
+
+	return facets, nil
+}
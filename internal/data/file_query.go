@@ -0,0 +1,626 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tagexplorer/internal/tagquery"
+)
+
+// FileQuery 描述一次文件检索，各条件之间为 AND 关系
+type FileQuery struct {
+	WorkspaceID       int64
+	TagIDs            []int64 // 必须同时拥有的标签
+	TagQuery          string  // 布尔标签表达式，如 (客户A OR 客户B) AND 2024 AND NOT 草稿
+	FolderPath        string  // 相对路径，为空表示整个工作区
+	IncludeSubfolders bool
+	NameQuery         string    // 文件名子串；包含 * 或 ? 时按通配符整体匹配
+	Extensions        []string  // 扩展名集合（不区分大小写，可带点）
+	MinSize           *int64    // 最小大小（字节，含）
+	MaxSize           *int64    // 最大大小（字节，含）
+	ModifiedAfter     time.Time // 修改时间下限（含），零值表示不限
+	ModifiedBefore    time.Time // 修改时间上限（不含），零值表示不限
+	FileType          string    // file/dir/all，为空时只查文件
+	Untagged          bool      // 仅查询没有任何标签的文件
+	MinTagCount       *int      // 标签数量下限（含）
+	MaxTagCount       *int      // 标签数量上限（含）
+	MissingTagGroupID int64     // 没有该分组（父标签及其全部子标签）中的任何标签
+	Sort              FileSort  // 为空时按路径排序；有文件名关键字时按相关度排序
+	Limit             int
+	Offset            int
+}
+
+// FileTypeAll 检索时同时包含文件与目录
+const FileTypeAll = "all"
+
+// 文件排序字段
+const (
+	SortByPath      = "path"
+	SortByName      = "name" // 自然排序（img2 < img10），中文按拼音
+	SortBySize      = "size"
+	SortByModTime   = "mod_time"
+	SortByCreatedAt = "created_at"
+	SortByTagCount  = "tag_count"
+	SortByExtension = "extension"
+	SortByRelevance = "relevance" // 文件名匹配程度：完全匹配 > 前缀 > 拼音首字母 > 包含，需配合 NameQuery
+)
+
+// FileSort 描述文件列表排序
+type FileSort struct {
+	By   string
+	Desc bool
+}
+
+// sortByID 按插入顺序排序，仅供内部批量遍历使用
+const sortByID = "id"
+
+// modTimeSortExpr 修改时间排序键，空值视为最早（与 idx_files_workspace_modtime_sort 一致）
+const modTimeSortExpr = `COALESCE(f.mod_time, '')`
+
+// tagCountExpr 单个文件的标签数量（走 file_tags 主键）
+const tagCountExpr = `(SELECT COUNT(1) FROM file_tags ft WHERE ft.file_id = f.id)`
+
+// sortColumn 排序列；key 为读取游标值时使用的表达式（时间列需取原始文本），args 为 expr 中占位符的参数
+type sortColumn struct {
+	expr string
+	key  string
+	args []any
+}
+
+// columns 返回排序列（不含兜底的 f.id），By 为空时使用 defaultBy
+func (s FileSort) columns(defaultBy string) ([]sortColumn, error) {
+	by := s.By
+	if by == "" {
+		by = defaultBy
+	}
+
+	switch by {
+	case sortByID:
+		return nil, nil
+	case SortByPath:
+		return []sortColumn{{expr: "f.path"}}, nil
+	case SortByName:
+		return []sortColumn{{expr: "f.name_key"}}, nil
+	case SortBySize:
+		return []sortColumn{{expr: "f.size"}}, nil
+	case SortByModTime:
+		// 修改时间可能为空，空值参与行值比较的结果为 NULL，会使游标无法越过这些记录
+		return []sortColumn{{expr: modTimeSortExpr, key: "CAST(" + modTimeSortExpr + " AS TEXT)"}}, nil
+	case SortByCreatedAt:
+		return []sortColumn{{expr: "f.created_at", key: "CAST(f.created_at AS TEXT)"}}, nil
+	case SortByTagCount:
+		return []sortColumn{{expr: tagCountExpr}}, nil
+	case SortByExtension:
+		return []sortColumn{{expr: "f.ext"}, {expr: "f.name_key"}}, nil
+	case SortByRelevance:
+		return nil, errors.New("按相关度排序需要提供文件名关键字")
+	default:
+		return nil, fmt.Errorf("无效的排序字段: %s", s.By)
+	}
+}
+
+// direction 返回排序方向关键字
+func (s FileSort) direction() string {
+	if s.Desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// orderBy 生成 ORDER BY 子句，始终以 f.id 兜底保证顺序稳定
+func (s FileSort) orderBy(defaultBy string) (string, error) {
+	columns, err := s.columns(defaultBy)
+	if err != nil {
+		return "", err
+	}
+
+	dir := s.direction()
+	parts := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		parts = append(parts, column.expr+" "+dir)
+	}
+	parts = append(parts, "f.id "+dir)
+	return strings.Join(parts, ", "), nil
+}
+
+// sortBy 返回实际生效的排序字段
+func (q FileQuery) sortBy() string {
+	switch {
+	case q.Sort.By != "":
+		return q.Sort.By
+	case strings.TrimSpace(q.NameQuery) != "":
+		return SortByRelevance
+	default:
+		return SortByPath
+	}
+}
+
+// sortColumns 返回检索使用的排序列，相关度排序依赖文件名关键字
+func (q FileQuery) sortColumns() ([]sortColumn, error) {
+	by := q.sortBy()
+	if by != SortByRelevance {
+		return q.Sort.columns(by)
+	}
+	name := strings.TrimSpace(q.NameQuery)
+	if name == "" {
+		return q.Sort.columns(by)
+	}
+	if strings.ContainsAny(name, "*?") {
+		// 通配符查询没有前缀/完全匹配之分，按名称排序
+		return []sortColumn{{expr: "f.name_key"}}, nil
+	}
+	rank, args := nameRankExpr(name)
+	return []sortColumn{{expr: rank, args: args}, {expr: "f.name_key"}}, nil
+}
+
+// orderByClause 生成 ORDER BY 子句及其参数，始终以 f.id 兜底
+func orderByClause(columns []sortColumn, desc bool) (string, []any) {
+	dir := FileSort{Desc: desc}.direction()
+	parts := make([]string, 0, len(columns)+1)
+	var args []any
+	for _, column := range columns {
+		parts = append(parts, column.expr+" "+dir)
+		args = append(args, column.args...)
+	}
+	parts = append(parts, "f.id "+dir)
+	return strings.Join(parts, ", "), args
+}
+
+// nameRankExpr 生成文件名相关度表达式（值越小越相关）：
+// 0 完全匹配（含仅差扩展名），1 名称或全拼前缀，2 拼音首字母前缀，3 其他（包含）
+func nameRankExpr(name string) (string, []any) {
+	escaped := escapeLike(name)
+	py, ok := normalizePinyinQuery(name)
+	if !ok {
+		return `CASE
+			WHEN f.name = ? COLLATE NOCASE OR f.name LIKE ? ESCAPE '\' THEN 0
+			WHEN f.name LIKE ? ESCAPE '\' THEN 1
+			ELSE 3 END`, []any{name, escaped + ".%", escaped + "%"}
+	}
+	return `CASE
+			WHEN f.name = ? COLLATE NOCASE OR f.name LIKE ? ESCAPE '\' OR f.name_pinyin = ? OR f.name_initials = ? THEN 0
+			WHEN f.name LIKE ? ESCAPE '\' OR f.name_pinyin LIKE ? THEN 1
+			WHEN f.name_initials LIKE ? THEN 2
+			ELSE 3 END`, []any{name, escaped + ".%", py, py, escaped + "%", py + "%", py + "%"}
+}
+
+// fileConditions 收集 WHERE 子句及其参数
+type fileConditions struct {
+	clauses []string
+	args    []any
+}
+
+func (c *fileConditions) add(clause string, args ...any) {
+	c.clauses = append(c.clauses, clause)
+	c.args = append(c.args, args...)
+}
+
+func (c *fileConditions) where() string {
+	return strings.Join(c.clauses, "\n\t\tAND ")
+}
+
+// SearchFiles 按组合条件分页查询文件
+func (d *Database) SearchFiles(ctx context.Context, q FileQuery) (*FilePage, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if q.WorkspaceID <= 0 {
+		return nil, errors.New("缺少有效的工作区 ID")
+	}
+	limit, offset := normalizePaging(q.Limit, q.Offset)
+
+	conds, err := d.buildFileConditions(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := q.sortColumns()
+	if err != nil {
+		return nil, err
+	}
+	orderBy, orderArgs := orderByClause(columns, q.Sort.Desc)
+
+	// 统计总数
+	countQuery := fmt.Sprintf(`SELECT COUNT(1) FROM files f WHERE %s`, conds.where())
+	var total int64
+	if err := d.conn.QueryRowContext(ctx, countQuery, conds.args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("统计文件数量失败: %w", err)
+	}
+
+	// 查询文件列表
+	query := fmt.Sprintf(`
+		SELECT f.id, f.workspace_id, f.path, f.name, f.size, f.type, f.mod_time, f.created_at, f.hash
+		FROM files f
+		WHERE %s
+		ORDER BY %s
+		LIMIT ? OFFSET ?`, conds.where(), orderBy)
+	args := append(append(append([]any{}, conds.args...), orderArgs...), limit, offset)
+
+	records, err := d.queryFileRecords(ctx, query, args, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePage{
+		Total:   total,
+		Records: records,
+	}, nil
+}
+
+// ValidateTagQuery 校验标签表达式语法并确认引用的标签均存在
+func (d *Database) ValidateTagQuery(ctx context.Context, src string) ([]Tag, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	node, err := tagquery.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	tagIDs, err := d.resolveQueryTags(ctx, src, node)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool, len(tagIDs))
+	var tags []Tag
+	for _, leaf := range tagquery.Tags(node) {
+		tag := tagIDs[strings.ToLower(leaf.Name)]
+		if seen[tag.ID] {
+			continue
+		}
+		seen[tag.ID] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// buildFileConditions 将检索参数转换为 WHERE 条件
+func (d *Database) buildFileConditions(ctx context.Context, q FileQuery) (*fileConditions, error) {
+	conds := &fileConditions{}
+	conds.add("f.workspace_id = ?", q.WorkspaceID)
+
+	switch q.FileType {
+	case "", FileTypeRegular:
+		conds.add("f.type = 'file'")
+	case FileTypeDirectory:
+		conds.add("f.type = 'dir'")
+	case FileTypeAll:
+	default:
+		return nil, fmt.Errorf("无效的文件类型: %s", q.FileType)
+	}
+
+	// 文件必须拥有所有指定的标签
+	if len(q.TagIDs) > 0 {
+		placeholders := make([]string, len(q.TagIDs))
+		args := make([]any, 0, len(q.TagIDs)+1)
+		for i, id := range q.TagIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		args = append(args, len(q.TagIDs))
+		conds.add(fmt.Sprintf(`f.id IN (
+			SELECT file_id FROM file_tags
+			WHERE tag_id IN (%s)
+			GROUP BY file_id
+			HAVING COUNT(DISTINCT tag_id) = ?
+		)`, strings.Join(placeholders, ",")), args...)
+	}
+
+	if strings.TrimSpace(q.TagQuery) != "" {
+		node, err := tagquery.Parse(q.TagQuery)
+		if err != nil {
+			return nil, err
+		}
+		tagIDs, err := d.resolveQueryTags(ctx, q.TagQuery, node)
+		if err != nil {
+			return nil, err
+		}
+		clause, args := compileTagQuery(node, tagIDs)
+		conds.add(clause, args...)
+	}
+
+	if err := d.addTagCountConditions(ctx, conds, q); err != nil {
+		return nil, err
+	}
+
+	if folder := normalizeFolderPath(q.FolderPath); folder != "" {
+		clause, args := folderScope(folder, q.IncludeSubfolders)
+		conds.add(clause, args...)
+	}
+
+	if name := strings.TrimSpace(q.NameQuery); name != "" {
+		// 纯字母数字的关键字同时匹配拼音全拼与首字母，如 bg / baogao 可找到「报告」
+		if py, ok := normalizePinyinQuery(name); ok && !strings.ContainsAny(name, "*?") {
+			conds.add(`(f.name LIKE ? ESCAPE '\' OR f.name_pinyin LIKE ? OR f.name_initials LIKE ?)`,
+				nameLikePattern(name), "%"+py+"%", "%"+py+"%")
+		} else {
+			conds.add(`f.name LIKE ? ESCAPE '\'`, nameLikePattern(name))
+		}
+	}
+
+	if len(q.Extensions) > 0 {
+		seen := make(map[string]bool, len(q.Extensions))
+		var placeholders []string
+		var args []any
+		for _, ext := range q.Extensions {
+			ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+			if ext == "" || seen[ext] {
+				continue
+			}
+			seen[ext] = true
+			placeholders = append(placeholders, "?")
+			args = append(args, ext)
+		}
+		if len(args) > 0 {
+			conds.add(fmt.Sprintf("f.ext IN (%s)", strings.Join(placeholders, ",")), args...)
+		}
+	}
+
+	if q.MinSize != nil && q.MaxSize != nil && *q.MinSize > *q.MaxSize {
+		return nil, errors.New("最小文件大小不能大于最大文件大小")
+	}
+	if q.MinSize != nil {
+		conds.add("f.size >= ?", *q.MinSize)
+	}
+	if q.MaxSize != nil {
+		conds.add("f.size <= ?", *q.MaxSize)
+	}
+
+	if !q.ModifiedAfter.IsZero() && !q.ModifiedBefore.IsZero() && !q.ModifiedAfter.Before(q.ModifiedBefore) {
+		return nil, errors.New("修改时间范围无效")
+	}
+	if !q.ModifiedAfter.IsZero() {
+		conds.add("f.mod_time >= ?", q.ModifiedAfter.UTC())
+	}
+	if !q.ModifiedBefore.IsZero() {
+		conds.add("f.mod_time < ?", q.ModifiedBefore.UTC())
+	}
+
+	return conds, nil
+}
+
+// addTagCountConditions 添加未打标签、标签数量与缺少分组标签的条件
+//
+// 均基于 file_tags 主键 (file_id, tag_id) 的 EXISTS / NOT EXISTS 反连接，只在需要精确数量时才计数。
+func (d *Database) addTagCountConditions(ctx context.Context, conds *fileConditions, q FileQuery) error {
+	if (q.MinTagCount != nil && *q.MinTagCount < 0) || (q.MaxTagCount != nil && *q.MaxTagCount < 0) {
+		return errors.New("标签数量不能为负数")
+	}
+	if q.MinTagCount != nil && q.MaxTagCount != nil && *q.MinTagCount > *q.MaxTagCount {
+		return errors.New("标签数量下限不能大于上限")
+	}
+
+	const hasTags = `EXISTS (SELECT 1 FROM file_tags ft WHERE ft.file_id = f.id)`
+	if q.Untagged || (q.MaxTagCount != nil && *q.MaxTagCount == 0) {
+		conds.add("NOT " + hasTags)
+	} else if q.MaxTagCount != nil {
+		conds.add(tagCountExpr+" <= ?", *q.MaxTagCount)
+	}
+	if q.MinTagCount != nil {
+		switch {
+		case *q.MinTagCount == 1:
+			conds.add(hasTags)
+		case *q.MinTagCount > 1:
+			conds.add(tagCountExpr+" >= ?", *q.MinTagCount)
+		}
+	}
+
+	if q.MissingTagGroupID > 0 {
+		groupIDs, err := d.tagGroupIDs(ctx, q.MissingTagGroupID)
+		if err != nil {
+			return err
+		}
+		placeholders := make([]string, len(groupIDs))
+		args := make([]any, len(groupIDs))
+		for i, id := range groupIDs {
+			placeholders[i] = "?"
+			args[i] = id
+		}
+		conds.add(fmt.Sprintf(
+			`NOT EXISTS (SELECT 1 FROM file_tags ft WHERE ft.file_id = f.id AND ft.tag_id IN (%s))`,
+			strings.Join(placeholders, ","),
+		), args...)
+	}
+	return nil
+}
+
+// tagGroupIDs 返回分组标签自身及其全部子孙标签的 ID
+func (d *Database) tagGroupIDs(ctx context.Context, groupID int64) ([]int64, error) {
+	rows, err := d.conn.QueryContext(ctx, `
+		WITH RECURSIVE tag_group(id) AS (
+			SELECT id FROM tags WHERE id = ?
+			UNION
+			SELECT t.id FROM tags t JOIN tag_group g ON t.parent_id = g.id
+		)
+		SELECT id FROM tag_group`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签分组失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("读取标签分组失败: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历标签分组失败: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("标签分组不存在")
+	}
+	return ids, nil
+}
+
+// normalizeFolderPath 统一文件夹路径的分隔符并去掉首尾的 /
+func normalizeFolderPath(folderPath string) string {
+	return strings.Trim(strings.ReplaceAll(folderPath, "\\", "/"), "/")
+}
+
+// folderScope 生成文件夹范围条件（folder 需已规范化且非空）
+//
+// 直接子项按 parent_path 精确匹配；包含子文件夹时按路径前缀区间 [folder/, folder0) 比较
+// （'0' 紧随 '/' 之后），两者都能走索引，且不受文件夹名中 % 与 _ 的影响。
+func folderScope(folder string, recursive bool) (string, []any) {
+	if !recursive {
+		return "f.parent_path = ?", []any{folder}
+	}
+	return "(f.path = ? OR (f.path > ? AND f.path < ?))", []any{folder, folder + "/", folder + "0"}
+}
+
+// nameLikePattern 将文件名查询转换为 LIKE 模式：含 * / ? 时按通配符整体匹配，否则按子串匹配
+func nameLikePattern(query string) string {
+	escaped := escapeLike(query)
+	if !strings.ContainsAny(query, "*?") {
+		return "%" + escaped + "%"
+	}
+	return strings.NewReplacer("*", "%", "?", "_").Replace(escaped)
+}
+
+// escapeLike 转义 LIKE 中的特殊字符（配合 ESCAPE '\' 使用）
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// resolveQueryTags 将表达式中的标签名解析为标签记录（不区分大小写），未知标签返回带位置的错误
+func (d *Database) resolveQueryTags(ctx context.Context, src string, node tagquery.Node) (map[string]Tag, error) {
+	leaves := tagquery.Tags(node)
+	names := make([]string, 0, len(leaves))
+	placeholders := make([]string, 0, len(leaves))
+	for _, leaf := range leaves {
+		names = append(names, leaf.Name)
+		placeholders = append(placeholders, "?")
+	}
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+
+	query := fmt.Sprintf(
+		`SELECT id, name, color, parent_id FROM tags WHERE name COLLATE NOCASE IN (%s)`,
+		strings.Join(placeholders, ","),
+	)
+	rows, err := d.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]Tag, len(names))
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.ParentID); err != nil {
+			return nil, fmt.Errorf("读取标签记录失败: %w", err)
+		}
+		result[strings.ToLower(tag.Name)] = tag
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历标签记录失败: %w", err)
+	}
+
+	for _, leaf := range leaves {
+		if _, ok := result[strings.ToLower(leaf.Name)]; !ok {
+			return nil, tagquery.NewError(src, leaf.Pos, leaf.End, fmt.Sprintf("：标签「%s」不存在", leaf.Name))
+		}
+	}
+	return result, nil
+}
+
+// compileTagQuery 将标签表达式编译为参数化 SQL，依赖 file_tags 主键 (file_id, tag_id)
+func compileTagQuery(node tagquery.Node, tags map[string]Tag) (string, []any) {
+	switch n := node.(type) {
+	case *tagquery.Tag:
+		return `EXISTS (SELECT 1 FROM file_tags ft WHERE ft.file_id = f.id AND ft.tag_id = ?)`,
+			[]any{tags[strings.ToLower(n.Name)].ID}
+	case *tagquery.Not:
+		clause, args := compileTagQuery(n.X, tags)
+		return "NOT " + clause, args
+	case *tagquery.And:
+		return compileTagTerms(n.Terms, " AND ", tags)
+	case *tagquery.Or:
+		return compileTagTerms(n.Terms, " OR ", tags)
+	default:
+		return "0", nil
+	}
+}
+
+func compileTagTerms(terms []tagquery.Node, op string, tags map[string]Tag) (string, []any) {
+	clauses := make([]string, 0, len(terms))
+	var args []any
+	for _, term := range terms {
+		clause, termArgs := compileTagQuery(term, tags)
+		clauses = append(clauses, clause)
+		args = append(args, termArgs...)
+	}
+	return "(" + strings.Join(clauses, op) + ")", args
+}
+
+// queryFileRecords 执行文件查询并附带每个文件的标签
+func (d *Database) queryFileRecords(ctx context.Context, query string, args []any, capacity int) ([]FileRecord, error) {
+	rows, err := d.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询文件列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]FileRecord, 0, capacity)
+	fileIDs := make([]int64, 0, capacity)
+	for rows.Next() {
+		var record FileRecord
+		var modTime sql.NullTime
+		if err := rows.Scan(
+			&record.ID,
+			&record.WorkspaceID,
+			&record.Path,
+			&record.Name,
+			&record.Size,
+			&record.Type,
+			&modTime,
+			&record.CreatedAt,
+			&record.Hash,
+		); err != nil {
+			return nil, fmt.Errorf("解析文件记录失败: %w", err)
+		}
+		record.ModTime = modTime.Time // 没有修改时间的记录保持零值
+		records = append(records, record)
+		fileIDs = append(fileIDs, record.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历文件记录失败: %w", err)
+	}
+
+	// 获取文件的标签
+	if len(fileIDs) > 0 {
+		tagMap, err := d.getTagsForFiles(ctx, fileIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range records {
+			if tags, ok := tagMap[records[i].ID]; ok {
+				records[i].Tags = tags
+			}
+		}
+	}
+
+	return records, nil
+}
+
+// normalizePaging 规范分页参数
+func normalizePaging(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = 200
+	}
+	if limit > 2000 {
+		limit = 2000
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
@@ -0,0 +1,274 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FolderNode 文件夹节点及其递归统计（仅统计普通文件）
+type FolderNode struct {
+	Path           string
+	Name           string
+	SubfolderCount int64 // 直接子文件夹数量
+	FileCount      int64
+	TotalSize      int64
+	TaggedCount    int64
+}
+
+// FolderTagProgress 文件夹的打标签进度（均按递归统计普通文件）
+type FolderTagProgress struct {
+	FolderPath string // 相对路径，空字符串表示工作区根目录
+	Total      int64
+	Tagged     int64
+}
+
+// folderAggregate 按直接子项分组的统计，child 为空表示直接位于该文件夹下的文件
+type folderAggregate struct {
+	child     string
+	fileCount int64
+	totalSize int64
+	tagged    int64
+}
+
+// hasTagsExpr 文件至少有一个标签（走 file_tags 主键）
+const hasTagsExpr = `EXISTS (SELECT 1 FROM file_tags ft WHERE ft.file_id = f.id)`
+
+// FolderChildren 返回文件夹的直接子文件夹及其递归文件数、总大小与已打标签文件数
+func (d *Database) FolderChildren(ctx context.Context, workspaceID int64, folderPath string) ([]FolderNode, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if workspaceID <= 0 {
+		return nil, errors.New("缺少有效的工作区 ID")
+	}
+	folder := normalizeFolderPath(folderPath)
+
+	rows, err := d.conn.QueryContext(ctx, `
+		SELECT f.path, f.name,
+			(SELECT COUNT(1) FROM files c WHERE c.workspace_id = f.workspace_id AND c.parent_path = f.path AND c.type = 'dir')
+		FROM files f
+		WHERE f.workspace_id = ? AND f.parent_path = ? AND f.type = 'dir' AND f.path != ''
+		ORDER BY f.name_key, f.id`,
+		workspaceID, folder,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询子文件夹失败: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []FolderNode
+	index := make(map[string]int)
+	for rows.Next() {
+		var node FolderNode
+		if err := rows.Scan(&node.Path, &node.Name, &node.SubfolderCount); err != nil {
+			return nil, fmt.Errorf("读取子文件夹失败: %w", err)
+		}
+		index[node.Name] = len(nodes)
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历子文件夹失败: %w", err)
+	}
+	rows.Close()
+
+	if len(nodes) == 0 {
+		return nodes, nil
+	}
+
+	aggregates, err := d.folderAggregates(ctx, workspaceID, folder, hasTagsExpr, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, agg := range aggregates {
+		i, ok := index[agg.child]
+		if !ok {
+			continue
+		}
+		nodes[i].FileCount = agg.fileCount
+		nodes[i].TotalSize = agg.totalSize
+		nodes[i].TaggedCount = agg.tagged
+	}
+	return nodes, nil
+}
+
+// TagProgressByFolder 统计文件夹及其各直接子文件夹的打标签进度
+//
+// 第一项为 folderPath 自身的汇总，其后为各直接子文件夹。groupID 大于 0 时，
+// 只有拥有该分组（父标签及其子孙标签）中标签的文件才算已打标签。
+func (d *Database) TagProgressByFolder(ctx context.Context, workspaceID int64, folderPath string, groupID int64) ([]FolderTagProgress, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if workspaceID <= 0 {
+		return nil, errors.New("缺少有效的工作区 ID")
+	}
+	folder := normalizeFolderPath(folderPath)
+
+	tagged := hasTagsExpr
+	var tagArgs []any
+	if groupID > 0 {
+		groupIDs, err := d.tagGroupIDs(ctx, groupID)
+		if err != nil {
+			return nil, err
+		}
+		placeholders := make([]string, len(groupIDs))
+		for i, id := range groupIDs {
+			placeholders[i] = "?"
+			tagArgs = append(tagArgs, id)
+		}
+		tagged = fmt.Sprintf(
+			`EXISTS (SELECT 1 FROM file_tags ft WHERE ft.file_id = f.id AND ft.tag_id IN (%s))`,
+			strings.Join(placeholders, ","),
+		)
+	}
+
+	aggregates, err := d.folderAggregates(ctx, workspaceID, folder, tagged, tagArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := FolderTagProgress{FolderPath: folder}
+	var children []FolderTagProgress
+	for _, agg := range aggregates {
+		summary.Total += agg.fileCount
+		summary.Tagged += agg.tagged
+		if agg.child == "" {
+			continue
+		}
+		children = append(children, FolderTagProgress{
+			FolderPath: joinFolderPath(folder, agg.child),
+			Total:      agg.fileCount,
+			Tagged:     agg.tagged,
+		})
+	}
+	return append([]FolderTagProgress{summary}, children...), nil
+}
+
+// folderAggregates 用一条聚合查询统计文件夹下各直接子项的递归文件数、大小与已打标签数
+func (d *Database) folderAggregates(ctx context.Context, workspaceID int64, folder, taggedExpr string, taggedArgs []any) ([]folderAggregate, error) {
+	conds := &fileConditions{}
+	conds.add("f.workspace_id = ?", workspaceID)
+	conds.add("f.type = 'file'")
+	prefix := ""
+	if folder != "" {
+		clause, args := folderScope(folder, true)
+		conds.add(clause, args...)
+		prefix = folder + "/"
+	}
+
+	// rest 为相对 folder 的路径，第一段即直接子文件夹名
+	query := fmt.Sprintf(`
+		SELECT
+			CASE WHEN instr(f.rest, '/') > 0 THEN substr(f.rest, 1, instr(f.rest, '/') - 1) ELSE '' END AS child,
+			COUNT(1),
+			IFNULL(SUM(f.size), 0),
+			IFNULL(SUM(%s), 0)
+		FROM (
+			SELECT f.id, f.size, substr(f.path, ?) AS rest
+			FROM files f
+			WHERE %s
+		) f
+		GROUP BY child
+		ORDER BY child COLLATE natural_zh`, taggedExpr, conds.where())
+	args := append(append([]any{}, taggedArgs...), utf8.RuneCountInString(prefix)+1)
+	args = append(args, conds.args...)
+
+	rows, err := d.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("统计文件夹失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []folderAggregate
+	for rows.Next() {
+		var agg folderAggregate
+		if err := rows.Scan(&agg.child, &agg.fileCount, &agg.totalSize, &agg.tagged); err != nil {
+			return nil, fmt.Errorf("读取文件夹统计失败: %w", err)
+		}
+		result = append(result, agg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历文件夹统计失败: %w", err)
+	}
+	return result, nil
+}
+
+// joinFolderPath 拼接相对路径，folder 为空时直接返回 name
+func joinFolderPath(folder, name string) string {
+	if folder == "" {
+		return name
+	}
+	return folder + "/" + name
+}
+
+// DeleteFolderRecords 删除已从磁盘移除的文件夹记录（仅 type 为 dir 的记录）
+func (d *Database) DeleteFolderRecords(ctx context.Context, workspaceID int64, paths []string) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(paths)+1)
+	args = append(args, workspaceID)
+	for _, p := range paths {
+		args = append(args, p)
+	}
+	query := `DELETE FROM files WHERE workspace_id = ? AND type = 'dir' AND path IN (?` +
+		strings.Repeat(", ?", len(paths)-1) + `)`
+	if _, err := d.conn.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("删除文件夹记录失败: %w", err)
+	}
+	return nil
+}
+
+// EnsureFolderRecords 为新建的文件夹补齐记录，已存在的记录保持不变
+func (d *Database) EnsureFolderRecords(ctx context.Context, workspaceID int64, paths []string) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO files(
+			workspace_id, path, name, size, type, mod_time, created_at, hash, ext, name_key, parent_path,
+			name_pinyin, name_initials
+		) VALUES (?, ?, ?, 0, ?, ?, ?, '', ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("准备插入语句失败: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, p := range paths {
+		name := p[strings.LastIndex(p, "/")+1:]
+		namePinyin, nameInitials := filePinyinKeys(name, FileTypeDirectory)
+		if _, err := stmt.ExecContext(ctx,
+			workspaceID, p, name, FileTypeDirectory, now, now,
+			FileExt(name, FileTypeDirectory), NameSortKey(name), ParentPath(p),
+			namePinyin, nameInitials,
+		); err != nil {
+			return fmt.Errorf("写入文件夹记录失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// columnMigration 描述需要补齐到旧库中的列
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// 旧版本数据库缺失的列，按顺序补齐（只增不改，避免破坏已落库结构）
+var columnMigrations = []columnMigration{
+	{"files", "ext", "TEXT NOT NULL DEFAULT ''"},
+	{"files", "name_key", "BLOB"},
+	{"files", "parent_path", "TEXT"},
+	{"files", "name_pinyin", "TEXT COLLATE NOCASE"},
+	{"files", "name_initials", "TEXT COLLATE NOCASE"},
+	{"tags", "name_pinyin", "TEXT COLLATE NOCASE"},
+	{"tags", "name_initials", "TEXT COLLATE NOCASE"},
+}
+
+// 依赖迁移列的索引，需在补齐列之后创建
+var migrationIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_ext ON files(workspace_id, ext);`,
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_size ON files(workspace_id, size);`,
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_type ON files(workspace_id, type);`,
+	`CREATE INDEX IF NOT EXISTS idx_file_tags_tag ON file_tags(tag_id, file_id);`,
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_name_key ON files(workspace_id, name_key, id);`,
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_created ON files(workspace_id, created_at);`,
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_modtime_sort ON files(workspace_id, COALESCE(mod_time, ''), id);`,
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_parent ON files(workspace_id, parent_path, type);`,
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_pinyin ON files(workspace_id, name_pinyin);`,
+	`CREATE INDEX IF NOT EXISTS idx_files_workspace_initials ON files(workspace_id, name_initials);`,
+	`CREATE INDEX IF NOT EXISTS idx_tags_pinyin ON tags(name_pinyin);`,
+	`CREATE INDEX IF NOT EXISTS idx_tags_initials ON tags(name_initials);`,
+	`CREATE INDEX IF NOT EXISTS idx_operations_type ON operations(type);`,
+	`CREATE INDEX IF NOT EXISTS idx_operations_workspace ON operations(workspace_id, id);`,
+}
+
+// migrate 为旧库补齐新增的列与索引
+func (d *Database) migrate(ctx context.Context) error {
+	for _, m := range columnMigrations {
+		if _, err := d.ensureColumn(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	if err := d.migrateOperationsTable(ctx); err != nil {
+		return err
+	}
+
+	if err := d.backfillFileDerived(ctx); err != nil {
+		return err
+	}
+	if err := d.backfillTagPinyin(ctx); err != nil {
+		return err
+	}
+	if err := d.backfillTagStats(ctx); err != nil {
+		return err
+	}
+
+	for _, stmt := range migrationIndexes {
+		if _, err := d.conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("创建索引失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureColumn 列不存在时追加，返回是否新增
+func (d *Database) ensureColumn(ctx context.Context, m columnMigration) (bool, error) {
+	columns, err := d.tableColumns(ctx, m.table)
+	if err != nil {
+		return false, err
+	}
+	if columns[strings.ToLower(m.column)] {
+		return false, nil
+	}
+
+	stmt := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, m.table, m.column, m.definition)
+	if _, err := d.conn.ExecContext(ctx, stmt); err != nil {
+		return false, fmt.Errorf("补齐字段 %s.%s 失败: %w", m.table, m.column, err)
+	}
+	return true, nil
+}
+
+// tableColumns 返回表的全部列名（小写）
+func (d *Database) tableColumns(ctx context.Context, table string) (map[string]bool, error) {
+	rows, err := d.conn.QueryContext(ctx, fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return nil, fmt.Errorf("读取表结构失败: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue any
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return nil, fmt.Errorf("解析表结构失败: %w", err)
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历表结构失败: %w", err)
+	}
+	return columns, nil
+}
+
+// backfillFileDerived 为旧记录补齐由文件名/路径派生的列（扩展名、排序键、父目录、拼音）
+func (d *Database) backfillFileDerived(ctx context.Context) error {
+	rows, err := d.conn.QueryContext(ctx, `
+		SELECT id, path, name, type FROM files
+		WHERE name_key IS NULL OR parent_path IS NULL OR name_pinyin IS NULL`)
+	if err != nil {
+		return fmt.Errorf("查询待补齐的文件记录失败: %w", err)
+	}
+	type pending struct {
+		id           int64
+		ext          string
+		nameKey      []byte
+		parentPath   string
+		namePinyin   string
+		nameInitials string
+	}
+	var items []pending
+	for rows.Next() {
+		var id int64
+		var relPath, name, fileType string
+		if err := rows.Scan(&id, &relPath, &name, &fileType); err != nil {
+			rows.Close()
+			return fmt.Errorf("解析文件记录失败: %w", err)
+		}
+		item := pending{
+			id:         id,
+			ext:        FileExt(name, fileType),
+			nameKey:    NameSortKey(name),
+			parentPath: ParentPath(relPath),
+		}
+		item.namePinyin, item.nameInitials = filePinyinKeys(name, fileType)
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("遍历文件记录失败: %w", err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE files SET ext = ?, name_key = ?, parent_path = ?, name_pinyin = ?, name_initials = ? WHERE id = ?`)
+	if err != nil {
+		return fmt.Errorf("准备更新语句失败: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, item := range items {
+		if _, err := stmt.ExecContext(ctx, item.ext, item.nameKey, item.parentPath, item.namePinyin, item.nameInitials, item.id); err != nil {
+			return fmt.Errorf("补齐文件派生字段失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// backfillTagPinyin 为旧标签补齐拼音列
+func (d *Database) backfillTagPinyin(ctx context.Context) error {
+	rows, err := d.conn.QueryContext(ctx, `SELECT id, name FROM tags WHERE name_pinyin IS NULL`)
+	if err != nil {
+		return fmt.Errorf("查询待补齐的标签失败: %w", err)
+	}
+	names := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return fmt.Errorf("解析标签记录失败: %w", err)
+		}
+		names[id] = name
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("遍历标签记录失败: %w", err)
+	}
+
+	for id, name := range names {
+		namePinyin, nameInitials := PinyinKeys(name)
+		if _, err := d.conn.ExecContext(ctx,
+			`UPDATE tags SET name_pinyin = ?, name_initials = ? WHERE id = ?`,
+			namePinyin, nameInitials, id,
+		); err != nil {
+			return fmt.Errorf("补齐标签拼音失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// backfillTagStats 统计表为空而已有标签关联时（旧库升级），按 file_tags 全量重建
+func (d *Database) backfillTagStats(ctx context.Context) error {
+	var needRebuild bool
+	if err := d.conn.QueryRowContext(ctx, `
+		SELECT NOT EXISTS (SELECT 1 FROM tag_stats) AND EXISTS (SELECT 1 FROM file_tags)`,
+	).Scan(&needRebuild); err != nil {
+		return fmt.Errorf("检查标签统计失败: %w", err)
+	}
+	if !needRebuild {
+		return nil
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	statements := []string{
+		`DELETE FROM tag_pairs`,
+		`DELETE FROM tag_stats`,
+		`INSERT INTO tag_stats(tag_id, file_count) SELECT tag_id, COUNT(1) FROM file_tags GROUP BY tag_id`,
+		`INSERT INTO tag_pairs(tag_id, other_id, file_count)
+			SELECT a.tag_id, b.tag_id, COUNT(1)
+			FROM file_tags a JOIN file_tags b ON b.file_id = a.file_id AND b.tag_id != a.tag_id
+			GROUP BY a.tag_id, b.tag_id`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("重建标签统计失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// FileExt 返回小写、不带点的扩展名，目录返回空字符串
+func FileExt(name, fileType string) string {
+	if fileType == FileTypeDirectory {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+// ParentPath 返回相对路径（以 / 分隔）的父目录，位于工作区根目录下的项目返回空字符串
+func ParentPath(relPath string) string {
+	if relPath == "" {
+		return ""
+	}
+	parent := path.Dir(relPath)
+	if parent == "." || parent == "/" {
+		return ""
+	}
+	return parent
+}
@@ -0,0 +1,187 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 移动步骤状态
+const (
+	MoveStatePending    = "pending"     // 已写入意图，尚未确认完成
+	MoveStateDone       = "done"        // 已移动并同步数据库
+	MoveStateFailed     = "failed"      // 移动失败
+	MoveStateRolledBack = "rolled_back" // 已回滚到原位置
+)
+
+// OperationMove 执行中操作的单个移动步骤
+type OperationMove struct {
+	OperationID int64
+	Seq         int
+	FileID      int64
+	From        string // 相对路径（/ 分隔）
+	To          string
+	State       string
+	Message     string
+}
+
+// BeginOperation 在单个事务中写入状态为 running 的操作记录及全部移动步骤，须在触碰磁盘前调用
+func (d *Database) BeginOperation(ctx context.Context, workspaceID int64, opType, summary, payload string, moves []OperationMove) (int64, error) {
+	if d == nil || d.conn == nil {
+		return 0, errors.New("数据库对象尚未初始化")
+	}
+	if opType == "" || payload == "" {
+		return 0, errors.New("操作类型与内容不能为空")
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var workspace any
+	if workspaceID > 0 {
+		workspace = workspaceID
+	}
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO operations(type, payload, workspace_id, summary, status) VALUES(?, ?, ?, ?, ?)`,
+		opType, payload, workspace, summary, OperationRunning,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("写入操作记录失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("获取操作记录 ID 失败: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO operation_moves(operation_id, seq, file_id, from_path, to_path) VALUES(?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("准备写入语句失败: %w", err)
+	}
+	defer stmt.Close()
+	for _, move := range moves {
+		if _, err := stmt.ExecContext(ctx, id, move.Seq, move.FileID, move.From, move.To); err != nil {
+			return 0, fmt.Errorf("写入操作步骤失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交事务失败: %w", err)
+	}
+	return id, nil
+}
+
+// SetOperationMoveState 更新单个移动步骤的状态
+func (d *Database) SetOperationMoveState(ctx context.Context, operationID int64, seq int, state, message string) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+
+	if _, err := d.conn.ExecContext(ctx,
+		`UPDATE operation_moves SET state = ?, message = ?, updated_at = CURRENT_TIMESTAMP WHERE operation_id = ? AND seq = ?`,
+		state, message, operationID, seq,
+	); err != nil {
+		return fmt.Errorf("更新操作步骤失败: %w", err)
+	}
+	return nil
+}
+
+// ListOperationMoves 按顺序返回操作的移动步骤
+func (d *Database) ListOperationMoves(ctx context.Context, operationID int64) ([]OperationMove, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+
+	rows, err := d.conn.QueryContext(ctx,
+		`SELECT operation_id, seq, file_id, from_path, to_path, state, message
+		 FROM operation_moves WHERE operation_id = ? ORDER BY seq`, operationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询操作步骤失败: %w", err)
+	}
+	defer rows.Close()
+
+	var moves []OperationMove
+	for rows.Next() {
+		var m OperationMove
+		if err := rows.Scan(&m.OperationID, &m.Seq, &m.FileID, &m.From, &m.To, &m.State, &m.Message); err != nil {
+			return nil, fmt.Errorf("读取操作步骤失败: %w", err)
+		}
+		moves = append(moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历操作步骤失败: %w", err)
+	}
+	return moves, nil
+}
+
+// FinishOperation 结束执行中的操作：更新为 applied 或 undone 并清除移动步骤
+func (d *Database) FinishOperation(ctx context.Context, id int64, status string) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+	if status != OperationApplied && status != OperationUndone {
+		return fmt.Errorf("无效的操作状态: %s", status)
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE operations SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?`,
+		status, id, OperationRunning,
+	)
+	if err != nil {
+		return fmt.Errorf("更新操作记录失败: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errors.New("操作记录不存在或已结束")
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM operation_moves WHERE operation_id = ?`, id); err != nil {
+		return fmt.Errorf("清除操作步骤失败: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// ListRunningOperations 返回仍处于执行中的操作（通常是异常退出遗留的），最早的在前
+func (d *Database) ListRunningOperations(ctx context.Context) ([]Operation, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+
+	rows, err := d.conn.QueryContext(ctx,
+		`SELECT `+operationColumns+` FROM operations WHERE status = ? ORDER BY id`, OperationRunning,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询操作记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []Operation
+	for rows.Next() {
+		var op Operation
+		if err := scanOperation(rows, &op); err != nil {
+			return nil, fmt.Errorf("读取操作记录失败: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历操作记录失败: %w", err)
+	}
+	return ops, nil
+}
@@ -0,0 +1,331 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 操作类型
+const (
+	OperationOrganize = "organize" // 一键整理移动文件
+	OperationTag      = "tag"      // 文件标签变更及随之发生的重命名
+	OperationRename   = "rename"   // 文件重命名
+	OperationTagEdit  = "tag_edit" // 标签重命名、合并与删除
+)
+
+// 操作状态
+const (
+	OperationRunning = "running" // 已写入意图、正在执行（异常退出后需继续或回滚）
+	OperationApplied = "applied" // 已执行，可撤销
+	OperationUndone  = "undone"  // 已撤销，可重做
+)
+
+// operationTypes、operationStatuses 为 operations.type/status 允许的取值，新增取值后旧库会在迁移时重建表结构
+var (
+	operationTypes    = []string{OperationOrganize, OperationTag, OperationRename, OperationTagEdit}
+	operationStatuses = []string{OperationRunning, OperationApplied, OperationUndone}
+)
+
+// operationsTableSQL 根据 operationTypes、operationStatuses 生成操作日志表结构
+func operationsTableSQL() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS operations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL CHECK(type IN (%s)),
+			payload TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			workspace_id INTEGER,
+			summary TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'applied' CHECK(status IN (%s)),
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`, quoteValues(operationTypes), quoteValues(operationStatuses))
+}
+
+func quoteValues(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, "'"+v+"'")
+	}
+	return strings.Join(quoted, ",")
+}
+
+// Operation 表示一条操作记录
+type Operation struct {
+	ID          int64         `json:"id"`
+	Type        string        `json:"type"`
+	Payload     string        `json:"payload"`
+	CreatedAt   time.Time     `json:"created_at"`
+	WorkspaceID sql.NullInt64 `json:"workspace_id"`
+	Summary     string        `json:"summary"`
+	Status      string        `json:"status"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+const operationColumns = `id, type, payload, created_at, workspace_id, summary, status, updated_at`
+
+func scanOperation(scanner interface{ Scan(...any) error }, op *Operation) error {
+	return scanner.Scan(&op.ID, &op.Type, &op.Payload, &op.CreatedAt, &op.WorkspaceID, &op.Summary, &op.Status, &op.UpdatedAt)
+}
+
+// InsertOperation 写入操作记录，workspaceID 为 0 表示与工作区无关
+func (d *Database) InsertOperation(ctx context.Context, workspaceID int64, opType, summary, payload string) (int64, error) {
+	if d == nil || d.conn == nil {
+		return 0, errors.New("数据库对象尚未初始化")
+	}
+	if opType == "" {
+		return 0, errors.New("操作类型不能为空")
+	}
+	if payload == "" {
+		return 0, errors.New("操作内容不能为空")
+	}
+
+	var workspace any
+	if workspaceID > 0 {
+		workspace = workspaceID
+	}
+	result, err := d.conn.ExecContext(ctx,
+		`INSERT INTO operations(type, payload, workspace_id, summary) VALUES(?, ?, ?, ?)`,
+		opType, payload, workspace, summary,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("写入操作记录失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("获取操作记录 ID 失败: %w", err)
+	}
+	return id, nil
+}
+
+// GetOperation 读取操作记录
+func (d *Database) GetOperation(ctx context.Context, id int64) (*Operation, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return nil, errors.New("无效的操作 ID")
+	}
+
+	row := d.conn.QueryRowContext(ctx, `SELECT `+operationColumns+` FROM operations WHERE id = ?`, id)
+	var op Operation
+	if err := scanOperation(row, &op); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("操作记录不存在")
+		}
+		return nil, fmt.Errorf("查询操作记录失败: %w", err)
+	}
+	return &op, nil
+}
+
+// ListOperations 按时间倒序返回指定工作区及与工作区无关的操作记录，workspaceID 为 0 时返回全部
+func (d *Database) ListOperations(ctx context.Context, workspaceID int64, limit int) ([]Operation, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	limit, _ = normalizePaging(limit, 0)
+
+	query := `SELECT ` + operationColumns + ` FROM operations`
+	var args []any
+	if workspaceID > 0 {
+		query += ` WHERE workspace_id = ? OR workspace_id IS NULL`
+		args = append(args, workspaceID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := d.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询操作记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []Operation
+	for rows.Next() {
+		var op Operation
+		if err := scanOperation(rows, &op); err != nil {
+			return nil, fmt.Errorf("读取操作记录失败: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历操作记录失败: %w", err)
+	}
+	return ops, nil
+}
+
+// ListOperationsByType 按时间倒序返回指定类型的全部操作记录，workspaceID 为 0 时不限工作区
+func (d *Database) ListOperationsByType(ctx context.Context, workspaceID int64, opType string) ([]Operation, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+
+	query := `SELECT ` + operationColumns + ` FROM operations WHERE type = ?`
+	args := []any{opType}
+	if workspaceID > 0 {
+		query += ` AND workspace_id = ?`
+		args = append(args, workspaceID)
+	}
+	query += ` ORDER BY id DESC`
+
+	rows, err := d.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询操作记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []Operation
+	for rows.Next() {
+		var op Operation
+		if err := scanOperation(rows, &op); err != nil {
+			return nil, fmt.Errorf("读取操作记录失败: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历操作记录失败: %w", err)
+	}
+	return ops, nil
+}
+
+// SetOperationStatus 更新操作记录的状态（撤销、重做）
+func (d *Database) SetOperationStatus(ctx context.Context, id int64, status string) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return errors.New("无效的操作 ID")
+	}
+	if status != OperationApplied && status != OperationUndone {
+		return fmt.Errorf("无效的操作状态: %s", status)
+	}
+	// 执行中的操作只能通过 FinishOperation 结束
+
+	result, err := d.conn.ExecContext(ctx,
+		`UPDATE operations SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status != ?`, status, id, OperationRunning,
+	)
+	if err != nil {
+		return fmt.Errorf("更新操作记录失败: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errors.New("操作记录不存在或尚未执行完成")
+	}
+	return nil
+}
+
+// SetOperationPayload 更新操作内容（执行过程中补充的信息，例如跨设备复制的文件）
+func (d *Database) SetOperationPayload(ctx context.Context, id int64, payload string) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return errors.New("无效的操作 ID")
+	}
+	if payload == "" {
+		return errors.New("操作内容不能为空")
+	}
+
+	result, err := d.conn.ExecContext(ctx,
+		`UPDATE operations SET payload = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, payload, id,
+	)
+	if err != nil {
+		return fmt.Errorf("更新操作记录失败: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return errors.New("操作记录不存在")
+	}
+	return nil
+}
+
+// DeleteOperation 删除操作记录
+func (d *Database) DeleteOperation(ctx context.Context, id int64) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return errors.New("无效的操作 ID")
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM operation_moves WHERE operation_id = ?`, id); err != nil {
+		return fmt.Errorf("删除操作步骤失败: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM operations WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("删除操作记录失败: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// migrateOperationsTable 旧库的操作日志表缺少新列或取值约束过旧时，重建表并保留原有记录
+func (d *Database) migrateOperationsTable(ctx context.Context) error {
+	var current string
+	if err := d.conn.QueryRowContext(ctx,
+		`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'operations'`,
+	).Scan(&current); err != nil {
+		return fmt.Errorf("读取操作日志表结构失败: %w", err)
+	}
+	upToDate := strings.Contains(current, "status")
+	for _, v := range append(append([]string{}, operationTypes...), operationStatuses...) {
+		upToDate = upToDate && strings.Contains(current, "'"+v+"'")
+	}
+	if upToDate {
+		return nil
+	}
+
+	oldColumns, err := d.tableColumns(ctx, "operations")
+	if err != nil {
+		return err
+	}
+	var common []string
+	for _, column := range strings.Split(operationColumns, ", ") {
+		if oldColumns[column] {
+			common = append(common, column)
+		}
+	}
+	columns := strings.Join(common, ", ")
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	statements := []string{
+		`ALTER TABLE operations RENAME TO operations_old`,
+		operationsTableSQL(),
+		fmt.Sprintf(`INSERT INTO operations(%s) SELECT %s FROM operations_old`, columns, columns),
+		`DROP TABLE operations_old`,
+		// 旧版本的整理记录只在 payload 中保存工作区
+		`UPDATE operations SET workspace_id = json_extract(payload, '$.workspace_id')
+			WHERE workspace_id IS NULL AND json_valid(payload)`,
+		`UPDATE operations SET summary = '整理 ' || json_array_length(payload, '$.moves') || ' 个文件'
+			WHERE type = 'organize' AND summary = '' AND json_valid(payload)`,
+		`UPDATE operations SET updated_at = created_at`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("迁移操作日志表失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package data
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// legacyOperationsSchema 操作日志表最初的结构：只有类型、内容与创建时间
+const legacyOperationsSchema = `CREATE TABLE operations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL CHECK(type IN ('organize','tag')),
+	payload TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`
+
+func TestMigrateOperationsTable(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	organizePayload := `{"workspace_id":3,"moves":[{"file_id":1,"from":"a.txt","to":"x/a.txt"},{"file_id":2,"from":"b.txt","to":"x/b.txt"}]}`
+	legacy := []string{
+		legacyOperationsSchema,
+		`CREATE INDEX idx_operations_type ON operations(type);`,
+		`INSERT INTO operations(type, payload, created_at) VALUES('organize', '` + organizePayload + `', '2024-05-06 07:08:09')`,
+		`INSERT INTO operations(type, payload, created_at) VALUES('tag', 'not json', '2024-05-07 00:00:00')`,
+	}
+	for _, stmt := range legacy {
+		if _, err := db.conn.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("prepare legacy schema: %v", err)
+		}
+	}
+
+	// 第二次初始化应当不再改动已迁移的表
+	for i := 0; i < 2; i++ {
+		if err := db.InitDB(ctx); err != nil {
+			t.Fatalf("InitDB #%d: %v", i+1, err)
+		}
+	}
+
+	created := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	op, err := db.GetOperation(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Type != OperationOrganize || op.Payload != organizePayload {
+		t.Errorf("organize row changed: type=%s payload=%s", op.Type, op.Payload)
+	}
+	if !op.WorkspaceID.Valid || op.WorkspaceID.Int64 != 3 {
+		t.Errorf("workspace_id = %+v, want 3 from payload", op.WorkspaceID)
+	}
+	if op.Summary != "整理 2 个文件" {
+		t.Errorf("summary = %q, want 整理 2 个文件", op.Summary)
+	}
+	if op.Status != OperationApplied {
+		t.Errorf("status = %q, want %q", op.Status, OperationApplied)
+	}
+	if !op.CreatedAt.Equal(created) || !op.UpdatedAt.Equal(created) {
+		t.Errorf("created_at/updated_at = %v/%v, want %v", op.CreatedAt, op.UpdatedAt, created)
+	}
+
+	tagOp, err := db.GetOperation(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tagOp.Type != OperationTag || tagOp.Payload != "not json" || tagOp.WorkspaceID.Valid || tagOp.Summary != "" {
+		t.Errorf("tag row = %+v, want it kept unchanged without workspace or summary", tagOp)
+	}
+
+	id, err := db.InsertOperation(ctx, 3, OperationTag, "添加 1 个文件的标签", `{"action":"add"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 3 {
+		t.Errorf("new operation id = %d, want 3 (ids continue after migrated rows)", id)
+	}
+
+	ops, err := db.ListOperations(ctx, 3, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, op := range ops {
+		ids = append(ids, op.ID)
+	}
+	if len(ids) != 3 || ids[0] != 3 || ids[1] != 2 || ids[2] != 1 {
+		t.Errorf("ListOperations ids = %v, want [3 2 1]", ids)
+	}
+	if other, err := db.ListOperations(ctx, 4, 10); err != nil || len(other) != 1 || other[0].ID != 2 {
+		t.Errorf("ListOperations(other workspace) = %+v, %v, want only the workspace-less row", other, err)
+	}
+}
+
+func TestSetOperationStatus(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := db.InitDB(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := db.InsertOperation(ctx, 0, OperationTag, "", `{}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetOperationStatus(ctx, id, OperationUndone); err != nil {
+		t.Fatal(err)
+	}
+	op, err := db.GetOperation(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Status != OperationUndone || op.WorkspaceID.Valid {
+		t.Errorf("operation = %+v, want undone without workspace", op)
+	}
+
+	if err := db.SetOperationStatus(ctx, id, "deleted"); err == nil {
+		t.Error("invalid status should be rejected")
+	}
+	if err := db.SetOperationStatus(ctx, id+1, OperationApplied); err == nil {
+		t.Error("missing operation should be reported")
+	}
+	if _, err := db.InsertOperation(ctx, 0, "unknown", "", `{}`); err == nil {
+		t.Error("type outside the CHECK constraint should be rejected")
+	}
+}
@@ -0,0 +1,383 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 自动整理的触发方式
+const (
+	RuleTriggerSchedule = "schedule"  // 定时
+	RuleTriggerNewFiles = "new_files" // 出现新文件
+	RuleTriggerManual   = "manual"    // 手动运行
+)
+
+// 自动整理单次运行的结果
+const (
+	RuleRunApplied   = "applied"    // 已执行，生成了可撤销的整理操作
+	RuleRunDryRun    = "dry_run"    // 只生成预览
+	RuleRunNoChanges = "no_changes" // 没有需要移动的文件
+	RuleRunBlocked   = "blocked"    // 存在未解决的冲突，未执行
+	RuleRunFailed    = "failed"     // 生成计划或执行失败
+)
+
+// maxRuleRuns 每条规则保留的运行记录数
+const maxRuleRuns = 100
+
+// errOrganizeRuleExists 同一工作区内规则名称重复
+var errOrganizeRuleExists = errors.New("同名的自动整理规则已存在")
+
+// OrganizeRule 表示一条自动整理规则，Request 为序列化后的整理请求
+type OrganizeRule struct {
+	ID              int64
+	WorkspaceID     int64
+	Name            string
+	SourceFolder    string // 相对工作区的收件箱文件夹
+	Request         string
+	IntervalMinutes int
+	OnNewFiles      bool
+	DryRun          bool
+	Enabled         bool
+	Snapshot        string // 上次检查时收件箱中文件的指纹，用于发现新文件
+	LastRunAt       sql.NullTime
+	LastStatus      string
+	LastMessage     string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// OrganizeRuleRun 表示规则的一次运行
+type OrganizeRuleRun struct {
+	ID            int64
+	RuleID        int64
+	Trigger       string
+	DryRun        bool
+	Status        string
+	OperationID   sql.NullInt64
+	MoveCount     int
+	ConflictCount int
+	SkipCount     int
+	Message       string
+	Preview       string
+	StartedAt     time.Time
+	FinishedAt    time.Time
+}
+
+const organizeRuleColumns = `id, workspace_id, name, source_folder, request, interval_minutes, on_new_files, dry_run,
+	enabled, snapshot, last_run_at, last_status, last_message, created_at, updated_at`
+
+const organizeRuleRunColumns = `id, rule_id, trigger, dry_run, status, operation_id, move_count, conflict_count,
+	skip_count, message, preview, started_at, finished_at`
+
+// CreateOrganizeRule 新增自动整理规则
+func (d *Database) CreateOrganizeRule(ctx context.Context, rule OrganizeRule) (*OrganizeRule, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if rule.WorkspaceID <= 0 {
+		return nil, errors.New("无效的工作区 ID")
+	}
+	exists, err := d.organizeRuleNameTaken(ctx, rule.WorkspaceID, rule.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errOrganizeRuleExists
+	}
+
+	now := time.Now().UTC()
+	result, err := d.conn.ExecContext(ctx,
+		`INSERT INTO organize_rules(workspace_id, name, source_folder, request, interval_minutes, on_new_files, dry_run,
+			enabled, created_at, updated_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.WorkspaceID, rule.Name, rule.SourceFolder, rule.Request, rule.IntervalMinutes, rule.OnNewFiles, rule.DryRun,
+		rule.Enabled, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("写入自动整理规则失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取自动整理规则 ID 失败: %w", err)
+	}
+	return d.GetOrganizeRule(ctx, id)
+}
+
+// UpdateOrganizeRule 修改规则的设置；修改后清空收件箱指纹，下次检查时重新判断是否有新文件
+func (d *Database) UpdateOrganizeRule(ctx context.Context, rule OrganizeRule) (*OrganizeRule, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if rule.ID <= 0 {
+		return nil, errors.New("无效的自动整理规则 ID")
+	}
+	exists, err := d.organizeRuleNameTaken(ctx, rule.WorkspaceID, rule.Name, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errOrganizeRuleExists
+	}
+
+	result, err := d.conn.ExecContext(ctx,
+		`UPDATE organize_rules SET name = ?, source_folder = ?, request = ?, interval_minutes = ?, on_new_files = ?,
+			dry_run = ?, enabled = ?, snapshot = '', updated_at = ? WHERE id = ?`,
+		rule.Name, rule.SourceFolder, rule.Request, rule.IntervalMinutes, rule.OnNewFiles,
+		rule.DryRun, rule.Enabled, time.Now().UTC(), rule.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("更新自动整理规则失败: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return nil, errors.New("自动整理规则不存在")
+	}
+	return d.GetOrganizeRule(ctx, rule.ID)
+}
+
+// GetOrganizeRule 读取单条自动整理规则
+func (d *Database) GetOrganizeRule(ctx context.Context, id int64) (*OrganizeRule, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return nil, errors.New("无效的自动整理规则 ID")
+	}
+
+	row := d.conn.QueryRowContext(ctx, `SELECT `+organizeRuleColumns+` FROM organize_rules WHERE id = ?`, id)
+	rule, err := scanOrganizeRule(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("自动整理规则不存在")
+		}
+		return nil, fmt.Errorf("查询自动整理规则失败: %w", err)
+	}
+	return rule, nil
+}
+
+// ListOrganizeRules 返回工作区的全部自动整理规则
+func (d *Database) ListOrganizeRules(ctx context.Context, workspaceID int64) ([]OrganizeRule, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+
+	rows, err := d.conn.QueryContext(ctx,
+		`SELECT `+organizeRuleColumns+` FROM organize_rules WHERE workspace_id = ? ORDER BY id`,
+		workspaceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询自动整理规则失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []OrganizeRule
+	for rows.Next() {
+		rule, err := scanOrganizeRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取自动整理规则失败: %w", err)
+		}
+		result = append(result, *rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历自动整理规则失败: %w", err)
+	}
+	return result, nil
+}
+
+// SetOrganizeRuleSnapshot 记录最近一次检查时收件箱的指纹
+func (d *Database) SetOrganizeRuleSnapshot(ctx context.Context, id int64, snapshot string) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+
+	if _, err := d.conn.ExecContext(ctx,
+		`UPDATE organize_rules SET snapshot = ? WHERE id = ?`, snapshot, id,
+	); err != nil {
+		return fmt.Errorf("更新自动整理规则失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteOrganizeRule 删除自动整理规则及其运行记录（已生成的整理操作保留在操作日志中）
+func (d *Database) DeleteOrganizeRule(ctx context.Context, id int64) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+
+	result, err := d.conn.ExecContext(ctx, `DELETE FROM organize_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除自动整理规则失败: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err == nil && rows == 0 {
+		return errors.New("自动整理规则不存在")
+	}
+	return nil
+}
+
+// InsertOrganizeRuleRun 写入运行记录并同步规则的最近运行状态，每条规则只保留最近 maxRuleRuns 条记录
+func (d *Database) InsertOrganizeRuleRun(ctx context.Context, run OrganizeRuleRun) (*OrganizeRuleRun, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if run.RuleID <= 0 {
+		return nil, errors.New("无效的自动整理规则 ID")
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO organize_rule_runs(rule_id, trigger, dry_run, status, operation_id, move_count, conflict_count,
+			skip_count, message, preview, started_at, finished_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.RuleID, run.Trigger, run.DryRun, run.Status, run.OperationID, run.MoveCount, run.ConflictCount,
+		run.SkipCount, run.Message, run.Preview, run.StartedAt.UTC(), run.FinishedAt.UTC(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("写入自动整理记录失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取自动整理记录 ID 失败: %w", err)
+	}
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{
+			`UPDATE organize_rules SET last_run_at = ?, last_status = ?, last_message = ? WHERE id = ?`,
+			[]any{run.StartedAt.UTC(), run.Status, run.Message, run.RuleID},
+		},
+		{
+			`DELETE FROM organize_rule_runs WHERE rule_id = ? AND id NOT IN (
+				SELECT id FROM organize_rule_runs WHERE rule_id = ? ORDER BY id DESC LIMIT ?)`,
+			[]any{run.RuleID, run.RuleID, maxRuleRuns},
+		},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return nil, fmt.Errorf("更新自动整理规则失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+	run.ID = id
+	return &run, nil
+}
+
+// GetOrganizeRuleRun 读取单条运行记录
+func (d *Database) GetOrganizeRuleRun(ctx context.Context, id int64) (*OrganizeRuleRun, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return nil, errors.New("无效的运行记录 ID")
+	}
+
+	row := d.conn.QueryRowContext(ctx, `SELECT `+organizeRuleRunColumns+` FROM organize_rule_runs WHERE id = ?`, id)
+	run, err := scanOrganizeRuleRun(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("运行记录不存在")
+		}
+		return nil, fmt.Errorf("查询运行记录失败: %w", err)
+	}
+	return run, nil
+}
+
+// ListOrganizeRuleRuns 按时间倒序返回规则的运行记录
+func (d *Database) ListOrganizeRuleRuns(ctx context.Context, ruleID int64, limit int) ([]OrganizeRuleRun, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	limit, _ = normalizePaging(limit, 0)
+
+	rows, err := d.conn.QueryContext(ctx,
+		`SELECT `+organizeRuleRunColumns+` FROM organize_rule_runs WHERE rule_id = ? ORDER BY id DESC LIMIT ?`,
+		ruleID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询运行记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []OrganizeRuleRun
+	for rows.Next() {
+		run, err := scanOrganizeRuleRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取运行记录失败: %w", err)
+		}
+		result = append(result, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历运行记录失败: %w", err)
+	}
+	return result, nil
+}
+
+func scanOrganizeRule(row rowScanner) (*OrganizeRule, error) {
+	var rule OrganizeRule
+	if err := row.Scan(
+		&rule.ID,
+		&rule.WorkspaceID,
+		&rule.Name,
+		&rule.SourceFolder,
+		&rule.Request,
+		&rule.IntervalMinutes,
+		&rule.OnNewFiles,
+		&rule.DryRun,
+		&rule.Enabled,
+		&rule.Snapshot,
+		&rule.LastRunAt,
+		&rule.LastStatus,
+		&rule.LastMessage,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func scanOrganizeRuleRun(row rowScanner) (*OrganizeRuleRun, error) {
+	var run OrganizeRuleRun
+	if err := row.Scan(
+		&run.ID,
+		&run.RuleID,
+		&run.Trigger,
+		&run.DryRun,
+		&run.Status,
+		&run.OperationID,
+		&run.MoveCount,
+		&run.ConflictCount,
+		&run.SkipCount,
+		&run.Message,
+		&run.Preview,
+		&run.StartedAt,
+		&run.FinishedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// organizeRuleNameTaken 判断工作区内是否已有同名规则（排除 excludeID）
+func (d *Database) organizeRuleNameTaken(ctx context.Context, workspaceID int64, name string, excludeID int64) (bool, error) {
+	var count int
+	if err := d.conn.QueryRowContext(ctx,
+		`SELECT COUNT(1) FROM organize_rules WHERE workspace_id = ? AND name = ? AND id != ?`,
+		workspaceID, name, excludeID,
+	).Scan(&count); err != nil {
+		return false, fmt.Errorf("查询自动整理规则失败: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,162 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 整理视图的链接方式
+const (
+	LinkSymlink  = "symlink"
+	LinkHardlink = "hardlink"
+)
+
+// OrganizeView 表示一个整理视图，Request 为序列化后的整理请求
+type OrganizeView struct {
+	ID          int64
+	WorkspaceID int64
+	Path        string // 视图目录的绝对路径
+	LinkType    string
+	Request     string
+	LinkCount   int
+	BuiltAt     sql.NullTime
+	CreatedAt   time.Time
+}
+
+const organizeViewColumns = `id, workspace_id, path, link_type, request, link_count, built_at, created_at`
+
+// CreateOrganizeView 新增整理视图记录（尚未生成）
+func (d *Database) CreateOrganizeView(ctx context.Context, workspaceID int64, path, linkType, request string) (*OrganizeView, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if workspaceID <= 0 {
+		return nil, errors.New("无效的工作区 ID")
+	}
+
+	var count int
+	if err := d.conn.QueryRowContext(ctx, `SELECT COUNT(1) FROM organize_views WHERE path = ?`, path).Scan(&count); err != nil {
+		return nil, fmt.Errorf("查询整理视图失败: %w", err)
+	}
+	if count > 0 {
+		return nil, errors.New("该目录已被其他整理视图使用")
+	}
+
+	result, err := d.conn.ExecContext(ctx,
+		`INSERT INTO organize_views(workspace_id, path, link_type, request, created_at) VALUES(?, ?, ?, ?, ?)`,
+		workspaceID, path, linkType, request, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("写入整理视图失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取整理视图 ID 失败: %w", err)
+	}
+	return d.GetOrganizeView(ctx, id)
+}
+
+// GetOrganizeView 读取单个整理视图
+func (d *Database) GetOrganizeView(ctx context.Context, id int64) (*OrganizeView, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return nil, errors.New("无效的整理视图 ID")
+	}
+
+	row := d.conn.QueryRowContext(ctx, `SELECT `+organizeViewColumns+` FROM organize_views WHERE id = ?`, id)
+	view, err := scanOrganizeView(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("整理视图不存在")
+		}
+		return nil, fmt.Errorf("查询整理视图失败: %w", err)
+	}
+	return view, nil
+}
+
+// ListOrganizeViews 返回工作区的全部整理视图
+func (d *Database) ListOrganizeViews(ctx context.Context, workspaceID int64) ([]OrganizeView, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+
+	rows, err := d.conn.QueryContext(ctx,
+		`SELECT `+organizeViewColumns+` FROM organize_views WHERE workspace_id = ? ORDER BY id`,
+		workspaceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询整理视图失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []OrganizeView
+	for rows.Next() {
+		view, err := scanOrganizeView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取整理视图失败: %w", err)
+		}
+		result = append(result, *view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历整理视图失败: %w", err)
+	}
+	return result, nil
+}
+
+// SetOrganizeViewBuilt 记录视图最近一次生成的结果
+func (d *Database) SetOrganizeViewBuilt(ctx context.Context, id int64, linkCount int) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+
+	if _, err := d.conn.ExecContext(ctx,
+		`UPDATE organize_views SET link_count = ?, built_at = ? WHERE id = ?`,
+		linkCount, time.Now().UTC(), id,
+	); err != nil {
+		return fmt.Errorf("更新整理视图失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteOrganizeView 删除整理视图记录
+func (d *Database) DeleteOrganizeView(ctx context.Context, id int64) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+
+	result, err := d.conn.ExecContext(ctx, `DELETE FROM organize_views WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除整理视图失败: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err == nil && rows == 0 {
+		return errors.New("整理视图不存在")
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrganizeView(row rowScanner) (*OrganizeView, error) {
+	var view OrganizeView
+	if err := row.Scan(
+		&view.ID,
+		&view.WorkspaceID,
+		&view.Path,
+		&view.LinkType,
+		&view.Request,
+		&view.LinkCount,
+		&view.BuiltAt,
+		&view.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
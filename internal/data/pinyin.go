@@ -0,0 +1,140 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// pinyinArgs 不带声调、多音字取常用读音
+var pinyinArgs = pinyin.NewArgs()
+
+// PinyinKeys 生成名称的全拼与首字母（均为小写且去掉空白），非汉字字符原样保留
+//
+// 例如 "年度报告 v2" 生成 "niandubaogaov2" 与 "ndbgv2"。
+func PinyinKeys(name string) (full, initials string) {
+	var fullBuf, initialsBuf strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if unicode.Is(unicode.Han, r) {
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 && py[0] != "" {
+				fullBuf.WriteString(py[0])
+				initialsBuf.WriteByte(py[0][0])
+				continue
+			}
+		}
+		fullBuf.WriteRune(r)
+		initialsBuf.WriteRune(r)
+	}
+	return fullBuf.String(), initialsBuf.String()
+}
+
+// filePinyinKeys 文件只对主文件名（不含扩展名）生成拼音，便于完全匹配
+func filePinyinKeys(name, fileType string) (full, initials string) {
+	if fileType != FileTypeDirectory {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return PinyinKeys(name)
+}
+
+// normalizePinyinQuery 将输入规范为拼音查询（小写、去掉空格与隔音符），
+// 仅由字母数字组成时才视为拼音查询
+func normalizePinyinQuery(query string) (string, bool) {
+	var b strings.Builder
+	for _, r := range strings.ToLower(query) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '\'':
+		default:
+			return "", false
+		}
+	}
+	if b.Len() == 0 {
+		return "", false
+	}
+	if !strings.ContainsFunc(b.String(), func(r rune) bool { return r >= 'a' && r <= 'z' }) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// 标签匹配程度，值越小越相关
+const (
+	TagMatchExact    = 0 // 名称、全拼或首字母完全一致
+	TagMatchPrefix   = 1 // 名称或全拼前缀
+	TagMatchInitials = 2 // 拼音首字母前缀
+	TagMatchContains = 3 // 名称、全拼或首字母包含关键字
+)
+
+// TagMatch 标签联想结果
+type TagMatch struct {
+	Tag  Tag
+	Rank int
+}
+
+// AutocompleteTags 按名称或拼音联想标签，排序：完全匹配 > 前缀 > 拼音首字母 > 包含
+func (d *Database) AutocompleteTags(ctx context.Context, query string, limit int) ([]TagMatch, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+
+	escaped := escapeLike(query)
+	rank := `CASE
+			WHEN t.name = ? COLLATE NOCASE THEN 0
+			WHEN t.name LIKE ? ESCAPE '\' THEN 1
+			ELSE 3 END`
+	where := `t.name LIKE ? ESCAPE '\'`
+	args := []any{query, escaped + "%"}
+	whereArgs := []any{"%" + escaped + "%"}
+	if py, ok := normalizePinyinQuery(query); ok {
+		rank = `CASE
+			WHEN t.name = ? COLLATE NOCASE OR t.name_pinyin = ? OR t.name_initials = ? THEN 0
+			WHEN t.name LIKE ? ESCAPE '\' OR t.name_pinyin LIKE ? THEN 1
+			WHEN t.name_initials LIKE ? THEN 2
+			ELSE 3 END`
+		where = `(t.name LIKE ? ESCAPE '\' OR t.name_pinyin LIKE ? OR t.name_initials LIKE ?)`
+		args = []any{query, py, py, escaped + "%", py + "%", py + "%"}
+		whereArgs = append(whereArgs, "%"+py+"%", "%"+py+"%")
+	}
+
+	rows, err := d.conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT t.id, t.name, t.color, t.parent_id, %s AS rank
+		FROM tags t
+		WHERE %s
+		ORDER BY rank, length(t.name), t.name COLLATE natural_zh
+		LIMIT ?`, rank, where),
+		append(append(args, whereArgs...), limit)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("联想标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []TagMatch
+	for rows.Next() {
+		var match TagMatch
+		if err := rows.Scan(&match.Tag.ID, &match.Tag.Name, &match.Tag.Color, &match.Tag.ParentID, &match.Rank); err != nil {
+			return nil, fmt.Errorf("读取标签记录失败: %w", err)
+		}
+		result = append(result, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历标签记录失败: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,192 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SavedSearch 表示一条保存的搜索（智能文件夹），Query 为序列化后的搜索参数
+type SavedSearch struct {
+	ID          int64
+	WorkspaceID sql.NullInt64 // 为空表示全局可用
+	Name        string
+	Query       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// errSavedSearchExists 同一范围内名称重复
+var errSavedSearchExists = errors.New("同名的保存搜索已存在")
+
+const savedSearchColumns = `id, workspace_id, name, query, created_at, updated_at`
+
+// scopeArg 将工作区 ID 转为写入参数，0 表示全局
+func scopeArg(workspaceID int64) any {
+	if workspaceID <= 0 {
+		return nil
+	}
+	return workspaceID
+}
+
+// CreateSavedSearch 新增保存的搜索，workspaceID 为 0 时为全局搜索
+func (d *Database) CreateSavedSearch(ctx context.Context, workspaceID int64, name, query string) (*SavedSearch, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("保存搜索的名称不能为空")
+	}
+
+	exists, err := d.savedSearchNameTaken(ctx, workspaceID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errSavedSearchExists
+	}
+
+	now := time.Now().UTC()
+	result, err := d.conn.ExecContext(ctx,
+		`INSERT INTO saved_searches(workspace_id, name, query, created_at, updated_at) VALUES(?, ?, ?, ?, ?)`,
+		scopeArg(workspaceID), name, query, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("写入保存搜索失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("获取保存搜索 ID 失败: %w", err)
+	}
+	return d.GetSavedSearch(ctx, id)
+}
+
+// UpdateSavedSearch 更新保存搜索的名称与查询，范围保持不变
+func (d *Database) UpdateSavedSearch(ctx context.Context, id int64, name, query string) (*SavedSearch, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("保存搜索的名称不能为空")
+	}
+
+	current, err := d.GetSavedSearch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := d.savedSearchNameTaken(ctx, current.WorkspaceID.Int64, name, id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errSavedSearchExists
+	}
+
+	if _, err := d.conn.ExecContext(ctx,
+		`UPDATE saved_searches SET name = ?, query = ?, updated_at = ? WHERE id = ?`,
+		name, query, time.Now().UTC(), id,
+	); err != nil {
+		return nil, fmt.Errorf("更新保存搜索失败: %w", err)
+	}
+	return d.GetSavedSearch(ctx, id)
+}
+
+// InsertSavedSearchIfAbsent 按范围与名称新增保存搜索；已有同名搜索时不做修改并返回 false（用于导入）
+func (d *Database) InsertSavedSearchIfAbsent(ctx context.Context, workspaceID int64, name, query string) (bool, error) {
+	if _, err := d.CreateSavedSearch(ctx, workspaceID, name, query); err != nil {
+		if errors.Is(err, errSavedSearchExists) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteSavedSearch 删除保存的搜索
+func (d *Database) DeleteSavedSearch(ctx context.Context, id int64) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return errors.New("无效的保存搜索 ID")
+	}
+
+	result, err := d.conn.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除保存搜索失败: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err == nil && rows == 0 {
+		return errors.New("保存搜索不存在")
+	}
+	return nil
+}
+
+// GetSavedSearch 读取单条保存搜索
+func (d *Database) GetSavedSearch(ctx context.Context, id int64) (*SavedSearch, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if id <= 0 {
+		return nil, errors.New("无效的保存搜索 ID")
+	}
+
+	row := d.conn.QueryRowContext(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches WHERE id = ?`, id)
+	var s SavedSearch
+	if err := row.Scan(&s.ID, &s.WorkspaceID, &s.Name, &s.Query, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("保存搜索不存在")
+		}
+		return nil, fmt.Errorf("查询保存搜索失败: %w", err)
+	}
+	return &s, nil
+}
+
+// ListSavedSearches 返回全局保存搜索以及指定工作区的保存搜索（workspaceID 为 0 时仅返回全局）
+func (d *Database) ListSavedSearches(ctx context.Context, workspaceID int64) ([]SavedSearch, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+
+	rows, err := d.conn.QueryContext(ctx, `
+		SELECT `+savedSearchColumns+`
+		FROM saved_searches
+		WHERE workspace_id IS NULL OR workspace_id = ?
+		ORDER BY workspace_id IS NOT NULL, name COLLATE natural_zh`,
+		workspaceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("查询保存搜索失败: %w", err)
+	}
+	defer rows.Close()
+
+	var result []SavedSearch
+	for rows.Next() {
+		var s SavedSearch
+		if err := rows.Scan(&s.ID, &s.WorkspaceID, &s.Name, &s.Query, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("读取保存搜索失败: %w", err)
+		}
+		result = append(result, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历保存搜索失败: %w", err)
+	}
+	return result, nil
+}
+
+// savedSearchNameTaken 判断同一范围内是否已有同名搜索（排除 excludeID）
+func (d *Database) savedSearchNameTaken(ctx context.Context, workspaceID int64, name string, excludeID int64) (bool, error) {
+	var count int
+	if err := d.conn.QueryRowContext(ctx,
+		`SELECT COUNT(1) FROM saved_searches WHERE IFNULL(workspace_id, 0) = ? AND name = ? AND id != ?`,
+		max(workspaceID, 0), name, excludeID,
+	).Scan(&count); err != nil {
+		return false, fmt.Errorf("查询保存搜索失败: %w", err)
+	}
+	return count > 0, nil
+}
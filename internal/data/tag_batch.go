@@ -0,0 +1,375 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 批量标签操作
+const (
+	TagBatchAdd    = "add"
+	TagBatchRemove = "remove"
+	TagBatchSet    = "set"
+	TagBatchClear  = "clear"
+)
+
+// batchChunkSize IN 查询每批的参数数量，避免超出 SQLite 参数上限
+const batchChunkSize = 500
+
+// FileTagChange 单个文件在批量操作前后的标签 ID（均已排序）
+type FileTagChange struct {
+	FileID int64
+	Before []int64
+	After  []int64
+}
+
+// BatchUpdateFileTags 在单个事务中为多个文件批量添加、移除或设置标签
+//
+// 文件必须属于指定工作区，标签必须存在，否则整个批次回滚；
+// 只返回标签实际发生变化的文件。
+func (d *Database) BatchUpdateFileTags(ctx context.Context, workspaceID int64, action string, fileIDs, tagIDs []int64) ([]FileTagChange, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if workspaceID <= 0 {
+		return nil, errors.New("缺少有效的工作区 ID")
+	}
+	fileIDs = uniqueIDs(fileIDs)
+	tagIDs = uniqueIDs(tagIDs)
+	if len(fileIDs) == 0 {
+		return nil, errors.New("至少需要选择一个文件")
+	}
+
+	var apply func(current []int64) []int64
+	switch action {
+	case TagBatchAdd:
+		if len(tagIDs) == 0 {
+			return nil, errors.New("至少需要选择一个标签")
+		}
+		apply = func(current []int64) []int64 {
+			return uniqueIDs(append(append([]int64{}, current...), tagIDs...))
+		}
+	case TagBatchRemove:
+		if len(tagIDs) == 0 {
+			return nil, errors.New("至少需要选择一个标签")
+		}
+		removed := make(map[int64]bool, len(tagIDs))
+		for _, id := range tagIDs {
+			removed[id] = true
+		}
+		apply = func(current []int64) []int64 {
+			result := make([]int64, 0, len(current))
+			for _, id := range current {
+				if !removed[id] {
+					result = append(result, id)
+				}
+			}
+			return result
+		}
+	case TagBatchSet:
+		apply = func([]int64) []int64 {
+			return tagIDs
+		}
+	case TagBatchClear:
+		tagIDs = nil
+		apply = func([]int64) []int64 {
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("无效的批量标签操作: %s", action)
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := checkFilesInWorkspaceTx(ctx, tx, workspaceID, fileIDs); err != nil {
+		return nil, err
+	}
+	if err := checkTagsExistTx(ctx, tx, tagIDs); err != nil {
+		return nil, err
+	}
+
+	current, err := fileTagIDsTx(ctx, tx, fileIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[int64][]int64, len(fileIDs))
+	for _, fileID := range fileIDs {
+		targets[fileID] = apply(current[fileID])
+	}
+	changes, err := setFileTagsTx(ctx, tx, current, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+	return changes, nil
+}
+
+// RestoreFileTags 在单个事务中将文件标签恢复为给定的标签集合（用于撤销批量操作）
+//
+// 已被删除的文件与标签会被忽略，返回实际发生变化的文件。
+func (d *Database) RestoreFileTags(ctx context.Context, targets map[int64][]int64) ([]FileTagChange, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	fileIDs := make([]int64, 0, len(targets))
+	var tagIDs []int64
+	for fileID, tags := range targets {
+		fileIDs = append(fileIDs, fileID)
+		tagIDs = append(tagIDs, tags...)
+	}
+	existingFiles, err := existingIDsTx(ctx, tx, "files", uniqueIDs(fileIDs))
+	if err != nil {
+		return nil, err
+	}
+	existingTags, err := existingIDsTx(ctx, tx, "tags", uniqueIDs(tagIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make(map[int64][]int64, len(targets))
+	for fileID, tags := range targets {
+		if !existingFiles[fileID] {
+			continue
+		}
+		kept := make([]int64, 0, len(tags))
+		for _, id := range tags {
+			if existingTags[id] {
+				kept = append(kept, id)
+			}
+		}
+		filtered[fileID] = uniqueIDs(kept)
+	}
+
+	ids := make([]int64, 0, len(filtered))
+	for fileID := range filtered {
+		ids = append(ids, fileID)
+	}
+	current, err := fileTagIDsTx(ctx, tx, uniqueIDs(ids))
+	if err != nil {
+		return nil, err
+	}
+	changes, err := setFileTagsTx(ctx, tx, current, filtered)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+	return changes, nil
+}
+
+// setFileTagsTx 将文件标签从 current 调整为 targets，只写入差异部分
+func setFileTagsTx(ctx context.Context, tx *sql.Tx, current, targets map[int64][]int64) ([]FileTagChange, error) {
+	insertStmt, err := tx.PrepareContext(ctx, `INSERT OR IGNORE INTO file_tags(file_id, tag_id) VALUES(?, ?)`)
+	if err != nil {
+		return nil, fmt.Errorf("准备写入语句失败: %w", err)
+	}
+	defer insertStmt.Close()
+	deleteStmt, err := tx.PrepareContext(ctx, `DELETE FROM file_tags WHERE file_id = ? AND tag_id = ?`)
+	if err != nil {
+		return nil, fmt.Errorf("准备删除语句失败: %w", err)
+	}
+	defer deleteStmt.Close()
+
+	fileIDs := make([]int64, 0, len(targets))
+	for fileID := range targets {
+		fileIDs = append(fileIDs, fileID)
+	}
+	sort.Slice(fileIDs, func(i, j int) bool { return fileIDs[i] < fileIDs[j] })
+
+	var changes []FileTagChange
+	for _, fileID := range fileIDs {
+		before := current[fileID]
+		after := uniqueIDs(targets[fileID])
+		added, removed := diffIDs(before, after)
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		for _, tagID := range removed {
+			if _, err := deleteStmt.ExecContext(ctx, fileID, tagID); err != nil {
+				return nil, fmt.Errorf("从文件移除标签失败: %w", err)
+			}
+		}
+		for _, tagID := range added {
+			if _, err := insertStmt.ExecContext(ctx, fileID, tagID); err != nil {
+				return nil, fmt.Errorf("添加标签到文件失败: %w", err)
+			}
+		}
+		changes = append(changes, FileTagChange{
+			FileID: fileID,
+			Before: append([]int64{}, before...),
+			After:  after,
+		})
+	}
+	return changes, nil
+}
+
+// fileTagIDsTx 读取文件当前的标签 ID（已排序），没有标签的文件不出现在结果中
+func fileTagIDsTx(ctx context.Context, tx *sql.Tx, fileIDs []int64) (map[int64][]int64, error) {
+	result := make(map[int64][]int64, len(fileIDs))
+	for _, chunk := range chunkIDs(fileIDs) {
+		placeholders, args := inClause(chunk)
+		rows, err := tx.QueryContext(ctx,
+			fmt.Sprintf(`SELECT file_id, tag_id FROM file_tags WHERE file_id IN (%s) ORDER BY file_id, tag_id`, placeholders),
+			args...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("查询文件标签失败: %w", err)
+		}
+		for rows.Next() {
+			var fileID, tagID int64
+			if err := rows.Scan(&fileID, &tagID); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("读取文件标签失败: %w", err)
+			}
+			result[fileID] = append(result[fileID], tagID)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("遍历文件标签失败: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// checkFilesInWorkspaceTx 确认所有文件都存在且属于指定工作区
+func checkFilesInWorkspaceTx(ctx context.Context, tx *sql.Tx, workspaceID int64, fileIDs []int64) error {
+	var found int
+	for _, chunk := range chunkIDs(fileIDs) {
+		placeholders, args := inClause(chunk)
+		var count int
+		if err := tx.QueryRowContext(ctx,
+			fmt.Sprintf(`SELECT COUNT(1) FROM files WHERE workspace_id = ? AND id IN (%s)`, placeholders),
+			append([]any{workspaceID}, args...)...,
+		).Scan(&count); err != nil {
+			return fmt.Errorf("校验文件失败: %w", err)
+		}
+		found += count
+	}
+	if found != len(fileIDs) {
+		return fmt.Errorf("有 %d 个文件不存在或不属于当前工作区", len(fileIDs)-found)
+	}
+	return nil
+}
+
+// checkTagsExistTx 确认所有标签都存在
+func checkTagsExistTx(ctx context.Context, tx *sql.Tx, tagIDs []int64) error {
+	existing, err := existingIDsTx(ctx, tx, "tags", tagIDs)
+	if err != nil {
+		return err
+	}
+	for _, id := range tagIDs {
+		if !existing[id] {
+			return fmt.Errorf("标签不存在: %d", id)
+		}
+	}
+	return nil
+}
+
+// existingIDsTx 返回指定表中存在的 ID
+func existingIDsTx(ctx context.Context, tx *sql.Tx, table string, ids []int64) (map[int64]bool, error) {
+	result := make(map[int64]bool, len(ids))
+	for _, chunk := range chunkIDs(ids) {
+		placeholders, args := inClause(chunk)
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT id FROM %s WHERE id IN (%s)`, table, placeholders), args...)
+		if err != nil {
+			return nil, fmt.Errorf("查询记录失败: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("读取记录失败: %w", err)
+			}
+			result[id] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("遍历记录失败: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// uniqueIDs 去重、去掉非法 ID 并升序排列
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// diffIDs 比较两个已排序的 ID 列表，返回新增与移除的部分
+func diffIDs(before, after []int64) (added, removed []int64) {
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case j >= len(after) || (i < len(before) && before[i] < after[j]):
+			removed = append(removed, before[i])
+			i++
+		case i >= len(before) || after[j] < before[i]:
+			added = append(added, after[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return added, removed
+}
+
+func chunkIDs(ids []int64) [][]int64 {
+	var chunks [][]int64
+	for start := 0; start < len(ids); start += batchChunkSize {
+		end := start + batchChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+	return chunks
+}
+
+func inClause(ids []int64) (string, []any) {
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", len(ids)), ","), args
+}
@@ -0,0 +1,264 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TagSnapshot 删除或合并前的标签快照，用于撤销时按原 ID 恢复
+type TagSnapshot struct {
+	Tag      Tag
+	ChildIDs []int64 // 直接子标签
+}
+
+// GetTag 根据 ID 读取标签
+func (d *Database) GetTag(ctx context.Context, id int64) (*Tag, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	return getTag(ctx, d.conn, id)
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getTag(ctx context.Context, q queryRower, id int64) (*Tag, error) {
+	if id <= 0 {
+		return nil, errors.New("无效的标签 ID")
+	}
+	var tag Tag
+	if err := q.QueryRowContext(ctx, `SELECT id, name, color, parent_id FROM tags WHERE id = ?`, id).
+		Scan(&tag.ID, &tag.Name, &tag.Color, &tag.ParentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("标签不存在")
+		}
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	return &tag, nil
+}
+
+// RenameTag 修改标签名称，返回修改前的标签
+func (d *Database) RenameTag(ctx context.Context, id int64, newName string) (*Tag, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, errors.New("标签名称不可为空")
+	}
+
+	before, err := d.GetTag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.Name == newName {
+		return before, nil
+	}
+
+	var taken bool
+	if err := d.conn.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM tags WHERE name = ? AND id != ?)`, newName, id,
+	).Scan(&taken); err != nil {
+		return nil, fmt.Errorf("检查标签名称失败: %w", err)
+	}
+	if taken {
+		return nil, fmt.Errorf("标签「%s」已存在", newName)
+	}
+
+	namePinyin, nameInitials := PinyinKeys(newName)
+	if _, err := d.conn.ExecContext(ctx,
+		`UPDATE tags SET name = ?, name_pinyin = ?, name_initials = ? WHERE id = ?`,
+		newName, namePinyin, nameInitials, id,
+	); err != nil {
+		return nil, fmt.Errorf("重命名标签失败: %w", err)
+	}
+	return before, nil
+}
+
+// MergeTags 将 source 标签合并到 target：带有 source 的文件改为带有 target，
+// source 的子标签改挂到 target 下，随后删除 source；返回 source 的快照与文件标签变化
+func (d *Database) MergeTags(ctx context.Context, sourceID, targetID int64) (*TagSnapshot, []FileTagChange, error) {
+	if sourceID == targetID {
+		return nil, nil, errors.New("不能将标签合并到自身")
+	}
+	return d.removeTag(ctx, sourceID, targetID)
+}
+
+// DeleteTagWithChanges 删除标签，返回删除前的快照与受影响文件的标签变化
+func (d *Database) DeleteTagWithChanges(ctx context.Context, id int64) (*TagSnapshot, []FileTagChange, error) {
+	return d.removeTag(ctx, id, 0)
+}
+
+// removeTag 删除标签，mergeInto 大于 0 时先把文件与子标签转移到该标签
+func (d *Database) removeTag(ctx context.Context, id, mergeInto int64) (*TagSnapshot, []FileTagChange, error) {
+	if d == nil || d.conn == nil {
+		return nil, nil, errors.New("数据库对象尚未初始化")
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	tag, err := getTag(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mergeInto > 0 {
+		if _, err := getTag(ctx, tx, mergeInto); err != nil {
+			return nil, nil, err
+		}
+	}
+	snapshot := &TagSnapshot{Tag: *tag}
+	if snapshot.ChildIDs, err = queryIDsTx(ctx, tx, `SELECT id FROM tags WHERE parent_id = ? ORDER BY id`, id); err != nil {
+		return nil, nil, err
+	}
+
+	fileIDs, err := queryIDsTx(ctx, tx, `SELECT file_id FROM file_tags WHERE tag_id = ? ORDER BY file_id`, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := fileTagIDsTx(ctx, tx, fileIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	targets := make(map[int64][]int64, len(fileIDs))
+	for _, fileID := range fileIDs {
+		after := make([]int64, 0, len(current[fileID])+1)
+		for _, tagID := range current[fileID] {
+			if tagID != id {
+				after = append(after, tagID)
+			}
+		}
+		if mergeInto > 0 {
+			after = append(after, mergeInto)
+		}
+		targets[fileID] = after
+	}
+	changes, err := setFileTagsTx(ctx, tx, current, targets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if mergeInto > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE tags SET parent_id = ? WHERE parent_id = ? AND id != ?`, mergeInto, id, mergeInto,
+		); err != nil {
+			return nil, nil, fmt.Errorf("转移子标签失败: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, id); err != nil {
+		return nil, nil, fmt.Errorf("删除标签失败: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+	return snapshot, changes, nil
+}
+
+// RestoreTag 按快照以原 ID 重新创建已删除的标签，并把仍存在的子标签挂回其下
+func (d *Database) RestoreTag(ctx context.Context, snapshot TagSnapshot) error {
+	if d == nil || d.conn == nil {
+		return errors.New("数据库对象尚未初始化")
+	}
+	tag := snapshot.Tag
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM tags WHERE id = ? OR name = ?)`, tag.ID, tag.Name,
+	).Scan(&exists); err != nil {
+		return fmt.Errorf("检查标签失败: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("标签「%s」已存在，无法恢复", tag.Name)
+	}
+
+	// 父标签可能已被删除
+	var parent any
+	if tag.ParentID.Valid {
+		if _, err := getTag(ctx, tx, tag.ParentID.Int64); err == nil {
+			parent = tag.ParentID.Int64
+		}
+	}
+	namePinyin, nameInitials := PinyinKeys(tag.Name)
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO tags(id, name, color, parent_id, name_pinyin, name_initials) VALUES(?, ?, ?, ?, ?, ?)`,
+		tag.ID, tag.Name, tag.Color, parent, namePinyin, nameInitials,
+	); err != nil {
+		return fmt.Errorf("恢复标签失败: %w", err)
+	}
+
+	for _, childID := range snapshot.ChildIDs {
+		if _, err := tx.ExecContext(ctx, `UPDATE tags SET parent_id = ? WHERE id = ?`, tag.ID, childID); err != nil {
+			return fmt.Errorf("恢复子标签失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// ListFileIDsByTag 返回带有指定标签的全部文件 ID
+func (d *Database) ListFileIDsByTag(ctx context.Context, tagID int64) ([]int64, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+
+	rows, err := d.conn.QueryContext(ctx, `SELECT file_id FROM file_tags WHERE tag_id = ? ORDER BY file_id`, tagID)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签文件失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("读取标签文件失败: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历标签文件失败: %w", err)
+	}
+	return ids, nil
+}
+
+func queryIDsTx(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("读取记录失败: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历记录失败: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,310 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 推荐来源
+const (
+	SuggestByCooccurrence = "cooccurrence" // 与文件现有标签经常一起出现
+	SuggestBySibling      = "sibling"      // 同一文件夹中的其他文件使用
+	SuggestBySimilarName  = "similar_name" // 名称相似的文件使用
+)
+
+// 各来源的权重：共现统计最可靠，同目录次之，名称相似只作补充
+var suggestWeights = map[string]float64{
+	SuggestByCooccurrence: 1.0,
+	SuggestBySibling:      0.8,
+	SuggestBySimilarName:  0.6,
+}
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+	// 名称相似匹配最多使用的关键字数量与参与统计的文件数量
+	maxNameTokens       = 5
+	maxSimilarNameFiles = 500
+)
+
+// SuggestReason 推荐理由，Count/Total 表示 Total 个相关文件中有 Count 个使用了该标签
+type SuggestReason struct {
+	Kind       string
+	Count      int64
+	Total      int64
+	RelatedTag *Tag   // 共现来源对应的现有标签
+	Keyword    string // 名称相似来源匹配的关键字
+}
+
+// TagScore 推荐的标签及其得分
+type TagScore struct {
+	Tag     Tag
+	Score   float64
+	Reasons []SuggestReason
+}
+
+// SuggestTags 根据标签共现、同目录文件与名称相似的文件为指定文件推荐标签（不含已有标签）
+//
+// 共现次数来自触发器增量维护的 tag_pairs/tag_stats，同目录与名称相似按需统计。
+// 每个来源的得分为使用比例乘以权重，同一标签多个来源的得分相加。
+func (d *Database) SuggestTags(ctx context.Context, fileID int64, limit int) ([]TagScore, error) {
+	if d == nil || d.conn == nil {
+		return nil, errors.New("数据库对象尚未初始化")
+	}
+	if limit <= 0 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	file, err := d.GetFileByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[int64]bool, len(file.Tags))
+	for _, tag := range file.Tags {
+		existing[tag.ID] = true
+	}
+
+	scores := make(map[int64]*TagScore)
+	add := func(tagID int64, reason SuggestReason) {
+		if existing[tagID] || reason.Total <= 0 || reason.Count <= 0 {
+			return
+		}
+		item, ok := scores[tagID]
+		if !ok {
+			item = &TagScore{Tag: Tag{ID: tagID}}
+			scores[tagID] = item
+		}
+		score := float64(reason.Count) / float64(reason.Total) * suggestWeights[reason.Kind]
+		// 同一来源只保留最强的理由，避免多个现有标签重复累加
+		for i, r := range item.Reasons {
+			if r.Kind != reason.Kind {
+				continue
+			}
+			prev := float64(r.Count) / float64(r.Total) * suggestWeights[r.Kind]
+			if score > prev {
+				item.Score += score - prev
+				item.Reasons[i] = reason
+			}
+			return
+		}
+		item.Score += score
+		item.Reasons = append(item.Reasons, reason)
+	}
+
+	if err := d.suggestByCooccurrence(ctx, file, add); err != nil {
+		return nil, err
+	}
+	if err := d.suggestBySibling(ctx, file, add); err != nil {
+		return nil, err
+	}
+	if err := d.suggestBySimilarName(ctx, file, add); err != nil {
+		return nil, err
+	}
+	if len(scores) == 0 {
+		return nil, nil
+	}
+
+	tags, err := d.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tagByID := make(map[int64]Tag, len(tags))
+	order := make(map[int64]int, len(tags))
+	for i, tag := range tags {
+		tagByID[tag.ID] = tag
+		order[tag.ID] = i
+	}
+
+	result := make([]TagScore, 0, len(scores))
+	for id, item := range scores {
+		tag, ok := tagByID[id]
+		if !ok {
+			continue
+		}
+		item.Tag = tag
+		for i := range item.Reasons {
+			if related := item.Reasons[i].RelatedTag; related != nil {
+				if t, ok := tagByID[related.ID]; ok {
+					item.Reasons[i].RelatedTag = &t
+				}
+			}
+		}
+		result = append(result, *item)
+	}
+	// ListTags 已按名称自然排序，得分相同时沿用该顺序
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return order[result[i].Tag.ID] < order[result[j].Tag.ID]
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// suggestByCooccurrence 现有标签 A 的文件中同时带有标签 B 的比例
+func (d *Database) suggestByCooccurrence(ctx context.Context, file *FileRecord, add func(int64, SuggestReason)) error {
+	if len(file.Tags) == 0 {
+		return nil
+	}
+
+	rows, err := d.conn.QueryContext(ctx, `
+		SELECT p.other_id, p.tag_id, p.file_count, s.file_count
+		FROM file_tags ft
+		JOIN tag_pairs p ON p.tag_id = ft.tag_id
+		JOIN tag_stats s ON s.tag_id = ft.tag_id
+		WHERE ft.file_id = ?`,
+		file.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("查询标签共现统计失败: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var candidate, related int64
+		var reason SuggestReason
+		if err := rows.Scan(&candidate, &related, &reason.Count, &reason.Total); err != nil {
+			return fmt.Errorf("读取标签共现统计失败: %w", err)
+		}
+		// 只有当前文件使用的标签不具备参考价值
+		reason.Total--
+		reason.Kind = SuggestByCooccurrence
+		reason.RelatedTag = &Tag{ID: related}
+		add(candidate, reason)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("遍历标签共现统计失败: %w", err)
+	}
+	return nil
+}
+
+// suggestBySibling 同一文件夹中其他普通文件使用各标签的比例
+func (d *Database) suggestBySibling(ctx context.Context, file *FileRecord, add func(int64, SuggestReason)) error {
+	parent := ParentPath(file.Path)
+
+	var total int64
+	if err := d.conn.QueryRowContext(ctx, `
+		SELECT COUNT(1) FROM files
+		WHERE workspace_id = ? AND parent_path = ? AND type = 'file' AND id != ?`,
+		file.WorkspaceID, parent, file.ID,
+	).Scan(&total); err != nil {
+		return fmt.Errorf("统计同目录文件失败: %w", err)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	rows, err := d.conn.QueryContext(ctx, `
+		SELECT ft.tag_id, COUNT(1)
+		FROM files f
+		JOIN file_tags ft ON ft.file_id = f.id
+		WHERE f.workspace_id = ? AND f.parent_path = ? AND f.type = 'file' AND f.id != ?
+		GROUP BY ft.tag_id`,
+		file.WorkspaceID, parent, file.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("统计同目录文件标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tagID int64
+		reason := SuggestReason{Kind: SuggestBySibling, Total: total}
+		if err := rows.Scan(&tagID, &reason.Count); err != nil {
+			return fmt.Errorf("读取同目录文件标签失败: %w", err)
+		}
+		add(tagID, reason)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("遍历同目录文件标签失败: %w", err)
+	}
+	return nil
+}
+
+// suggestBySimilarName 文件名包含相同关键字的其他文件使用各标签的比例，每个关键字单独统计
+func (d *Database) suggestBySimilarName(ctx context.Context, file *FileRecord, add func(int64, SuggestReason)) error {
+	// 文件名中可能已写入标签，现有标签名不作为关键字
+	tagNames := make(map[string]bool, len(file.Tags))
+	for _, tag := range file.Tags {
+		tagNames[strings.ToLower(tag.Name)] = true
+	}
+
+	for _, token := range nameTokens(file.Name, file.Type) {
+		if tagNames[strings.ToLower(token)] {
+			continue
+		}
+		rows, err := d.conn.QueryContext(ctx, `
+			WITH similar AS (
+				SELECT id FROM files
+				WHERE workspace_id = ? AND type = 'file' AND id != ? AND name LIKE ? ESCAPE '\'
+				LIMIT ?
+			)
+			SELECT ft.tag_id, COUNT(1), (SELECT COUNT(1) FROM similar)
+			FROM similar s
+			JOIN file_tags ft ON ft.file_id = s.id
+			GROUP BY ft.tag_id`,
+			file.WorkspaceID, file.ID, "%"+escapeLike(token)+"%", maxSimilarNameFiles,
+		)
+		if err != nil {
+			return fmt.Errorf("统计名称相似文件标签失败: %w", err)
+		}
+
+		for rows.Next() {
+			var tagID int64
+			reason := SuggestReason{Kind: SuggestBySimilarName, Keyword: token}
+			if err := rows.Scan(&tagID, &reason.Count, &reason.Total); err != nil {
+				rows.Close()
+				return fmt.Errorf("读取名称相似文件标签失败: %w", err)
+			}
+			add(tagID, reason)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("遍历名称相似文件标签失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// nameTokens 从文件名（不含扩展名）中拆出用于相似匹配的关键字
+//
+// 以空白、标点和数字分隔，忽略单个字符的片段，按长度优先保留前 maxNameTokens 个。
+func nameTokens(name, fileType string) []string {
+	if fileType != FileTypeDirectory {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	seen := make(map[string]bool, len(fields))
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		key := strings.ToLower(field)
+		if utf8.RuneCountInString(field) < 2 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		tokens = append(tokens, field)
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return utf8.RuneCountInString(tokens[i]) > utf8.RuneCountInString(tokens[j])
+	})
+	if len(tokens) > maxNameTokens {
+		tokens = tokens[:maxNameTokens]
+	}
+	return tokens
+}
@@ -0,0 +1,53 @@
+package data
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// 标签顺序决定文件名中嵌入标签的顺序，改变排序规则会让已有文件在重新扫描后被误判为需要重命名
+func TestTagOrderIgnoresCaseOnly(t *testing.T) {
+	ctx := context.Background()
+	db, workspaceID := openCursorTestDB(t, 1)
+	page, err := db.ListFiles(ctx, workspaceID, 1, 0)
+	if err != nil || len(page.Records) != 1 {
+		t.Fatalf("ListFiles = %+v, %v", page, err)
+	}
+	fileID := page.Records[0].ID
+
+	for _, name := range []string{"tag9", "b", "tag10", "A"} {
+		tag, err := db.CreateTag(ctx, name, "#000000", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AddTagToFile(ctx, fileID, tag.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const want = "A,b,tag10,tag9"
+
+	tags, err := db.ListTags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := joinTagNames(tags); got != want {
+		t.Errorf("ListTags order = %s, want %s", got, want)
+	}
+
+	byFile, err := db.getTagsForFiles(ctx, []int64{fileID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := joinTagNames(byFile[fileID]); got != want {
+		t.Errorf("getTagsForFiles order = %s, want %s", got, want)
+	}
+}
+
+func joinTagNames(tags []Tag) string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,189 @@
+// Package exifdate 从 JPEG 与 TIFF 类（包括多数相机 RAW）文件中读取 EXIF 拍摄时间
+package exifdate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 文件不含可识别的拍摄时间
+var ErrNotFound = errors.New("未找到拍摄时间")
+
+// 用到的 EXIF 标签
+const (
+	tagDateTime          = 0x0132 // IFD0：文件修改时间（没有拍摄时间时使用）
+	tagExifIFD           = 0x8769 // IFD0：Exif 子目录位置
+	tagDateTimeOriginal  = 0x9003 // Exif：拍摄时间
+	tagDateTimeDigitized = 0x9004 // Exif：数字化时间
+)
+
+const (
+	typeASCII   = 2
+	typeLong    = 4
+	maxEntries  = 512 // 单个目录的条目上限，防止损坏的文件导致大量读取
+	maxSegments = 64  // JPEG 中最多检查的段数
+	dateLayout  = "2006:01:02 15:04:05"
+)
+
+// Extensions 支持读取拍摄时间的扩展名（小写，含点）
+var Extensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".jpe": true,
+	".tif": true, ".tiff": true, ".dng": true,
+	".nef": true, ".nrw": true, ".cr2": true, ".arw": true,
+	".orf": true, ".rw2": true, ".pef": true, ".srw": true,
+}
+
+// CaptureTime 读取文件的拍摄时间（按拍摄地的本地时间解析，不做时区换算）
+func CaptureTime(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	var head [4]byte
+	if _, err := io.ReadFull(f, head[:]); err != nil {
+		return time.Time{}, ErrNotFound
+	}
+	switch {
+	case head[0] == 0xFF && head[1] == 0xD8:
+		exif, err := jpegExif(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return tiffCaptureTime(bytes.NewReader(exif))
+	case string(head[:]) == "II*\x00" || string(head[:]) == "MM\x00*":
+		return tiffCaptureTime(f)
+	default:
+		return time.Time{}, ErrNotFound
+	}
+}
+
+// jpegExif 依次读取 JPEG 的段，返回 APP1 Exif 段中的 TIFF 数据；r 位于 SOI 之后
+func jpegExif(r io.ReadSeeker) ([]byte, error) {
+	if _, err := r.Seek(2, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var marker [4]byte
+	for i := 0; i < maxSegments; i++ {
+		if _, err := io.ReadFull(r, marker[:]); err != nil || marker[0] != 0xFF {
+			return nil, ErrNotFound
+		}
+		// 图像数据开始后不会再有 EXIF
+		if marker[1] == 0xDA || marker[1] == 0xD9 {
+			return nil, ErrNotFound
+		}
+		length := int(binary.BigEndian.Uint16(marker[2:])) - 2
+		if length < 0 {
+			return nil, ErrNotFound
+		}
+		if marker[1] != 0xE1 {
+			if _, err := r.Seek(int64(length), io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return nil, ErrNotFound
+		}
+		if bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// tiffCaptureTime 解析 TIFF 结构，依次尝试拍摄时间、数字化时间与 IFD0 中的时间
+func tiffCaptureTime(r io.ReaderAt) (time.Time, error) {
+	var header [8]byte
+	if _, err := r.ReadAt(header[:], 0); err != nil {
+		return time.Time{}, ErrNotFound
+	}
+	var order binary.ByteOrder
+	switch string(header[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return time.Time{}, ErrNotFound
+	}
+
+	ifd0, err := readIFD(r, order, int64(order.Uint32(header[4:])))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if entry, ok := ifd0[tagExifIFD]; ok && entry.typ == typeLong {
+		if exif, err := readIFD(r, order, int64(order.Uint32(entry.value[:]))); err == nil {
+			for _, tag := range []uint16{tagDateTimeOriginal, tagDateTimeDigitized} {
+				if t, ok := entryTime(r, order, exif[tag]); ok {
+					return t, nil
+				}
+			}
+		}
+	}
+	if t, ok := entryTime(r, order, ifd0[tagDateTime]); ok {
+		return t, nil
+	}
+	return time.Time{}, ErrNotFound
+}
+
+// ifdEntry 目录条目，value 为原始的 4 字节值或偏移
+type ifdEntry struct {
+	typ   uint16
+	count uint32
+	value [4]byte
+}
+
+func readIFD(r io.ReaderAt, order binary.ByteOrder, offset int64) (map[uint16]ifdEntry, error) {
+	var countBuf [2]byte
+	if offset <= 0 {
+		return nil, ErrNotFound
+	}
+	if _, err := r.ReadAt(countBuf[:], offset); err != nil {
+		return nil, ErrNotFound
+	}
+	count := int(order.Uint16(countBuf[:]))
+	if count > maxEntries {
+		return nil, ErrNotFound
+	}
+	buf := make([]byte, count*12)
+	if _, err := r.ReadAt(buf, offset+2); err != nil {
+		return nil, ErrNotFound
+	}
+
+	entries := make(map[uint16]ifdEntry, count)
+	for i := 0; i < count; i++ {
+		raw := buf[i*12 : (i+1)*12]
+		entry := ifdEntry{typ: order.Uint16(raw[2:]), count: order.Uint32(raw[4:])}
+		copy(entry.value[:], raw[8:])
+		entries[order.Uint16(raw)] = entry
+	}
+	return entries, nil
+}
+
+// entryTime 读取 ASCII 类型的时间条目；全零或空白的时间视为不存在
+func entryTime(r io.ReaderAt, order binary.ByteOrder, entry ifdEntry) (time.Time, bool) {
+	if entry.typ != typeASCII || entry.count < uint32(len(dateLayout)) || entry.count > 64 {
+		return time.Time{}, false
+	}
+	buf := make([]byte, entry.count)
+	if _, err := r.ReadAt(buf, int64(order.Uint32(entry.value[:]))); err != nil {
+		return time.Time{}, false
+	}
+	value := strings.TrimRight(string(buf), "\x00 ")
+	if len(value) < len(dateLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(dateLayout, value[:len(dateLayout)], time.Local)
+	if err != nil || t.Year() < 1900 {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,297 @@
+package pathtemplate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// 占位符类型
+const (
+	KindLevel = "level" // {level1}：第 N 级标签目录
+	KindTag   = "tag"   // {tag:客户}：文件在该标签组下的子标签
+	KindName  = "name"  // {name}：不含扩展名的文件名
+	KindExt   = "ext"   // {ext}：扩展名（含点）
+	KindYear  = "year"  // {year}/{month}/{day}：修改时间
+	KindMonth = "month"
+	KindDay   = "day"
+	KindMtime = "mtime" // {mtime:2006-01}：按 Go 时间格式输出修改时间
+)
+
+// 目录名中不允许出现的字符（按最严格的 Windows 规则）
+const invalidChars = `<>:"|?*\`
+
+// Part 表示模板中的一段文本或一个占位符
+type Part struct {
+	Kind    string // 为空表示普通文本
+	Literal string
+	Arg     string // tag 的标签组名、mtime 的时间格式
+	Level   int    // level 的层级（从 1 开始）
+	Pos     int    // 在原始模板中的字节偏移
+	End     int
+}
+
+// Segment 表示路径中的一级（目录或文件名）
+type Segment struct {
+	Parts []Part
+}
+
+// Template 已解析的路径模板，最后一级为文件名
+type Template struct {
+	Source   string
+	Segments []Segment
+}
+
+// SyntaxError 描述模板错误，Offset/Length 以 UTF-16 单元计，便于前端直接定位
+type SyntaxError struct {
+	Offset  int
+	Length  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("路径模板第 %d 个字符处%s", e.Offset+1, e.Message)
+}
+
+// NewError 根据原始模板与字节区间生成错误
+func NewError(src string, pos, end int, msg string) *SyntaxError {
+	if pos > len(src) {
+		pos = len(src)
+	}
+	if end < pos {
+		end = pos
+	}
+	if end > len(src) {
+		end = len(src)
+	}
+	return &SyntaxError{
+		Offset:  utf16Len(src[:pos]),
+		Length:  utf16Len(src[pos:end]),
+		Message: msg,
+	}
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// Parse 解析路径模板，例如 {level1}/{year}/{name}{ext} 或 {tag:客户}/{mtime:2006-01}/{name}{ext}
+//
+// 以 / 分隔目录，最后一级为文件名且必须包含 {name}；{{ 与 }} 表示字面的花括号。
+func Parse(src string) (*Template, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, NewError(src, 0, len(src), "：模板不能为空")
+	}
+	if strings.HasPrefix(src, "/") {
+		return nil, NewError(src, 0, 1, "：模板必须是相对路径")
+	}
+
+	tmpl := &Template{Source: src}
+	seg := Segment{}
+	segStart := 0
+	var literal strings.Builder
+	litStart := 0
+	flush := func(end int) {
+		if literal.Len() > 0 {
+			seg.Parts = append(seg.Parts, Part{Literal: literal.String(), Pos: litStart, End: end})
+			literal.Reset()
+		}
+	}
+	closeSegment := func(end int) error {
+		flush(end)
+		if len(seg.Parts) == 0 {
+			return NewError(src, segStart, end, "：目录名不能为空")
+		}
+		if len(seg.Parts) == 1 && seg.Parts[0].Kind == "" {
+			if name := strings.TrimSpace(seg.Parts[0].Literal); name == "." || name == ".." {
+				return NewError(src, segStart, end, "：不能使用 . 或 .. 作为目录名")
+			}
+		}
+		tmpl.Segments = append(tmpl.Segments, seg)
+		seg = Segment{}
+		return nil
+	}
+
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '{' && i+1 < len(src) && src[i+1] == '{', c == '}' && i+1 < len(src) && src[i+1] == '}':
+			if literal.Len() == 0 {
+				litStart = i
+			}
+			literal.WriteByte(c)
+			i += 2
+		case c == '{':
+			flush(i)
+			end := strings.IndexByte(src[i:], '}')
+			if end < 0 {
+				return nil, NewError(src, i, len(src), "：花括号未闭合")
+			}
+			part, err := parsePlaceholder(src, i, i+end+1)
+			if err != nil {
+				return nil, err
+			}
+			seg.Parts = append(seg.Parts, part)
+			i += end + 1
+		case c == '}':
+			return nil, NewError(src, i, i+1, "：多余的右花括号，字面的 } 请写作 }}")
+		case c == '/':
+			if err := closeSegment(i); err != nil {
+				return nil, err
+			}
+			i++
+			segStart = i
+		default:
+			r, size := utf8.DecodeRuneInString(src[i:])
+			if strings.ContainsRune(invalidChars, r) || unicode.IsControl(r) {
+				return nil, NewError(src, i, i+size, fmt.Sprintf("：路径中不能包含字符 %q", r))
+			}
+			if literal.Len() == 0 {
+				litStart = i
+			}
+			literal.WriteString(src[i : i+size])
+			i += size
+		}
+	}
+	if err := closeSegment(len(src)); err != nil {
+		return nil, err
+	}
+
+	last := tmpl.Segments[len(tmpl.Segments)-1]
+	hasName := false
+	for _, part := range last.Parts {
+		hasName = hasName || part.Kind == KindName
+	}
+	if !hasName {
+		return nil, NewError(src, segStart, len(src), "：文件名部分需包含 {name}，避免文件重名")
+	}
+	return tmpl, nil
+}
+
+// parsePlaceholder 解析 src[pos:end] 处的 {...} 占位符
+func parsePlaceholder(src string, pos, end int) (Part, error) {
+	body := strings.TrimSpace(src[pos+1 : end-1])
+	key, arg, hasArg := strings.Cut(body, ":")
+	key = strings.ToLower(strings.TrimSpace(key))
+	arg = strings.TrimSpace(arg)
+	part := Part{Kind: key, Arg: arg, Pos: pos, End: end}
+
+	switch {
+	case key == KindTag || key == KindMtime:
+		if arg == "" {
+			return Part{}, NewError(src, pos, end, fmt.Sprintf("：{%s:…} 需要指定参数", key))
+		}
+		if strings.ContainsAny(arg, "/{") {
+			return Part{}, NewError(src, pos, end, "：参数中不能包含 / 或 {")
+		}
+		if key == KindMtime && (arg == "." || arg == "..") {
+			return Part{}, NewError(src, pos, end, "：时间格式不能是 . 或 ..")
+		}
+		return part, nil
+	case hasArg:
+		return Part{}, NewError(src, pos, end, fmt.Sprintf("：{%s} 不接受参数", key))
+	case key == KindName, key == KindExt, key == KindYear, key == KindMonth, key == KindDay:
+		return part, nil
+	case strings.HasPrefix(key, KindLevel):
+		n, err := strconv.Atoi(strings.TrimPrefix(key, KindLevel))
+		if err != nil || n < 1 {
+			return Part{}, NewError(src, pos, end, "：层级占位符应写作 {level1}、{level2}…")
+		}
+		part.Kind = KindLevel
+		part.Level = n
+		return part, nil
+	default:
+		return Part{}, NewError(src, pos, end, fmt.Sprintf("：未知的占位符 {%s}", body))
+	}
+}
+
+// Placeholders 返回模板中指定类型的占位符（按出现顺序）
+func (t *Template) Placeholders(kind string) []Part {
+	var result []Part
+	for _, seg := range t.Segments {
+		for _, part := range seg.Parts {
+			if part.Kind == kind {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// Values 渲染模板所需的数据
+type Values struct {
+	Levels    []string          // 各级标签目录名（已清理）
+	TagGroups map[string]string // 标签组名 -> 文件在该组下的标签名，缺失时为空
+	Name      string
+	Ext       string
+	ModTime   time.Time
+}
+
+// Render 渲染为以 / 分隔的相对路径；clean 用于清理来自标签与时间的目录名片段
+//
+// 缺少对应层级或标签组时返回缺失项名称（层级为 levelN），路径为空。
+// 渲染后为空、仅含空白或为 . 与 .. 的一级同样视为缺失（目录为「第 N 级目录名」，最后一级为「文件名」），
+// 因此结果不会越出目标目录。
+func (t *Template) Render(v Values, clean func(string) string) (string, []string) {
+	var missing []string
+	segments := make([]string, 0, len(t.Segments))
+	for i, seg := range t.Segments {
+		before := len(missing)
+		var b strings.Builder
+		for _, part := range seg.Parts {
+			switch part.Kind {
+			case "":
+				b.WriteString(part.Literal)
+			case KindName:
+				b.WriteString(v.Name)
+			case KindExt:
+				b.WriteString(v.Ext)
+			case KindLevel:
+				if part.Level > len(v.Levels) || v.Levels[part.Level-1] == "" {
+					missing = append(missing, fmt.Sprintf("%s%d", KindLevel, part.Level))
+					continue
+				}
+				b.WriteString(v.Levels[part.Level-1])
+			case KindTag:
+				value := v.TagGroups[part.Arg]
+				if value == "" {
+					missing = append(missing, part.Arg)
+					continue
+				}
+				b.WriteString(clean(value))
+			case KindYear:
+				b.WriteString(v.ModTime.Format("2006"))
+			case KindMonth:
+				b.WriteString(v.ModTime.Format("01"))
+			case KindDay:
+				b.WriteString(v.ModTime.Format("02"))
+			case KindMtime:
+				b.WriteString(clean(v.ModTime.Format(part.Arg)))
+			}
+		}
+		value := b.String()
+		if len(missing) == before && !validSegment(value) {
+			if i == len(t.Segments)-1 {
+				missing = append(missing, "文件名")
+			} else {
+				missing = append(missing, fmt.Sprintf("第 %d 级目录名", i+1))
+			}
+		}
+		segments = append(segments, value)
+	}
+	if len(missing) > 0 {
+		return "", missing
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// validSegment 判断渲染出的一级路径能否安全使用：非空白，且不是 . 或 ..
+func validSegment(value string) bool {
+	trimmed := strings.TrimSpace(value)
+	return trimmed != "" && trimmed != "." && trimmed != ".."
+}
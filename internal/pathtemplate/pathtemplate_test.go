@@ -0,0 +1,199 @@
+package pathtemplate
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func identity(s string) string { return s }
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		message string
+	}{
+		{"", "模板不能为空"},
+		{"   ", "模板不能为空"},
+		{"/abs/{name}", "必须是相对路径"},
+		{"{year}", "需包含 {name}"},
+		{"{name}/{year}", "需包含 {name}"},
+		{"a//{name}", "目录名不能为空"},
+		{"a/", "目录名不能为空"},
+		{"./{name}", "不能使用 . 或 .."},
+		{"../{name}", "不能使用 . 或 .."},
+		{"a/ .. /{name}", "不能使用 . 或 .."},
+		{"{mtime:..}/{name}", "时间格式不能是 . 或 .."},
+		{"{mtime:.}/{name}", "时间格式不能是 . 或 .."},
+		{"{mtime}/{name}", "需要指定参数"},
+		{"{tag:}/{name}", "需要指定参数"},
+		{"{tag:a/b}/{name}", "不能包含 / 或 {"},
+		{"{mtime:2006/01}/{name}", "不能包含 / 或 {"},
+		{"{year:x}/{name}", "不接受参数"},
+		{"{level0}/{name}", "层级占位符"},
+		{"{levelx}/{name}", "层级占位符"},
+		{"{foo}/{name}", "未知的占位符"},
+		{"{year/{name}", "未知的占位符"},
+		{"a/{name", "花括号未闭合"},
+		{"a}/{name}", "多余的右花括号"},
+		{"a:b/{name}", "不能包含字符"},
+		{`a\b/{name}`, "不能包含字符"},
+		{"a\tb/{name}", "不能包含字符"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want error containing %q", tt.src, tt.message)
+			}
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Fatalf("Parse(%q) error type %T, want *SyntaxError", tt.src, err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.src, err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestErrorOffsetUTF16(t *testing.T) {
+	tests := []struct {
+		src    string
+		offset int
+		length int
+	}{
+		{"客户/{foo}/{name}", 3, 5},
+		{"😀/{foo}/{name}", 3, 5}, // 😀 占两个 UTF-16 单元
+		{"{tag:客户}/a}/{name}", 10, 1},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("Parse(%q) error = %v, want *SyntaxError", tt.src, err)
+		}
+		if syntaxErr.Offset != tt.offset || syntaxErr.Length != tt.length {
+			t.Errorf("Parse(%q) offset/length = %d/%d, want %d/%d",
+				tt.src, syntaxErr.Offset, syntaxErr.Length, tt.offset, tt.length)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	values := Values{
+		Levels:    []string{"项目", "2024"},
+		TagGroups: map[string]string{"客户": "甲公司", "状态": ""},
+		Name:      "报告",
+		Ext:       ".pdf",
+		ModTime:   time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"{name}{ext}", "报告.pdf"},
+		{"{level1}/{level2}/{name}{ext}", "项目/2024/报告.pdf"},
+		{"{tag:客户}/{year}/{month}/{day}/{name}{ext}", "甲公司/2024/03/07/报告.pdf"},
+		{"{mtime:2006-01}/{name}{ext}", "2024-03/报告.pdf"},
+		{"{ LEVEL1 }/{ Name }{ext}", "项目/报告.pdf"},
+		{"归档 {year}/{name}-{day}{ext}", "归档 2024/报告-07.pdf"},
+		{"{{x}}/{name}{ext}", "{x}/报告.pdf"},
+		{"a.b/..x/{name}{ext}", "a.b/..x/报告.pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			tmpl, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.src, err)
+			}
+			got, missing := tmpl.Render(values, identity)
+			if len(missing) > 0 {
+				t.Fatalf("Render(%q) missing %v", tt.src, missing)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMissing(t *testing.T) {
+	base := Values{
+		Levels:    []string{"项目"},
+		TagGroups: map[string]string{"客户": "甲公司"},
+		Name:      "报告",
+		Ext:       ".pdf",
+		ModTime:   time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name    string
+		src     string
+		values  func(v *Values)
+		missing string
+	}{
+		{"missing level", "{level2}/{name}{ext}", nil, "level2"},
+		{"empty level", "{level1}/{name}{ext}", func(v *Values) { v.Levels = []string{""} }, "level1"},
+		{"missing tag group", "{tag:状态}/{name}{ext}", nil, "状态"},
+		{"tag value dotdot", "{tag:客户}/{name}{ext}", func(v *Values) { v.TagGroups["客户"] = ".." }, "第 1 级目录名"},
+		{"tag value dot", "a/{tag:客户}/{name}{ext}", func(v *Values) { v.TagGroups["客户"] = "." }, "第 2 级目录名"},
+		{"level dotdot", "{level1}/{name}{ext}", func(v *Values) { v.Levels = []string{".."} }, "第 1 级目录名"},
+		{"blank tag value", "{tag:客户}/{name}{ext}", func(v *Values) { v.TagGroups["客户"] = "  " }, "第 1 级目录名"},
+		{"empty ext segment", "{ext}/{name}", func(v *Values) { v.Ext = "" }, "第 1 级目录名"},
+		{"name dotdot", "a/{name}", func(v *Values) { v.Name = ".." }, "文件名"},
+		{"empty name", "a/{name}{ext}", func(v *Values) { v.Name, v.Ext = "", "" }, "文件名"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.src, err)
+			}
+			values := base
+			values.TagGroups = map[string]string{"客户": "甲公司"}
+			if tt.values != nil {
+				tt.values(&values)
+			}
+			got, missing := tmpl.Render(values, identity)
+			if got != "" {
+				t.Errorf("Render(%q) = %q, want empty path", tt.src, got)
+			}
+			if len(missing) != 1 || missing[0] != tt.missing {
+				t.Errorf("Render(%q) missing = %v, want [%s]", tt.src, missing, tt.missing)
+			}
+		})
+	}
+}
+
+func TestRenderAppliesClean(t *testing.T) {
+	tmpl, err := Parse("{tag:客户}/{name}{ext}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clean := func(s string) string { return strings.ReplaceAll(s, ":", "_") }
+	got, missing := tmpl.Render(Values{
+		TagGroups: map[string]string{"客户": "a:b"},
+		Name:      "x",
+		Ext:       ".txt",
+	}, clean)
+	if len(missing) > 0 || got != "a_b/x.txt" {
+		t.Errorf("Render = %q, %v, want a_b/x.txt", got, missing)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	tmpl, err := Parse("{tag:客户}/{level2}/{tag:项目}/{name}{ext}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var args []string
+	for _, part := range tmpl.Placeholders(KindTag) {
+		args = append(args, part.Arg)
+	}
+	if got := strings.Join(args, ","); got != "客户,项目" {
+		t.Errorf("tag placeholders = %s, want 客户,项目", got)
+	}
+	levels := tmpl.Placeholders(KindLevel)
+	if len(levels) != 1 || levels[0].Level != 2 {
+		t.Errorf("level placeholders = %+v, want level 2", levels)
+	}
+}
@@ -0,0 +1,351 @@
+package tagquery
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// 表达式限制，防止过深的嵌套或过长的查询拖慢数据库
+const (
+	maxDepth = 64
+	maxTags  = 200
+)
+
+// Node 表示标签查询表达式的语法树节点
+type Node interface {
+	node()
+}
+
+// Tag 表示一个标签名称
+type Tag struct {
+	Name string
+	Pos  int // 在原始查询中的字节偏移
+	End  int
+}
+
+// Not 表示取反
+type Not struct {
+	X Node
+}
+
+// And 表示所有子项都需满足
+type And struct {
+	Terms []Node
+}
+
+// Or 表示任一子项满足即可
+type Or struct {
+	Terms []Node
+}
+
+func (*Tag) node() {}
+func (*Not) node() {}
+func (*And) node() {}
+func (*Or) node()  {}
+
+// SyntaxError 描述查询语法错误，Offset/Length 以 UTF-16 单元计，便于前端直接定位
+type SyntaxError struct {
+	Offset  int
+	Length  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("标签查询第 %d 个字符处%s", e.Offset+1, e.Message)
+}
+
+// NewError 根据原始查询与字节区间生成语法错误
+func NewError(src string, pos, end int, msg string) *SyntaxError {
+	if pos > len(src) {
+		pos = len(src)
+	}
+	if end < pos {
+		end = pos
+	}
+	if end > len(src) {
+		end = len(src)
+	}
+	return &SyntaxError{
+		Offset:  utf16Len(src[:pos]),
+		Length:  utf16Len(src[pos:end]),
+		Message: msg,
+	}
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// Parse 解析标签查询，支持 AND / OR / NOT、括号分组与引号包裹的标签名
+//
+// 优先级：NOT > AND > OR；相邻的两个标签之间省略运算符时视为 AND。
+// 运算符不区分大小写，也可写作 & / | / !，括号支持全角形式。
+func Parse(src string) (Node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{src: src, tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, NewError(src, 0, len(src), "：查询不能为空")
+	}
+
+	node, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, NewError(src, tok.pos, tok.end, "：多余的右括号")
+		}
+		return nil, NewError(src, tok.pos, tok.end, "：缺少运算符")
+	}
+	if p.tagCount > maxTags {
+		return nil, NewError(src, 0, len(src), fmt.Sprintf("：标签数量超过上限 %d", maxTags))
+	}
+	return node, nil
+}
+
+// Tags 返回表达式中出现的全部标签（按出现顺序）
+func Tags(node Node) []*Tag {
+	var result []*Tag
+	var walk func(Node)
+	walk = func(n Node) {
+		switch v := n.(type) {
+		case *Tag:
+			result = append(result, v)
+		case *Not:
+			walk(v.X)
+		case *And:
+			for _, term := range v.Terms {
+				walk(term)
+			}
+		case *Or:
+			for _, term := range v.Terms {
+				walk(term)
+			}
+		}
+	}
+	walk(node)
+	return result
+}
+
+// Quote 将标签名转为带引号的查询片段，可安全拼接进表达式
+func Quote(name string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name)
+	return `"` + escaped + `"`
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokTag
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	end  int
+}
+
+// lex 将查询拆分为词法单元
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(' || r == '（':
+			tokens = append(tokens, token{kind: tokLParen, pos: i, end: i + size})
+			i += size
+		case r == ')' || r == '）':
+			tokens = append(tokens, token{kind: tokRParen, pos: i, end: i + size})
+			i += size
+		case r == '!':
+			tokens = append(tokens, token{kind: tokNot, pos: i, end: i + size})
+			i += size
+		case r == '&' || r == '|':
+			end := i + size
+			if end < len(src) && rune(src[end]) == r {
+				end++
+			}
+			kind := tokAnd
+			if r == '|' {
+				kind = tokOr
+			}
+			tokens = append(tokens, token{kind: kind, pos: i, end: end})
+			i = end
+		case r == '"':
+			tok, err := lexQuoted(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = tok.end
+		default:
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if unicode.IsSpace(r) || strings.ContainsRune("()（）\"&|", r) {
+					break
+				}
+				i += size
+			}
+			word := src[start:i]
+			kind := tokTag
+			switch strings.ToUpper(word) {
+			case "AND":
+				kind = tokAnd
+			case "OR":
+				kind = tokOr
+			case "NOT":
+				kind = tokNot
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: start, end: i})
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(src), end: len(src)})
+	return tokens, nil
+}
+
+// lexQuoted 读取引号包裹的标签名，支持 \" 与 \\ 转义
+func lexQuoted(src string, start int) (token, error) {
+	var b strings.Builder
+	i := start + 1
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src) && (src[i+1] == '"' || src[i+1] == '\\'):
+			b.WriteByte(src[i+1])
+			i += 2
+		case c == '"':
+			name := strings.TrimSpace(b.String())
+			if name == "" {
+				return token{}, NewError(src, start, i+1, "：标签名不能为空")
+			}
+			return token{kind: tokTag, text: name, pos: start, end: i + 1}, nil
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return token{}, NewError(src, start, len(src), "：引号未闭合")
+}
+
+type parser struct {
+	src      string
+	tokens   []token
+	idx      int
+	tagCount int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.idx]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.idx]
+	if tok.kind != tokEOF {
+		p.idx++
+	}
+	return tok
+}
+
+func (p *parser) parseOr(depth int) (Node, error) {
+	first, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	terms := []Node{first}
+	for p.peek().kind == tokOr {
+		p.next()
+		term, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &Or{Terms: terms}, nil
+}
+
+func (p *parser) parseAnd(depth int) (Node, error) {
+	first, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	terms := []Node{first}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokTag, tokNot, tokLParen:
+			// 省略运算符时视为 AND
+		default:
+			if len(terms) == 1 {
+				return first, nil
+			}
+			return &And{Terms: terms}, nil
+		}
+		term, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+}
+
+func (p *parser) parseUnary(depth int) (Node, error) {
+	if depth > maxDepth {
+		tok := p.peek()
+		return nil, NewError(p.src, tok.pos, tok.end, "：嵌套层级过深")
+	}
+
+	tok := p.next()
+	switch tok.kind {
+	case tokNot:
+		x, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	case tokTag:
+		p.tagCount++
+		return &Tag{Name: tok.text, Pos: tok.pos, End: tok.end}, nil
+	case tokLParen:
+		if p.peek().kind == tokRParen {
+			closing := p.peek()
+			return nil, NewError(p.src, tok.pos, closing.end, "：括号内不能为空")
+		}
+		inner, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != tokRParen {
+			return nil, NewError(p.src, tok.pos, tok.end, "：括号未闭合")
+		}
+		p.next()
+		return inner, nil
+	case tokEOF:
+		return nil, NewError(p.src, tok.pos, tok.end, "：表达式不完整，缺少标签")
+	case tokRParen:
+		return nil, NewError(p.src, tok.pos, tok.end, "：此处需要标签而不是右括号")
+	default:
+		return nil, NewError(p.src, tok.pos, tok.end, fmt.Sprintf("：运算符 %q 前缺少标签", p.src[tok.pos:tok.end]))
+	}
+}
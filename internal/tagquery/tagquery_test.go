@@ -0,0 +1,174 @@
+package tagquery
+
+import (
+	"strings"
+	"testing"
+)
+
+// format 将语法树还原为带括号的紧凑形式，便于断言结构
+func format(n Node) string {
+	switch v := n.(type) {
+	case *Tag:
+		return v.Name
+	case *Not:
+		return "!" + format(v.X)
+	case *And:
+		return "(" + join(v.Terms, " & ") + ")"
+	case *Or:
+		return "(" + join(v.Terms, " | ") + ")"
+	default:
+		return "?"
+	}
+}
+
+func join(terms []Node, sep string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = format(term)
+	}
+	return strings.Join(parts, sep)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"客户A", "客户A"},
+		{"a b", "(a & b)"},
+		{"a AND b AND c", "(a & b & c)"},
+		{"a OR b AND c", "(a | (b & c))"},
+		{"a AND b OR c", "((a & b) | c)"},
+		{"(a OR b) AND c", "((a | b) & c)"},
+		{"a && b || c", "((a & b) | c)"},
+		{"a & b | c", "((a & b) | c)"},
+		{"a and b or not c", "((a & b) | !c)"},
+		{"NOT a AND b", "(!a & b)"},
+		{"NOT (a OR b) c", "(!(a | b) & c)"},
+		{"!a !b", "(!a & !b)"},
+		{"NOT NOT a", "!!a"},
+		{"（a OR b）c", "((a | b) & c)"},
+		{`"客户 A" OR b`, "(客户 A | b)"},
+		{`"and"`, "and"},
+		{`"a\"b" "c\\d"`, `(a"b & c\d)`},
+		{`"  spaced  "`, "spaced"},
+		{"a(b)", "(a & b)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			node, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.src, err)
+			}
+			if got := format(node); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		message string
+	}{
+		{"", "不能为空"},
+		{"   ", "不能为空"},
+		{"a AND", "缺少标签"},
+		{"AND a", "前缺少标签"},
+		{"a OR OR b", "前缺少标签"},
+		{"NOT", "缺少标签"},
+		{"(a", "括号未闭合"},
+		{"a)", "多余的右括号"},
+		{"()", "括号内不能为空"},
+		{"a ()", "括号内不能为空"},
+		{")", "右括号"},
+		{`"abc`, "引号未闭合"},
+		{`""`, "标签名不能为空"},
+		{`" "`, "标签名不能为空"},
+		{strings.Repeat("(", maxDepth+2) + "a" + strings.Repeat(")", maxDepth+2), "嵌套层级过深"},
+		{strings.Repeat("NOT ", maxDepth+2) + "a", "嵌套层级过深"},
+		{strings.TrimSpace(strings.Repeat("t ", maxTags+1)), "标签数量超过上限"},
+	}
+	for _, tt := range tests {
+		name := tt.src
+		if len(name) > 20 {
+			name = name[:20] + "…"
+		}
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want error containing %q", tt.src, tt.message)
+			}
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Fatalf("Parse(%q) error type %T, want *SyntaxError", tt.src, err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.src, err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestParseDepthLimit(t *testing.T) {
+	nested := strings.Repeat("(", maxDepth) + "a" + strings.Repeat(")", maxDepth)
+	if _, err := Parse(nested); err != nil {
+		t.Fatalf("%d levels of nesting should be accepted: %v", maxDepth, err)
+	}
+	tags := strings.TrimSpace(strings.Repeat("t ", maxTags))
+	if _, err := Parse(tags); err != nil {
+		t.Fatalf("%d tags should be accepted: %v", maxTags, err)
+	}
+}
+
+func TestErrorOffsetUTF16(t *testing.T) {
+	tests := []struct {
+		src    string
+		offset int
+		length int
+	}{
+		{"标签 AND", 6, 0},     // 末尾缺少标签
+		{"标签 )", 3, 1},       // 多余的右括号
+		{"😀 AND OR b", 7, 2}, // 😀 占两个 UTF-16 单元
+		{`a "未闭合`, 2, 4},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("Parse(%q) error = %v, want *SyntaxError", tt.src, err)
+		}
+		if syntaxErr.Offset != tt.offset || syntaxErr.Length != tt.length {
+			t.Errorf("Parse(%q) offset/length = %d/%d, want %d/%d",
+				tt.src, syntaxErr.Offset, syntaxErr.Length, tt.offset, tt.length)
+		}
+	}
+}
+
+func TestQuoteRoundTrip(t *testing.T) {
+	names := []string{"客户A", "a b", `say "hi"`, `back\slash`, "AND", "NOT", "(x)", "a|b&c!", `\"`}
+	for _, name := range names {
+		node, err := Parse(Quote(name))
+		if err != nil {
+			t.Fatalf("Parse(Quote(%q)) error: %v", name, err)
+		}
+		tag, ok := node.(*Tag)
+		if !ok || tag.Name != name {
+			t.Errorf("Parse(Quote(%q)) = %s, want tag %q", name, format(node), name)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	node, err := Parse(`a OR NOT (b "c d") AND a`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tag := range Tags(node) {
+		names = append(names, tag.Name)
+	}
+	if got := strings.Join(names, ","); got != "a,b,c d,a" {
+		t.Errorf("Tags = %s, want a,b,c d,a", got)
+	}
+}
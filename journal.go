@@ -0,0 +1,439 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// 重命名类操作在日志中的动作名
+const (
+	renameActionRename    = "rename"        // 手动重命名
+	renameActionApplyTags = "apply_tags"    // 按标签重命名单个文件
+	renameActionFormat    = "format_rename" // 标签格式变化后批量重命名
+)
+
+// recordOperation 写入操作日志并返回记录 ID
+//
+// 调用时操作本身已经完成，写入失败只记录警告（仅影响撤销）。
+func (a *App) recordOperation(opType string, workspaceID int64, summary string, payload any) int64 {
+	raw, err := json.Marshal(payload)
+	if err == nil {
+		var id int64
+		if id, err = a.db.InsertOperation(a.ctx, workspaceID, opType, summary, string(raw)); err == nil {
+			return id
+		}
+	}
+	if a.logger != nil {
+		a.logger.Warn("写入操作日志失败", zap.String("type", opType), zap.String("summary", summary), zap.Error(err))
+	}
+	return 0
+}
+
+// ListOperations 返回当前工作区及与工作区无关的操作日志（最新在前）
+func (a *App) ListOperations(limit int) ([]api.OperationSummary, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	var workspaceID int64
+	if a.currentWorkspace != nil {
+		workspaceID = a.currentWorkspace.ID
+	}
+	ops, err := a.db.ListOperations(a.ctx, workspaceID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]api.OperationSummary, 0, len(ops))
+	for _, op := range ops {
+		result = append(result, toAPIOperation(op))
+	}
+	return result, nil
+}
+
+func toAPIOperation(op data.Operation) api.OperationSummary {
+	action := op.Type
+	var head struct {
+		Action string `json:"action"`
+	}
+	if err := json.Unmarshal([]byte(op.Payload), &head); err == nil && head.Action != "" {
+		action = head.Action
+	}
+
+	var workspaceID *int64
+	if op.WorkspaceID.Valid {
+		value := op.WorkspaceID.Int64
+		workspaceID = &value
+	}
+	return api.OperationSummary{
+		ID:          op.ID,
+		Type:        op.Type,
+		Action:      action,
+		Summary:     op.Summary,
+		Status:      op.Status,
+		WorkspaceID: workspaceID,
+		CanUndo:     op.Status == data.OperationApplied,
+		CanRedo:     op.Status == data.OperationUndone,
+		CreatedAt:   formatTime(op.CreatedAt),
+		UpdatedAt:   formatTime(op.UpdatedAt),
+	}
+}
+
+// Undo 撤销操作：先确认每个文件仍处于操作后的位置与标签，存在冲突时不做任何修改
+func (a *App) Undo(operationID int64) (*api.OperationReplayResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.replayOperation(operationID, true)
+}
+
+// Redo 重做已撤销的操作：先确认每个文件仍处于操作前的位置与标签，存在冲突时不做任何修改
+func (a *App) Redo(operationID int64) (*api.OperationReplayResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.replayOperation(operationID, false)
+}
+
+// replayOperation 按操作日志撤销（undo 为 true）或重做操作
+//
+// 已处于目标状态的文件直接跳过，因此部分失败后可以重复执行；全部成功后才更新操作状态。
+func (a *App) replayOperation(operationID int64, undo bool) (*api.OperationReplayResult, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	op, err := a.db.GetOperation(a.ctx, operationID)
+	if err != nil {
+		return nil, err
+	}
+	if op.Status == data.OperationRunning {
+		return nil, errors.New("操作尚未执行完成，请先继续或回滚")
+	}
+	next := data.OperationUndone
+	if undo && op.Status != data.OperationApplied {
+		return nil, errors.New("操作已撤销")
+	}
+	if !undo {
+		if op.Status != data.OperationUndone {
+			return nil, errors.New("操作尚未撤销，无需重做")
+		}
+		next = data.OperationApplied
+	}
+	if op.WorkspaceID.Valid && (a.currentWorkspace == nil || a.currentWorkspace.ID != op.WorkspaceID.Int64) {
+		return nil, errors.New("当前工作区与操作记录不一致，请先切换到原工作区")
+	}
+
+	result := &api.OperationReplayResult{OperationID: op.ID, Status: op.Status}
+	switch op.Type {
+	case data.OperationOrganize:
+		var payload api.OrganizeOperationPayload
+		if err := json.Unmarshal([]byte(op.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("解析整理记录失败: %w", err)
+		}
+		changes := make([]api.FileChangeRecord, 0, len(payload.Moves))
+		for _, move := range payload.Moves {
+			changes = append(changes, api.FileChangeRecord{FileID: move.FileID, From: move.From, To: move.To, Copied: move.Copied})
+		}
+		err = a.replayFileChanges(changes, undo, result, nil, nil)
+		if err == nil && len(result.Conflicts) == 0 && result.Failed == 0 {
+			result.RemovedFolders = a.replayOrganizeFolders(payload, undo)
+		}
+	case data.OperationTag, data.OperationRename:
+		var payload api.FileOperationPayload
+		if err := json.Unmarshal([]byte(op.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("解析操作记录失败: %w", err)
+		}
+		err = a.replayFileChanges(payload.Changes, undo, result, nil, nil)
+	case data.OperationTagEdit:
+		var payload api.TagEditPayload
+		if err := json.Unmarshal([]byte(op.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("解析标签操作记录失败: %w", err)
+		}
+		err = a.replayTagEdit(payload, undo, result)
+	default:
+		return nil, fmt.Errorf("不支持撤销的操作类型: %s", op.Type)
+	}
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("执行撤销/重做失败", zap.Int64("operation_id", op.ID), zap.Bool("undo", undo), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	switch {
+	case len(result.Conflicts) > 0:
+		result.Message = fmt.Sprintf("%d 处状态已在操作后发生变化，未做任何修改", len(result.Conflicts))
+	case result.Failed > 0:
+		result.Message = fmt.Sprintf("%d 个文件处理失败，可稍后重试", result.Failed)
+	default:
+		if err := a.db.SetOperationStatus(a.ctx, op.ID, next); err != nil {
+			return nil, err
+		}
+		result.Status = next
+	}
+
+	if a.logger != nil {
+		a.logger.Info("执行撤销/重做",
+			zap.Int64("operation_id", op.ID),
+			zap.Bool("undo", undo),
+			zap.Int("succeeded", result.Succeeded),
+			zap.Int("skipped", result.Skipped),
+			zap.Int("failed", result.Failed),
+			zap.Int("conflicts", len(result.Conflicts)),
+		)
+	}
+	return result, nil
+}
+
+// fileReplay 单个文件需要执行的撤销/重做步骤
+type fileReplay struct {
+	fileID   int64
+	setTags  bool
+	tags     []int64
+	move     bool
+	src, dst string
+}
+
+// replayFileChanges 检查并回放文件变化：标签在单个事务中恢复，随后逐个移动文件
+//
+// pre 在检查通过后、恢复标签前执行，post 在恢复标签后、移动文件前执行（用于标签本身的增删改）。
+func (a *App) replayFileChanges(changes []api.FileChangeRecord, undo bool, result *api.OperationReplayResult, pre, post func() error) error {
+	// 按执行顺序（撤销时相反）检查，前面步骤移走的文件所腾出的位置可以被后面的步骤使用
+	plans := make([]fileReplay, 0, len(changes))
+	vacated := make(map[string]bool)
+	for i := range changes {
+		change := changes[i]
+		if undo {
+			change = changes[len(changes)-1-i]
+		}
+		plan, conflict := a.checkFileChange(change, undo, vacated)
+		if conflict != nil {
+			result.Conflicts = append(result.Conflicts, *conflict)
+			continue
+		}
+		if !plan.setTags && !plan.move {
+			result.Skipped++
+			continue
+		}
+		if plan.move {
+			vacated[plan.src] = true
+		}
+		plans = append(plans, plan)
+	}
+	if len(result.Conflicts) > 0 {
+		return nil
+	}
+
+	if pre != nil {
+		if err := pre(); err != nil {
+			return err
+		}
+	}
+	targets := make(map[int64][]int64)
+	for _, plan := range plans {
+		if plan.setTags {
+			targets[plan.fileID] = plan.tags
+		}
+	}
+	if len(targets) > 0 {
+		if _, err := a.db.RestoreFileTags(a.ctx, targets); err != nil {
+			return err
+		}
+	}
+	if post != nil {
+		if err := post(); err != nil {
+			return err
+		}
+	}
+
+	for _, plan := range plans {
+		if !plan.move {
+			result.Succeeded++
+			continue
+		}
+		if err := a.moveRecordedFile(plan.fileID, plan.src, plan.dst); err != nil {
+			result.Failed++
+			if a.logger != nil {
+				a.logger.Warn("回放文件移动失败",
+					zap.Int64("file_id", plan.fileID),
+					zap.String("from", plan.src),
+					zap.String("to", plan.dst),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		result.Succeeded++
+	}
+	return nil
+}
+
+// checkFileChange 检查文件是否仍处于可回放的状态，返回需要执行的步骤或冲突
+//
+// vacated 为回放中先于本步骤移走的源路径，目标位置被其占用时不视为冲突。
+func (a *App) checkFileChange(change api.FileChangeRecord, undo bool, vacated map[string]bool) (fileReplay, *api.OperationConflict) {
+	plan := fileReplay{fileID: change.FileID}
+	if change.FileID <= 0 {
+		return a.checkUntrackedMove(change, undo, vacated)
+	}
+	file, err := a.db.GetFileByID(a.ctx, change.FileID)
+	if err != nil {
+		return plan, &api.OperationConflict{FileID: change.FileID, Path: change.To, Message: "文件记录已不存在"}
+	}
+	currentPath := filepath.ToSlash(file.Path)
+	conflict := func(msg string) *api.OperationConflict {
+		return &api.OperationConflict{FileID: file.ID, Path: currentPath, Message: msg}
+	}
+
+	if !sameIDs(change.Before, change.After) {
+		expected, target := change.After, change.Before
+		if !undo {
+			expected, target = change.Before, change.After
+		}
+		current := tagIDsOf(file.Tags)
+		switch {
+		case sameIDs(current, target):
+		case sameIDs(current, expected):
+			plan.setTags = true
+			plan.tags = target
+		default:
+			return plan, conflict("文件标签已在操作后被修改")
+		}
+	}
+
+	if change.From == "" || change.From == change.To {
+		return plan, nil
+	}
+	if a.currentWorkspace == nil || file.WorkspaceID != a.currentWorkspace.ID {
+		return plan, conflict("文件不属于当前工作区")
+	}
+	src, dst := change.To, change.From
+	if !undo {
+		src, dst = change.From, change.To
+	}
+	switch currentPath {
+	case dst:
+		if _, err := os.Stat(a.workspaceAbs(dst)); err != nil {
+			return plan, conflict("文件在磁盘上已不存在")
+		}
+	case src:
+		srcInfo, err := os.Stat(a.workspaceAbs(src))
+		if err != nil {
+			return plan, conflict("文件在磁盘上已不存在")
+		}
+		if dstInfo, err := os.Stat(a.workspaceAbs(dst)); err == nil && !os.SameFile(srcInfo, dstInfo) && !vacated[dst] {
+			return plan, conflict(fmt.Sprintf("目标位置已存在文件: %s", dst))
+		}
+		plan.move = true
+		plan.src, plan.dst = src, dst
+	default:
+		return plan, conflict(fmt.Sprintf("文件已不在预期位置: %s", src))
+	}
+	return plan, nil
+}
+
+// checkUntrackedMove 检查未收录到数据库的文件（例如整理时移入隔离区的文件），只按磁盘状态判断
+func (a *App) checkUntrackedMove(change api.FileChangeRecord, undo bool, vacated map[string]bool) (fileReplay, *api.OperationConflict) {
+	plan := fileReplay{}
+	src, dst := change.To, change.From
+	if !undo {
+		src, dst = change.From, change.To
+	}
+	conflict := func(msg string) *api.OperationConflict {
+		return &api.OperationConflict{Path: src, Message: msg}
+	}
+	if a.currentWorkspace == nil {
+		return plan, conflict("尚未选择工作区")
+	}
+
+	_, srcErr := os.Stat(a.workspaceAbs(src))
+	_, dstErr := os.Stat(a.workspaceAbs(dst))
+	switch {
+	case srcErr == nil && (dstErr != nil || vacated[dst]):
+		plan.move = true
+		plan.src, plan.dst = src, dst
+	case srcErr == nil:
+		return plan, conflict(fmt.Sprintf("目标位置已存在文件: %s", dst))
+	case dstErr == nil:
+		// 已在目标位置
+	default:
+		return plan, conflict("文件在磁盘上已不存在")
+	}
+	return plan, nil
+}
+
+// workspaceAbs 将当前工作区内以 / 分隔的相对路径转换为绝对路径
+func (a *App) workspaceAbs(relPath string) string {
+	return filepath.Join(a.currentWorkspace.Path, filepath.FromSlash(relPath))
+}
+
+// moveRecordedFile 将当前工作区内的文件从 from 移动到 to（均为相对路径）并同步数据库，目标已存在时拒绝覆盖
+func (a *App) moveRecordedFile(fileID int64, from, to string) error {
+	if a.currentWorkspace == nil {
+		return errors.New("尚未选择工作区")
+	}
+	_, err := a.moveFileInWorkspace(a.currentWorkspace.Path, fileID, from, to)
+	return err
+}
+
+// moveFileInWorkspace 在 root 工作区内移动文件并同步数据库，数据库更新失败时把文件移回原处；返回是否跨设备复制
+//
+// fileID 为 0 表示文件未收录到数据库，只移动磁盘上的文件。
+func (a *App) moveFileInWorkspace(root string, fileID int64, from, to string) (bool, error) {
+	srcAbs := filepath.Join(root, filepath.FromSlash(from))
+	dstAbs := filepath.Join(root, filepath.FromSlash(to))
+	srcInfo, err := os.Stat(srcAbs)
+	if err != nil {
+		return false, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if dstInfo, err := os.Stat(dstAbs); err == nil && !os.SameFile(srcInfo, dstInfo) {
+		return false, fmt.Errorf("目标位置已存在文件: %s", to)
+	}
+	if err := os.MkdirAll(filepath.Dir(dstAbs), 0o755); err != nil {
+		return false, fmt.Errorf("创建目标目录失败: %w", err)
+	}
+	copied, err := a.moveFile(srcAbs, dstAbs, to)
+	if err != nil {
+		return copied, fmt.Errorf("移动文件失败: %w", err)
+	}
+
+	if fileID <= 0 {
+		return copied, nil
+	}
+	if err := a.db.UpdateFileName(a.ctx, fileID, filepath.Base(dstAbs), filepath.ToSlash(to)); err != nil {
+		_, _ = a.moveFile(dstAbs, srcAbs, from)
+		return copied, fmt.Errorf("更新数据库失败: %w", err)
+	}
+	return copied, nil
+}
+
+// tagIDsOf 返回升序排列的标签 ID
+func tagIDsOf(tags []data.Tag) []int64 {
+	ids := make([]int64, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// sameIDs 比较两个已排序的 ID 列表是否一致（nil 与空列表视为相同）
+func sameIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tagexplorer/internal/data"
+)
+
+// latestOperationID 返回最近一条操作日志的 ID
+func latestOperationID(t *testing.T, a *App) int64 {
+	t.Helper()
+	ops, err := a.ListOperations(1)
+	if err != nil || len(ops) == 0 {
+		t.Fatalf("no operation recorded: %v", err)
+	}
+	return ops[0].ID
+}
+
+func TestUndoRedoRename(t *testing.T) {
+	a, root := newTestApp(t, "docs/notes.txt")
+	fileID := testFileID(t, a, "docs/notes.txt")
+
+	if err := a.RenameFile(fileID, "renamed.txt"); err != nil {
+		t.Fatal(err)
+	}
+	ops, err := a.ListOperations(10)
+	if err != nil || len(ops) != 1 {
+		t.Fatalf("ListOperations = %+v, %v, want one operation", ops, err)
+	}
+	op := ops[0]
+	if op.Type != data.OperationRename || !op.CanUndo || op.CanRedo {
+		t.Fatalf("operation = %+v, want an undoable rename", op)
+	}
+
+	steps := []struct {
+		name   string
+		replay func(int64) error
+		status string
+		path   string
+		gone   string
+	}{
+		{"undo", func(id int64) error { _, err := a.Undo(id); return err }, data.OperationUndone, "docs/notes.txt", "docs/renamed.txt"},
+		{"redo", func(id int64) error { _, err := a.Redo(id); return err }, data.OperationApplied, "docs/renamed.txt", "docs/notes.txt"},
+		{"undo again", func(id int64) error { _, err := a.Undo(id); return err }, data.OperationUndone, "docs/notes.txt", "docs/renamed.txt"},
+	}
+	for _, step := range steps {
+		if err := step.replay(op.ID); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		stored, err := a.db.GetOperation(a.ctx, op.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != step.status {
+			t.Errorf("%s: status = %s, want %s", step.name, stored.Status, step.status)
+		}
+		assertExists(t, root, step.path, true)
+		assertExists(t, root, step.gone, false)
+		if got := testFilePath(t, a, fileID); got != step.path {
+			t.Errorf("%s: database path = %s, want %s", step.name, got, step.path)
+		}
+	}
+
+	if _, err := a.Undo(op.ID); err == nil {
+		t.Error("undoing an undone operation should fail")
+	}
+}
+
+func TestUndoPreconditionConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		disturb func(t *testing.T, a *App, root string, fileID int64)
+		message string
+		// 冲突后磁盘上应当保持不变的文件（路径 -> 内容）与数据库中的路径
+		onDisk map[string]string
+		dbPath string
+	}{
+		{
+			name: "moved on disk",
+			disturb: func(t *testing.T, a *App, root string, fileID int64) {
+				if err := os.Rename(filepath.Join(root, "renamed.txt"), filepath.Join(root, "elsewhere.txt")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			message: "已不存在",
+			onDisk:  map[string]string{"elsewhere.txt": "notes.txt"},
+			dbPath:  "renamed.txt",
+		},
+		{
+			name: "renamed again",
+			disturb: func(t *testing.T, a *App, root string, fileID int64) {
+				if err := a.RenameFile(fileID, "third.txt"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			message: "已不在预期位置",
+			onDisk:  map[string]string{"third.txt": "notes.txt"},
+			dbPath:  "third.txt",
+		},
+		{
+			name: "original path taken",
+			disturb: func(t *testing.T, a *App, root string, fileID int64) {
+				writeTestFile(t, filepath.Join(root, "notes.txt"), "new")
+			},
+			message: "目标位置已存在文件",
+			onDisk:  map[string]string{"renamed.txt": "notes.txt", "notes.txt": "new"},
+			dbPath:  "renamed.txt",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, root := newTestApp(t, "notes.txt")
+			fileID := testFileID(t, a, "notes.txt")
+			if err := a.RenameFile(fileID, "renamed.txt"); err != nil {
+				t.Fatal(err)
+			}
+			opID := latestOperationID(t, a)
+			tt.disturb(t, a, root, fileID)
+
+			result, err := a.Undo(opID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Conflicts) != 1 || !strings.Contains(result.Conflicts[0].Message, tt.message) {
+				t.Fatalf("conflicts = %+v, want one containing %q", result.Conflicts, tt.message)
+			}
+			if result.Succeeded != 0 || result.Status != data.OperationApplied {
+				t.Errorf("result = %+v, want nothing done and status applied", result)
+			}
+
+			stored, err := a.db.GetOperation(a.ctx, opID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != data.OperationApplied {
+				t.Errorf("status = %s, want applied", stored.Status)
+			}
+			for name, want := range tt.onDisk {
+				if content, err := os.ReadFile(filepath.Join(root, name)); err != nil || string(content) != want {
+					t.Errorf("%s = %q, %v, want %q untouched", name, content, err, want)
+				}
+			}
+			if got := testFilePath(t, a, fileID); got != tt.dbPath {
+				t.Errorf("database path = %s, want %s", got, tt.dbPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tagexplorer/internal/api"
+)
+
+// 整理冲突策略
+const (
+	organizeConflictBlock      = "block"       // 存在冲突时拒绝执行（默认）
+	organizeConflictSkip       = "skip"        // 跳过冲突文件
+	organizeConflictSuffix     = "suffix"      // 自动改名为 name (2).ext
+	organizeConflictKeepNewer  = "keep_newer"  // 保留修改时间较新的文件，被替换的文件移入隔离区
+	organizeConflictKeepLarger = "keep_larger" // 保留较大的文件，被替换的文件移入隔离区
+	organizeConflictQuarantine = "quarantine"  // 总是替换，被替换的文件移入隔离区
+)
+
+// 冲突处理结果（OrganizePreviewItem.Resolution）
+const (
+	resolutionSkip       = "skip"       // 因冲突跳过
+	resolutionSuffix     = "suffix"     // 已改名
+	resolutionReplace    = "replace"    // 占据目标路径，原文件移入隔离区或被跳过
+	resolutionQuarantine = "quarantine" // 本文件被替换，移入隔离区
+)
+
+const defaultQuarantineFolder = "_隔离区"
+
+// organizeCandidate 待移动文件的比较依据
+type organizeCandidate struct {
+	index   int
+	modTime time.Time
+	size    int64
+}
+
+// organizeResolver 按冲突策略解决整理计划中的冲突；路径比较不区分大小写，兼容 Windows/macOS 的默认文件系统
+type organizeResolver struct {
+	app            *App
+	workspaceID    int64
+	root           string
+	strategy       string
+	quarantineRoot string // 隔离区目录（相对工作区）
+	quarantineDir  string // 本次整理使用的隔离区子目录
+
+	candidates  []organizeCandidate
+	byIndex     map[int]organizeCandidate
+	moving      map[string]bool // 本次整理会移走的源路径
+	targetUsed  map[string]int  // 已占用的目标路径 -> 条目下标（隔离区路径为 -1）
+	dirUsed     map[string]bool // 已占用目标路径的各级父目录
+	blockedDirs map[string]bool // 磁盘上与目标目录同名的文件
+}
+
+// newOrganizeResolver 创建冲突处理器，root 为目标路径所在的目录（工作区或整理视图）
+func newOrganizeResolver(a *App, workspaceID int64, strategy, quarantineFolder, root string) (*organizeResolver, error) {
+	switch strategy {
+	case "":
+		strategy = organizeConflictBlock
+	case organizeConflictBlock, organizeConflictSkip, organizeConflictSuffix,
+		organizeConflictKeepNewer, organizeConflictKeepLarger, organizeConflictQuarantine:
+	default:
+		return nil, fmt.Errorf("无效的冲突处理方式: %s", strategy)
+	}
+	folder := quarantineFolder
+	if folder == "" {
+		folder = defaultQuarantineFolder
+	}
+	if !validFolderName(folder) {
+		return nil, fmt.Errorf("隔离区目录名无效: %s", folder)
+	}
+
+	return &organizeResolver{
+		app:            a,
+		workspaceID:    workspaceID,
+		root:           root,
+		strategy:       strategy,
+		quarantineRoot: folder,
+		quarantineDir:  folder + "/" + time.Now().Format("20060102-150405"),
+		byIndex:        make(map[int]organizeCandidate),
+		moving:         make(map[string]bool),
+		targetUsed:     make(map[string]int),
+		dirUsed:        make(map[string]bool),
+		blockedDirs:    make(map[string]bool),
+	}, nil
+}
+
+// inQuarantine 判断文件是否位于隔离区内（隔离区内的文件不参与整理）
+func (r *organizeResolver) inQuarantine(relPath string) bool {
+	return strings.HasPrefix(strings.ToLower(filepath.ToSlash(relPath)), strings.ToLower(r.quarantineRoot)+"/")
+}
+
+func (r *organizeResolver) addCandidate(index int, item api.OrganizePreviewItem, modTime time.Time, size int64) {
+	c := organizeCandidate{index: index, modTime: modTime, size: size}
+	r.candidates = append(r.candidates, c)
+	r.byIndex[index] = c
+	r.moving[strings.ToLower(item.OriginalPath)] = true
+}
+
+// resolve 按文件顺序逐个检查冲突并应用策略
+func (r *organizeResolver) resolve(items []api.OrganizePreviewItem) error {
+	for _, c := range r.candidates {
+		if err := r.resolveItem(items, c); err != nil {
+			return err
+		}
+	}
+
+	// 被替换的文件本身也在预览中（例如已在目标位置）时，标明其去向
+	replaced := make(map[string]*api.OrganizePreviewItem)
+	for i := range items {
+		if items[i].Status == "move" && items[i].QuarantinePath != "" {
+			replaced[strings.ToLower(items[i].ReplacedPath)] = &items[i]
+		}
+	}
+	for i := range items {
+		by, ok := replaced[strings.ToLower(items[i].OriginalPath)]
+		if !ok || items[i].Status == "move" {
+			continue
+		}
+		items[i].Resolution = resolutionQuarantine
+		items[i].Message = fmt.Sprintf("将被 %s 替换，移入隔离区 %s", by.OriginalPath, by.QuarantinePath)
+	}
+	return nil
+}
+
+func (r *organizeResolver) resolveItem(items []api.OrganizePreviewItem, c organizeCandidate) error {
+	item := &items[c.index]
+	if msg := r.directoryConflict(item.TargetPath); msg != "" {
+		r.unresolvable(item, msg)
+		return nil
+	}
+	if holder, ok := r.targetUsed[strings.ToLower(item.TargetPath)]; ok {
+		return r.resolveInternal(items, c, holder)
+	}
+
+	info, err := os.Stat(r.abs(item.TargetPath))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("检查目标路径失败: %w", err)
+		}
+		r.claim(c.index, item.TargetPath)
+		return nil
+	}
+	// 大小写不敏感的文件系统上，仅改变大小写时目标即源文件本身
+	if srcInfo, err := os.Stat(r.abs(item.OriginalPath)); err == nil && os.SameFile(info, srcInfo) {
+		r.claim(c.index, item.TargetPath)
+		return nil
+	}
+	return r.resolveExisting(item, c, info)
+}
+
+// resolveInternal 处理与本次整理中其他文件目标相同的冲突
+func (r *organizeResolver) resolveInternal(items []api.OrganizePreviewItem, c organizeCandidate, holder int) error {
+	item := &items[c.index]
+	if holder < 0 {
+		r.unresolvable(item, "目标路径位于本次整理的隔离区")
+		return nil
+	}
+	switch r.strategy {
+	case organizeConflictSuffix:
+		return r.suffix(item, c.index)
+	case organizeConflictKeepNewer, organizeConflictKeepLarger:
+		other := &items[holder]
+		if !r.wins(c.modTime, c.size, r.byIndex[holder].modTime, r.byIndex[holder].size) {
+			r.skip(item, fmt.Sprintf("与 %s 冲突，保留了%s的文件", other.OriginalPath, r.keepLabel()))
+			return nil
+		}
+		r.takeOver(item, other)
+		r.skip(other, fmt.Sprintf("与 %s 冲突，保留了%s的文件", item.OriginalPath, r.keepLabel()))
+		r.targetUsed[strings.ToLower(item.TargetPath)] = c.index
+		return nil
+	case organizeConflictQuarantine:
+		other := &items[holder]
+		r.takeOver(item, other)
+		target, err := r.quarantinePath(other.TargetPath)
+		if err != nil {
+			return err
+		}
+		other.TargetPath = target
+		other.Resolution = resolutionQuarantine
+		other.Message = fmt.Sprintf("目标路径被 %s 替换，移入隔离区", item.OriginalPath)
+		r.targetUsed[strings.ToLower(item.TargetPath)] = c.index
+		return nil
+	default:
+		r.unresolvable(item, "目标路径与其他文件冲突")
+		return nil
+	}
+}
+
+// resolveExisting 处理目标位置已有文件的冲突
+func (r *organizeResolver) resolveExisting(item *api.OrganizePreviewItem, c organizeCandidate, occupant os.FileInfo) error {
+	if r.moving[strings.ToLower(item.TargetPath)] {
+		r.unresolvable(item, "目标位置的文件也将在本次整理中移动，请执行后再次整理")
+		return nil
+	}
+
+	switch r.strategy {
+	case organizeConflictSuffix:
+		return r.suffix(item, c.index)
+	case organizeConflictKeepNewer, organizeConflictKeepLarger, organizeConflictQuarantine:
+		if occupant.IsDir() {
+			r.unresolvable(item, "目标位置是同名目录")
+			return nil
+		}
+		if r.strategy != organizeConflictQuarantine && !r.wins(c.modTime, c.size, occupant.ModTime(), occupant.Size()) {
+			r.skip(item, fmt.Sprintf("目标位置已有同名文件，保留了%s的文件", r.keepLabel()))
+			return nil
+		}
+		quarantine, err := r.quarantinePath(item.TargetPath)
+		if err != nil {
+			return err
+		}
+		fileID, err := r.app.db.GetFileIDByPath(r.app.ctx, r.workspaceID, item.TargetPath)
+		if err != nil {
+			return err
+		}
+		item.Resolution = resolutionReplace
+		item.ReplacedPath = item.TargetPath
+		item.ReplacedFileID = fileID
+		item.QuarantinePath = quarantine
+		item.Message = "目标位置的同名文件将移入隔离区"
+		r.claim(c.index, item.TargetPath)
+		return nil
+	default:
+		r.unresolvable(item, "目标位置已有同名文件")
+		return nil
+	}
+}
+
+// directoryConflict 检查目标路径与其他目录、文件在目录层面的冲突，这类冲突无法通过策略解决
+func (r *organizeResolver) directoryConflict(target string) string {
+	if r.dirUsed[strings.ToLower(target)] {
+		return "目标路径与其他文件的目标目录同名"
+	}
+	for _, dir := range parentDirs(target) {
+		key := strings.ToLower(dir)
+		if _, ok := r.targetUsed[key]; ok {
+			return "目标目录与其他文件的目标路径同名"
+		}
+		blocked, ok := r.blockedDirs[key]
+		if !ok {
+			info, err := os.Stat(r.abs(dir))
+			blocked = err == nil && !info.IsDir()
+			r.blockedDirs[key] = blocked
+		}
+		if blocked {
+			return "目标目录与已有文件同名"
+		}
+	}
+	return ""
+}
+
+// suffix 在目标目录中为文件寻找 name (2).ext 形式的可用名称
+func (r *organizeResolver) suffix(item *api.OrganizePreviewItem, index int) error {
+	candidate, err := r.freePath(item.TargetPath)
+	if err != nil {
+		return err
+	}
+	item.TargetPath = candidate
+	item.Resolution = resolutionSuffix
+	item.Message = fmt.Sprintf("目标位置已有同名文件，改名为 %s", path.Base(candidate))
+	if strings.EqualFold(candidate, item.OriginalPath) {
+		item.Status = "already_in_place"
+	}
+	r.claim(index, candidate)
+	return nil
+}
+
+// quarantinePath 返回被替换文件在隔离区中的位置，并占用该路径
+func (r *organizeResolver) quarantinePath(target string) (string, error) {
+	candidate, err := r.freePath(r.quarantineDir + "/" + target)
+	if err != nil {
+		return "", err
+	}
+	r.claim(-1, candidate)
+	return candidate, nil
+}
+
+// freePath 返回 relPath 本身或其 name (n).ext 变体中第一个未被占用的路径
+func (r *organizeResolver) freePath(relPath string) (string, error) {
+	dir, base := path.Split(relPath)
+	ext := path.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	for n := 1; n < 10000; n++ {
+		candidate := relPath
+		if n > 1 {
+			candidate = dir + fmt.Sprintf("%s (%d)%s", name, n, ext)
+		}
+		key := strings.ToLower(candidate)
+		if _, ok := r.targetUsed[key]; ok || r.dirUsed[key] || r.moving[key] {
+			continue
+		}
+		if _, err := os.Lstat(r.abs(candidate)); err == nil {
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("检查目标路径失败: %w", err)
+		}
+		return candidate, nil
+	}
+	return "", fmt.Errorf("无法为 %s 找到可用的文件名", relPath)
+}
+
+// takeOver 让 item 接替 other 占据的目标路径，连同 other 对磁盘上原有文件的替换
+func (r *organizeResolver) takeOver(item, other *api.OrganizePreviewItem) {
+	item.Resolution = resolutionReplace
+	item.ReplacedPath = other.ReplacedPath
+	item.ReplacedFileID = other.ReplacedFileID
+	item.QuarantinePath = other.QuarantinePath
+	item.Message = fmt.Sprintf("与 %s 冲突，替换该文件", other.OriginalPath)
+	other.ReplacedPath = ""
+	other.ReplacedFileID = 0
+	other.QuarantinePath = ""
+}
+
+func (r *organizeResolver) unresolvable(item *api.OrganizePreviewItem, msg string) {
+	if r.strategy == organizeConflictBlock {
+		item.Status = "conflict"
+		item.Message = msg
+		return
+	}
+	r.skip(item, msg)
+}
+
+func (r *organizeResolver) skip(item *api.OrganizePreviewItem, msg string) {
+	item.Status = "skip_conflict"
+	item.Resolution = resolutionSkip
+	item.Message = msg
+}
+
+// wins 判断待移动文件是否优于已占据目标的文件，相同时保留已有文件
+func (r *organizeResolver) wins(modTime time.Time, size int64, otherModTime time.Time, otherSize int64) bool {
+	if r.strategy == organizeConflictKeepLarger {
+		return size > otherSize
+	}
+	return modTime.After(otherModTime)
+}
+
+func (r *organizeResolver) keepLabel() string {
+	if r.strategy == organizeConflictKeepLarger {
+		return "较大"
+	}
+	return "较新"
+}
+
+func (r *organizeResolver) claim(index int, target string) {
+	r.targetUsed[strings.ToLower(target)] = index
+	for _, dir := range parentDirs(target) {
+		r.dirUsed[strings.ToLower(dir)] = true
+	}
+}
+
+func (r *organizeResolver) abs(relPath string) string {
+	return filepath.Join(r.root, filepath.FromSlash(relPath))
+}
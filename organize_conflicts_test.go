@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"tagexplorer/internal/api"
+)
+
+func TestQuarantineUndoRestoresFiles(t *testing.T) {
+	a, root := newTestApp(t, "report.txt")
+	fileID := testFileID(t, a, "report.txt")
+	tag, err := a.CreateTag("客户甲", "#000000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.AddTagToFile(fileID, tag.ID); err != nil {
+		t.Fatal(err)
+	}
+	source := testFilePath(t, a, fileID)
+
+	req := api.OrganizeRequest{
+		Levels:           []api.OrganizeLevel{{TagIDs: []int64{tag.ID}}},
+		ConflictStrategy: "quarantine",
+	}
+	preview, err := a.PreviewOrganize(req)
+	if err != nil || len(preview.Items) != 1 {
+		t.Fatalf("PreviewOrganize = %+v, %v, want one item", preview, err)
+	}
+	target := preview.Items[0].TargetPath
+
+	// 扫描之后才出现在目标位置的文件，数据库中没有记录
+	writeTestFile(t, filepath.Join(root, filepath.FromSlash(target)), "occupant")
+
+	result, err := a.ExecuteOrganize(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := result.Preview.Items[0]
+	if item.Status != "move" || item.ReplacedFileID != 0 || item.QuarantinePath == "" {
+		t.Fatalf("item = %+v, want a move replacing an untracked file", item)
+	}
+	quarantineDir := result.Preview.QuarantineDir
+	if path.Dir(quarantineDir) != defaultQuarantineFolder {
+		t.Fatalf("quarantine dir = %q, want a folder under %s", quarantineDir, defaultQuarantineFolder)
+	}
+	assertExists(t, root, item.QuarantinePath, true)
+	assertExists(t, root, source, false)
+
+	undo, err := a.UndoOrganize(result.OperationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo.Failed != 0 {
+		t.Fatalf("undo = %+v, want no failures", undo)
+	}
+
+	for name, want := range map[string]string{source: "report.txt", target: "occupant"} {
+		if content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name))); err != nil || string(content) != want {
+			t.Errorf("%s = %q, %v, want %q restored", name, content, err, want)
+		}
+	}
+	if got := testFilePath(t, a, fileID); got != source {
+		t.Errorf("database path = %s, want %s", got, source)
+	}
+	assertExists(t, root, quarantineDir, false)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// PreviewFlatten 预览展平：把若干文件夹中的文件移动到同一个文件夹
+func (a *App) PreviewFlatten(req api.FlattenRequest) (*api.OrganizePreview, error) {
+	return a.buildFlattenPlan(req)
+}
+
+// ExecuteFlatten 执行展平，与整理一样写入操作日志，可撤销与重做
+func (a *App) ExecuteFlatten(req api.FlattenRequest) (*api.OrganizeResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	plan, err := a.buildFlattenPlan(req)
+	if err != nil {
+		return nil, err
+	}
+	return a.executeOrganizePlan(a.currentWorkspace, plan, organizeRun{action: organizeActionFlatten, removeEmpty: req.RemoveEmptyFolders})
+}
+
+// ListOrganizeCreatedFolders 返回当前工作区已执行的整理所创建、且仍然存在的文件夹（展平的候选）
+func (a *App) ListOrganizeCreatedFolders() ([]string, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	ops, err := a.db.ListOperations(a.ctx, a.currentWorkspace.ID, 2000)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, op := range ops {
+		if op.Type != data.OperationOrganize || op.Status != data.OperationApplied {
+			continue
+		}
+		var payload api.OrganizeOperationPayload
+		if err := json.Unmarshal([]byte(op.Payload), &payload); err != nil {
+			continue
+		}
+		for _, dir := range payload.CreatedDirs {
+			if !seen[dir] && dirExists(a.workspaceAbs(dir)) {
+				seen[dir] = true
+				result = append(result, dir)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// buildFlattenPlan 生成展平计划（不触磁盘），冲突按请求的策略解决，预览即为实际执行的内容
+func (a *App) buildFlattenPlan(req api.FlattenRequest) (*api.OrganizePreview, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+	if len(req.Folders) == 0 {
+		return nil, errors.New("至少选择一个文件夹")
+	}
+
+	target, err := cleanWorkspaceDir(req.Target)
+	if err != nil {
+		return nil, fmt.Errorf("目标文件夹无效: %w", err)
+	}
+	folders := make([]string, 0, len(req.Folders))
+	for _, raw := range req.Folders {
+		folder, err := cleanWorkspaceDir(raw)
+		if err != nil || folder == "" {
+			return nil, fmt.Errorf("文件夹无效: %s", raw)
+		}
+		if !dirExists(a.workspaceAbs(folder)) {
+			return nil, fmt.Errorf("文件夹不存在: %s", folder)
+		}
+		if target == folder || strings.HasPrefix(target, folder+"/") {
+			return nil, fmt.Errorf("目标文件夹不能位于要展平的文件夹中: %s", folder)
+		}
+		folders = append(folders, folder)
+	}
+
+	resolver, err := newOrganizeResolver(a, a.currentWorkspace.ID, req.ConflictStrategy, req.QuarantineFolder, a.currentWorkspace.Path)
+	if err != nil {
+		return nil, err
+	}
+	plan := &api.OrganizePreview{
+		Items:    make([]api.OrganizePreviewItem, 0),
+		Summary:  api.OrganizeSummary{},
+		BasePath: a.currentWorkspace.Path,
+	}
+
+	const batchSize = 500
+	offset := 0
+	for {
+		page, err := a.db.ListFiles(a.ctx, a.currentWorkspace.ID, batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("获取文件列表失败: %w", err)
+		}
+		if len(page.Records) == 0 {
+			break
+		}
+
+		for _, file := range page.Records {
+			relPath := filepath.ToSlash(file.Path)
+			if file.Type != data.FileTypeRegular || resolver.inQuarantine(relPath) || !withinAnyFolder(relPath, folders) {
+				continue
+			}
+
+			item := api.OrganizePreviewItem{
+				FileID:       file.ID,
+				OriginalPath: relPath,
+				TargetPath:   path.Join(target, path.Base(relPath)),
+				Status:       "move",
+			}
+			for _, tag := range file.Tags {
+				item.Tags = append(item.Tags, tag.Name)
+			}
+			plan.Items = append(plan.Items, item)
+			resolver.addCandidate(len(plan.Items)-1, item, file.ModTime, file.Size)
+		}
+
+		if len(page.Records) < batchSize {
+			break
+		}
+		offset += len(page.Records)
+	}
+
+	if err := resolver.resolve(plan.Items); err != nil {
+		return nil, err
+	}
+	summarizeOrganizePlan(plan)
+	return plan, nil
+}
+
+// cleanWorkspaceDir 规范化相对工作区的文件夹路径，空字符串表示根目录；拒绝绝对路径与越出工作区的路径
+func cleanWorkspaceDir(dir string) (string, error) {
+	dir = filepath.ToSlash(strings.TrimSpace(dir))
+	if dir == "" {
+		return "", nil
+	}
+	if path.IsAbs(dir) || filepath.IsAbs(dir) {
+		return "", errors.New("需要相对工作区的路径")
+	}
+	cleaned := path.Clean(dir)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.New("路径不能越出工作区")
+	}
+	if cleaned == "." {
+		return "", nil
+	}
+	return cleaned, nil
+}
+
+// withinAnyFolder 判断相对路径是否位于任一文件夹（含子文件夹）中
+func withinAnyFolder(relPath string, folders []string) bool {
+	for _, folder := range folders {
+		if strings.HasPrefix(relPath, folder+"/") {
+			return true
+		}
+	}
+	return false
+}
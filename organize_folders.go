@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// ListOrganizeLeftoverFolders 返回整理遗留的空文件夹：已执行的整理为变空的原文件夹，已撤销的整理为其创建的文件夹
+func (a *App) ListOrganizeLeftoverFolders(operationID int64) ([]string, error) {
+	candidates, err := a.organizeFolderCandidates(operationID)
+	if err != nil {
+		return nil, err
+	}
+	return a.emptyFolders(candidates), nil
+}
+
+// RemoveOrganizeLeftoverFolders 删除整理遗留的空文件夹，返回实际删除的文件夹
+func (a *App) RemoveOrganizeLeftoverFolders(operationID int64) ([]string, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	candidates, err := a.organizeFolderCandidates(operationID)
+	if err != nil {
+		return nil, err
+	}
+	removed := a.removeEmptyFolders(a.currentWorkspace, candidates)
+	if a.logger != nil {
+		a.logger.Info("清理整理遗留的空文件夹", zap.Int64("operation_id", operationID), zap.Int("removed", len(removed)))
+	}
+	return removed, nil
+}
+
+// organizeFolderCandidates 按整理的当前状态返回可能遗留为空的文件夹
+func (a *App) organizeFolderCandidates(operationID int64) ([]string, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	op, err := a.db.GetOperation(a.ctx, operationID)
+	if err != nil {
+		return nil, err
+	}
+	if op.Type != data.OperationOrganize {
+		return nil, errors.New("操作类型不匹配")
+	}
+	if op.WorkspaceID.Int64 != a.currentWorkspace.ID {
+		return nil, errors.New("当前工作区与操作记录不一致，请先切换到原工作区")
+	}
+	var payload api.OrganizeOperationPayload
+	if err := json.Unmarshal([]byte(op.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("解析整理记录失败: %w", err)
+	}
+
+	switch op.Status {
+	case data.OperationApplied:
+		return removableSourceFolders(payload), nil
+	case data.OperationUndone:
+		return payload.CreatedDirs, nil
+	default:
+		return nil, errors.New("操作尚未执行完成，请先继续或回滚")
+	}
+}
+
+// replayOrganizeFolders 整理撤销/重做后按记录的设置清理空文件夹，并同步文件夹记录
+func (a *App) replayOrganizeFolders(payload api.OrganizeOperationPayload, undo bool) []string {
+	ws := a.currentWorkspace
+	if undo {
+		// 移回文件时重建的原文件夹需要补回记录
+		a.ensureFolderRecords(ws, payload.RemovedDirs)
+		if payload.RemoveEmptyFolders {
+			return a.removeEmptyFolders(ws, payload.CreatedDirs)
+		}
+		// 隔离区目录只为本次整理而建，撤销后总是清理
+		return a.removeEmptyFolders(ws, quarantineFolders(payload))
+	}
+
+	a.ensureFolderRecords(ws, payload.CreatedDirs)
+	if payload.RemoveEmptyFolders {
+		return a.removeEmptyFolders(ws, removableSourceFolders(payload))
+	}
+	return nil
+}
+
+// missingParentDirs 返回移动到 relPath 时需要新建的各级文件夹（由浅到深），seen 用于跨多次调用去重
+func missingParentDirs(root, relPath string, seen map[string]bool) []string {
+	var result []string
+	for _, dir := range parentDirs(relPath) {
+		if seen[dir] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir))); err == nil {
+			continue
+		}
+		seen[dir] = true
+		result = append(result, dir)
+	}
+	return result
+}
+
+// quarantineFolders 返回本次整理新建的文件夹中属于隔离区目录的部分
+func quarantineFolders(payload api.OrganizeOperationPayload) []string {
+	if payload.QuarantineDir == "" {
+		return nil
+	}
+	var result []string
+	for _, dir := range payload.CreatedDirs {
+		if dir == payload.QuarantineDir || strings.HasPrefix(dir, payload.QuarantineDir+"/") {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// sourceFolders 返回移动源文件所在的各级文件夹（去重）
+func sourceFolders(moves []api.OrganizeMoveRecord) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, move := range moves {
+		for _, dir := range parentDirs(move.From) {
+			if !seen[dir] {
+				seen[dir] = true
+				result = append(result, dir)
+			}
+		}
+	}
+	return result
+}
+
+// removableSourceFolders 返回整理后可能变空的源文件夹，不含整理范围的根文件夹及其上级
+func removableSourceFolders(payload api.OrganizeOperationPayload) []string {
+	dirs := sourceFolders(payload.Moves)
+	if payload.KeepFolder == "" {
+		return dirs
+	}
+	result := dirs[:0]
+	for _, dir := range dirs {
+		if dir != payload.KeepFolder && !strings.HasPrefix(payload.KeepFolder, dir+"/") {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// emptyFolders 返回候选中为空、或只包含同样为空的候选文件夹的文件夹（由深到浅）
+func (a *App) emptyFolders(candidates []string) []string {
+	empty := make(map[string]bool)
+	var result []string
+	for _, dir := range deepestFirst(candidates) {
+		entries, err := os.ReadDir(a.workspaceAbs(dir))
+		if err != nil {
+			continue
+		}
+		removable := true
+		for _, entry := range entries {
+			if !entry.IsDir() || !empty[dir+"/"+entry.Name()] {
+				removable = false
+				break
+			}
+		}
+		if removable {
+			empty[dir] = true
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// removeEmptyFolders 由深到浅删除工作区 ws 中候选的空文件夹，并删除对应的文件夹记录
+func (a *App) removeEmptyFolders(ws *data.Workspace, candidates []string) []string {
+	if ws == nil {
+		return nil
+	}
+
+	var removed []string
+	for _, dir := range deepestFirst(candidates) {
+		abs := filepath.Join(ws.Path, filepath.FromSlash(dir))
+		entries, err := os.ReadDir(abs)
+		if err != nil || len(entries) > 0 {
+			continue
+		}
+		if err := os.Remove(abs); err != nil {
+			if a.logger != nil {
+				a.logger.Warn("删除空文件夹失败", zap.String("path", dir), zap.Error(err))
+			}
+			continue
+		}
+		removed = append(removed, dir)
+	}
+	if err := a.db.DeleteFolderRecords(a.ctx, ws.ID, removed); err != nil && a.logger != nil {
+		a.logger.Warn("删除文件夹记录失败", zap.Error(err))
+	}
+	return removed
+}
+
+// ensureFolderRecords 为工作区 ws 中磁盘上存在的文件夹补齐记录，失败只记录警告（重新扫描即可修复）
+func (a *App) ensureFolderRecords(ws *data.Workspace, dirs []string) {
+	if ws == nil || len(dirs) == 0 {
+		return
+	}
+	existing := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if dirExists(filepath.Join(ws.Path, filepath.FromSlash(dir))) {
+			existing = append(existing, dir)
+		}
+	}
+	if err := a.db.EnsureFolderRecords(a.ctx, ws.ID, existing); err != nil && a.logger != nil {
+		a.logger.Warn("补齐文件夹记录失败", zap.Error(err))
+	}
+}
+
+// deepestFirst 按层级由深到浅排序（不修改原切片）
+func deepestFirst(dirs []string) []string {
+	sorted := append([]string(nil), dirs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.Count(sorted[i], "/") > strings.Count(sorted[j], "/")
+	})
+	return sorted
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// 整理类操作在日志中的动作名
+const (
+	organizeActionOrganize = "organize"      // 按标签整理
+	organizeActionFlatten  = "flatten"       // 展平文件夹
+	organizeActionAuto     = "auto_organize" // 自动整理规则触发的整理
+)
+
+// 导出格式
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+)
+
+// organizeActionLabel 返回动作在操作摘要中显示的名称
+func organizeActionLabel(action string) string {
+	switch action {
+	case organizeActionFlatten:
+		return "展平"
+	case organizeActionAuto:
+		return "自动整理"
+	default:
+		return "整理"
+	}
+}
+
+// ListOrganizeOperations 返回整理操作历史（最新在前），workspaceID 为 0 时返回全部工作区
+func (a *App) ListOrganizeOperations(workspaceID int64) ([]api.OrganizeOperationInfo, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	ops, err := a.db.ListOperationsByType(a.ctx, workspaceID, data.OperationOrganize)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]api.OrganizeOperationInfo, 0, len(ops))
+	for _, op := range ops {
+		var payload api.OrganizeOperationPayload
+		if err := json.Unmarshal([]byte(op.Payload), &payload); err != nil && a.logger != nil {
+			a.logger.Warn("解析整理记录失败", zap.Int64("operation_id", op.ID), zap.Error(err))
+		}
+		result = append(result, toOrganizeOperationInfo(op, payload))
+	}
+	return result, nil
+}
+
+// GetOrganizeOperation 返回整理操作的完整记录；执行中的操作附带各步骤的状态
+func (a *App) GetOrganizeOperation(operationID int64) (*api.OrganizeOperationDetail, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	op, err := a.db.GetOperation(a.ctx, operationID)
+	if err != nil {
+		return nil, err
+	}
+	if op.Type != data.OperationOrganize {
+		return nil, errors.New("操作类型不匹配")
+	}
+	var payload api.OrganizeOperationPayload
+	if err := json.Unmarshal([]byte(op.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("解析整理记录失败: %w", err)
+	}
+
+	detail := &api.OrganizeOperationDetail{
+		Operation:          toOrganizeOperationInfo(*op, payload),
+		Moves:              make([]api.OrganizeOperationMove, 0, len(payload.Moves)),
+		CreatedFolders:     append([]string{}, payload.CreatedDirs...),
+		RemovedFolders:     append([]string{}, payload.RemovedDirs...),
+		RemoveEmptyFolders: payload.RemoveEmptyFolders,
+	}
+	for seq, move := range payload.Moves {
+		detail.Moves = append(detail.Moves, api.OrganizeOperationMove{
+			Seq: seq, FileID: move.FileID, From: move.From, To: move.To, Copied: move.Copied,
+		})
+	}
+	if op.Status == data.OperationRunning {
+		steps, err := a.db.ListOperationMoves(a.ctx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, step := range steps {
+			if step.Seq >= 0 && step.Seq < len(detail.Moves) {
+				detail.Moves[step.Seq].State = step.State
+				detail.Moves[step.Seq].Message = step.Message
+			}
+		}
+	}
+	return detail, nil
+}
+
+func toOrganizeOperationInfo(op data.Operation, payload api.OrganizeOperationPayload) api.OrganizeOperationInfo {
+	action := payload.Action
+	if action == "" {
+		action = organizeActionOrganize
+	}
+	info := api.OrganizeOperationInfo{
+		ID:          op.ID,
+		WorkspaceID: op.WorkspaceID.Int64,
+		Action:      action,
+		RuleID:      payload.RuleID,
+		Summary:     op.Summary,
+		Status:      op.Status,
+		CanUndo:     op.Status == data.OperationApplied,
+		CanRedo:     op.Status == data.OperationUndone,
+		MoveCount:   len(payload.Moves),
+		CreatedDirs: len(payload.CreatedDirs),
+		RemovedDirs: len(payload.RemovedDirs),
+		CreatedAt:   formatTime(op.CreatedAt),
+		UpdatedAt:   formatTime(op.UpdatedAt),
+	}
+	for _, move := range payload.Moves {
+		if move.Copied {
+			info.CopiedCount++
+		}
+	}
+	return info
+}
+
+// ExportOrganizePreview 将整理/展平预览导出为 CSV 或 JSON，返回保存路径（用户取消时为空）
+func (a *App) ExportOrganizePreview(preview api.OrganizePreview, format string) (string, error) {
+	return a.exportOrganize("导出整理预览", "整理预览", format, func(w io.Writer) error {
+		if format == exportFormatJSON {
+			return writeJSONExport(w, preview)
+		}
+		return writeOrganizePreviewCSV(w, preview)
+	})
+}
+
+// ExportOrganizeOperation 将已执行的整理记录导出为 CSV 或 JSON，返回保存路径（用户取消时为空）
+func (a *App) ExportOrganizeOperation(operationID int64, format string) (string, error) {
+	detail, err := a.GetOrganizeOperation(operationID)
+	if err != nil {
+		return "", err
+	}
+	return a.exportOrganize("导出整理记录", fmt.Sprintf("整理记录-%d", operationID), format, func(w io.Writer) error {
+		if format == exportFormatJSON {
+			return writeJSONExport(w, detail)
+		}
+		return writeOrganizeOperationCSV(w, detail)
+	})
+}
+
+// exportOrganize 弹出保存对话框并写入导出内容
+func (a *App) exportOrganize(title, defaultName, format string, write func(io.Writer) error) (string, error) {
+	if a.ctx == nil {
+		return "", errors.New("应用尚未完成初始化")
+	}
+	if format != exportFormatCSV && format != exportFormatJSON {
+		return "", fmt.Errorf("不支持的导出格式: %s", format)
+	}
+
+	pattern := "*." + format
+	selectedPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           title,
+		DefaultFilename: defaultName + "." + format,
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: fmt.Sprintf("%s 文件 (%s)", strings.ToUpper(format), pattern),
+				Pattern:     pattern,
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("打开保存对话框失败: %w", err)
+	}
+	if selectedPath == "" {
+		return "", nil // 用户取消
+	}
+	if !strings.HasSuffix(strings.ToLower(selectedPath), "."+format) {
+		selectedPath += "." + format
+	}
+
+	if err := writeExportFile(selectedPath, write); err != nil {
+		return "", err
+	}
+	if a.logger != nil {
+		a.logger.Info("导出整理内容", zap.String("path", selectedPath), zap.String("format", format))
+	}
+	return selectedPath, nil
+}
+
+// writeExportFile 创建文件并写入导出内容，失败时删除写了一半的文件
+func writeExportFile(filePath string, write func(io.Writer) error) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("创建导出文件失败: %w", err)
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(filePath)
+		return fmt.Errorf("写入导出文件失败: %w", err)
+	}
+	return nil
+}
+
+func writeJSONExport(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// newExportCSV 创建 CSV 写入器；写入 UTF-8 BOM，便于表格软件正确识别中文
+func newExportCSV(w io.Writer) (*csv.Writer, error) {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return nil, err
+	}
+	return csv.NewWriter(w), nil
+}
+
+func writeOrganizePreviewCSV(w io.Writer, preview api.OrganizePreview) error {
+	cw, err := newExportCSV(w)
+	if err != nil {
+		return err
+	}
+	rows := [][]string{{
+		"file_id", "original_path", "target_path", "status", "resolution",
+		"replaced_path", "quarantine_path", "missing_tags", "tags", "message",
+	}}
+	for _, item := range preview.Items {
+		rows = append(rows, []string{
+			strconv.FormatInt(item.FileID, 10), item.OriginalPath, item.TargetPath, item.Status, item.Resolution,
+			item.ReplacedPath, item.QuarantinePath, strings.Join(item.MissingTags, ";"), strings.Join(item.Tags, ";"), item.Message,
+		})
+	}
+	return cw.WriteAll(rows)
+}
+
+func writeOrganizeOperationCSV(w io.Writer, detail *api.OrganizeOperationDetail) error {
+	cw, err := newExportCSV(w)
+	if err != nil {
+		return err
+	}
+	rows := [][]string{{"seq", "file_id", "from", "to", "copied", "state", "message"}}
+	for _, move := range detail.Moves {
+		rows = append(rows, []string{
+			strconv.Itoa(move.Seq), strconv.FormatInt(move.FileID, 10), move.From, move.To,
+			strconv.FormatBool(move.Copied), move.State, move.Message,
+		})
+	}
+	return cw.WriteAll(rows)
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/exifdate"
+)
+
+// 整理层级的标签匹配方式
+const (
+	organizeMatchAll   = "all"   // 必须具备全部标签，目录名为 [甲][乙]（默认）
+	organizeMatchFirst = "first" // 按配置顺序取文件具备的第一个标签，目录名为 [甲]
+	organizeMatchEach  = "each"  // 文件具备的全部所列标签，目录名为 [甲][丙]
+)
+
+// 整理层级的分组依据
+const (
+	organizeLevelTag       = "tag"       // 按标签（默认）
+	organizeLevelExtension = "extension" // 按扩展名，目录名为小写扩展名（不含点）
+	organizeLevelCategory  = "category"  // 按文件类别，例如「图片」「文档」
+	organizeLevelMtime     = "mtime"     // 按修改时间
+	organizeLevelExifDate  = "exif_date" // 按 EXIF 拍摄时间，没有拍摄时间的文件不满足该级
+	organizeLevelSize      = "size"      // 按大小区间
+	organizeLevelParent    = "parent"    // 按文件当前所在文件夹的名称
+)
+
+const defaultOrganizeDateFormat = "2006-01"
+
+// defaultOrganizeSizeBuckets 默认的大小区间边界：1MB、10MB、100MB、1GB
+var defaultOrganizeSizeBuckets = []int64{1 << 20, 10 << 20, 100 << 20, 1 << 30}
+
+// organizeCategories 按扩展名划分的文件类别，未列出的扩展名归为「其他」
+var organizeCategories = map[string][]string{
+	"图片":  {".jpg", ".jpeg", ".jpe", ".png", ".gif", ".bmp", ".webp", ".tif", ".tiff", ".heic", ".heif", ".svg", ".ico", ".dng", ".nef", ".cr2", ".arw", ".orf", ".rw2"},
+	"视频":  {".mp4", ".mov", ".mkv", ".avi", ".webm", ".flv", ".wmv", ".m4v", ".mpg", ".mpeg", ".3gp"},
+	"音频":  {".mp3", ".wav", ".flac", ".aac", ".ogg", ".m4a", ".wma", ".opus", ".ape"},
+	"文档":  {".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".txt", ".md", ".rtf", ".odt", ".ods", ".odp", ".csv", ".epub"},
+	"压缩包": {".zip", ".rar", ".7z", ".tar", ".gz", ".bz2", ".xz", ".tgz", ".zst"},
+}
+
+var organizeCategoryByExt = func() map[string]string {
+	byExt := make(map[string]string)
+	for category, exts := range organizeCategories {
+		for _, ext := range exts {
+			byExt[ext] = category
+		}
+	}
+	return byExt
+}()
+
+// organizeFile 计算层级目录所需的文件信息；拍摄时间在首次用到时读取
+type organizeFile struct {
+	relPath  string
+	absPath  string
+	size     int64
+	modTime  time.Time
+	tagSet   map[int64]bool
+	captured *time.Time
+	exifRead bool
+}
+
+// captureTime 返回文件的 EXIF 拍摄时间，不支持的格式或读取失败时返回 false
+func (f *organizeFile) captureTime() (time.Time, bool) {
+	if !f.exifRead {
+		f.exifRead = true
+		if exifdate.Extensions[strings.ToLower(path.Ext(f.relPath))] {
+			if t, err := exifdate.CaptureTime(f.absPath); err == nil {
+				f.captured = &t
+			}
+		}
+	}
+	if f.captured == nil {
+		return time.Time{}, false
+	}
+	return *f.captured, true
+}
+
+// validateOrganizeLevel 校验单个层级的配置，idx 从 0 开始；返回该级引用的标签 ID
+func validateOrganizeLevel(idx int, level api.OrganizeLevel) ([]int64, error) {
+	if level.Fallback != "" && !validFolderName(level.Fallback) {
+		return nil, fmt.Errorf("第 %d 级的兜底目录名无效: %s", idx+1, level.Fallback)
+	}
+
+	switch level.Kind {
+	case "", organizeLevelTag:
+		if len(level.TagIDs) == 0 {
+			return nil, fmt.Errorf("第 %d 级至少选择一个标签", idx+1)
+		}
+		for _, tagID := range level.TagIDs {
+			if tagID <= 0 {
+				return nil, fmt.Errorf("第 %d 级存在无效的标签 ID", idx+1)
+			}
+		}
+		switch level.Mode {
+		case "", organizeMatchAll, organizeMatchFirst, organizeMatchEach:
+		default:
+			return nil, fmt.Errorf("第 %d 级的匹配方式无效: %s", idx+1, level.Mode)
+		}
+		return level.TagIDs, nil
+	case organizeLevelMtime, organizeLevelExifDate:
+		if level.DateFormat != "" {
+			sample := time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)
+			formatted := sample.Format(level.DateFormat)
+			if formatted == level.DateFormat || !validFolderName(formatted) {
+				return nil, fmt.Errorf("第 %d 级的日期格式无效: %s", idx+1, level.DateFormat)
+			}
+		}
+	case organizeLevelSize:
+		for i, bound := range level.SizeBuckets {
+			if bound <= 0 || (i > 0 && bound <= level.SizeBuckets[i-1]) {
+				return nil, fmt.Errorf("第 %d 级的大小区间需为递增的正数", idx+1)
+			}
+		}
+	case organizeLevelExtension, organizeLevelCategory, organizeLevelParent:
+	default:
+		return nil, fmt.Errorf("第 %d 级的分组依据无效: %s", idx+1, level.Kind)
+	}
+	if len(level.TagIDs) > 0 {
+		return nil, fmt.Errorf("第 %d 级按文件属性分组，不能同时选择标签", idx+1)
+	}
+	return nil, nil
+}
+
+// organizeLevelFolder 计算文件在某一级的目录名
+//
+// 不满足该级时使用兜底目录；未配置兜底目录则返回缺失的标签或属性（任选其一的层级合并为一项）。
+func organizeLevelFolder(level api.OrganizeLevel, file *organizeFile, tagNameMap map[int64]string) (string, []string) {
+	if level.Kind != "" && level.Kind != organizeLevelTag {
+		name, missing := organizeAttributeFolder(level, file)
+		switch {
+		case missing == "":
+			return name, nil
+		case level.Fallback != "":
+			return level.Fallback, nil
+		default:
+			return "", []string{missing}
+		}
+	}
+
+	mode := level.Mode
+	if mode == "" {
+		mode = organizeMatchAll
+	}
+
+	var matched, missing []string
+	for _, tagID := range level.TagIDs {
+		if !file.tagSet[tagID] {
+			missing = append(missing, tagNameMap[tagID])
+			continue
+		}
+		matched = append(matched, sanitizeFolderSegment(tagNameMap[tagID]))
+		if mode == organizeMatchFirst {
+			break
+		}
+	}
+
+	satisfied := len(matched) > 0
+	if mode == organizeMatchAll {
+		satisfied = len(missing) == 0
+	}
+	switch {
+	case satisfied:
+		return fmt.Sprintf("[%s]", strings.Join(matched, "][")), nil
+	case level.Fallback != "":
+		return level.Fallback, nil
+	case mode == organizeMatchAll:
+		return "", missing
+	default:
+		return "", []string{strings.Join(missing, "/") + "（任一）"}
+	}
+}
+
+// organizeAttributeFolder 按文件属性计算目录名；文件缺少该属性时返回缺失项的说明
+func organizeAttributeFolder(level api.OrganizeLevel, file *organizeFile) (string, string) {
+	dateFormat := level.DateFormat
+	if dateFormat == "" {
+		dateFormat = defaultOrganizeDateFormat
+	}
+
+	switch level.Kind {
+	case organizeLevelExtension:
+		ext := strings.TrimPrefix(strings.ToLower(path.Ext(file.relPath)), ".")
+		if ext == "" {
+			return "", "扩展名"
+		}
+		return sanitizeFolderSegment(ext), ""
+	case organizeLevelCategory:
+		if category, ok := organizeCategoryByExt[strings.ToLower(path.Ext(file.relPath))]; ok {
+			return category, ""
+		}
+		return "其他", ""
+	case organizeLevelMtime:
+		return file.modTime.Local().Format(dateFormat), ""
+	case organizeLevelExifDate:
+		captured, ok := file.captureTime()
+		if !ok {
+			return "", "拍摄时间"
+		}
+		return captured.Format(dateFormat), ""
+	case organizeLevelSize:
+		buckets := level.SizeBuckets
+		if len(buckets) == 0 {
+			buckets = defaultOrganizeSizeBuckets
+		}
+		return sizeBucketName(file.size, buckets), ""
+	case organizeLevelParent:
+		dir := path.Dir(file.relPath)
+		if dir == "." || dir == "/" {
+			return "", "所在文件夹"
+		}
+		return sanitizeFolderSegment(path.Base(dir)), ""
+	}
+	return "", level.Kind
+}
+
+// sizeBucketName 返回文件大小所在区间的目录名，例如「小于1MB」「1MB-10MB」「1GB以上」
+func sizeBucketName(size int64, bounds []int64) string {
+	for i, bound := range bounds {
+		if size < bound {
+			if i == 0 {
+				return "小于" + formatSizeLabel(bound)
+			}
+			return formatSizeLabel(bounds[i-1]) + "-" + formatSizeLabel(bound)
+		}
+	}
+	return formatSizeLabel(bounds[len(bounds)-1]) + "以上"
+}
+
+// formatSizeLabel 以合适的单位格式化大小（保留一位小数），例如 1048576 -> 1MB，1536 -> 1.5KB
+func formatSizeLabel(size int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64) + units[unit]
+}
+
+// validFolderName 检查用户填写的目录名能否直接作为单级目录
+func validFolderName(name string) bool {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" || trimmed == "." || trimmed == ".." || trimmed != name {
+		return false
+	}
+	return !strings.ContainsAny(name, `<>:"/\|?*`)
+}
+
+// parentDirs 返回相对路径的各级父目录，例如 a/b/c.txt -> [a a/b]
+func parentDirs(relPath string) []string {
+	var dirs []string
+	for dir := path.Dir(relPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+	return dirs
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// 移动步骤在磁盘上的实际位置
+const (
+	moveAtFrom    = "from"
+	moveAtTo      = "to"
+	moveAtMissing = "missing"
+)
+
+// organizeInterruptedEvent 发现上次异常退出遗留的整理时向前端推送的事件，数据为操作 ID 列表
+const organizeInterruptedEvent = "organize-interrupted"
+
+// detectInterruptedOrganizes 前端加载完成后检查上次异常退出遗留的整理，并推送 organizeInterruptedEvent，
+// 前端据此通过 ListInterruptedOrganizes 提示用户继续或回滚
+func (a *App) detectInterruptedOrganizes() {
+	if a.db == nil {
+		return
+	}
+
+	ops, err := a.db.ListRunningOperations(a.ctx)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Warn("检查未完成的整理失败", zap.Error(err))
+		}
+		return
+	}
+	var ids []int64
+	for _, op := range ops {
+		if op.Type != data.OperationOrganize {
+			continue
+		}
+		if a.logger != nil {
+			a.logger.Warn("发现未完成的整理",
+				zap.Int64("operation_id", op.ID),
+				zap.Int64("workspace_id", op.WorkspaceID.Int64),
+				zap.String("summary", op.Summary),
+			)
+		}
+		ids = append(ids, op.ID)
+	}
+	if len(ids) > 0 {
+		a.emitEvent(organizeInterruptedEvent, ids)
+	}
+}
+
+// ListInterruptedOrganizes 返回异常退出后未完成的整理
+func (a *App) ListInterruptedOrganizes() ([]api.InterruptedOrganize, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	ops, err := a.db.ListRunningOperations(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]api.InterruptedOrganize, 0, len(ops))
+	for _, op := range ops {
+		if op.Type != data.OperationOrganize {
+			continue
+		}
+		item := api.InterruptedOrganize{
+			OperationID: op.ID,
+			WorkspaceID: op.WorkspaceID.Int64,
+			Summary:     op.Summary,
+			CreatedAt:   formatTime(op.CreatedAt),
+		}
+		moves, err := a.db.ListOperationMoves(a.ctx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		item.Total = len(moves)
+		ws, err := a.db.GetWorkspaceByID(a.ctx, item.WorkspaceID)
+		if err != nil {
+			item.Missing = len(moves)
+			result = append(result, item)
+			continue
+		}
+		item.WorkspacePath = ws.Path
+		for _, move := range moves {
+			switch locateMove(ws.Path, move) {
+			case moveAtTo:
+				item.Moved++
+			case moveAtFrom:
+				item.Pending++
+			default:
+				item.Missing++
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// ResumeOrganize 继续执行未完成的整理，已在目标位置的文件只同步数据库
+func (a *App) ResumeOrganize(operationID int64) (*api.OperationReplayResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.recoverOrganize(operationID, true)
+}
+
+// RollbackOrganize 回滚未完成的整理，把已移动的文件按相反顺序放回原位置
+func (a *App) RollbackOrganize(operationID int64) (*api.OperationReplayResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.recoverOrganize(operationID, false)
+}
+
+// recoverOrganize 按磁盘实际状态逐个处理移动步骤，全部成功后结束操作：继续为 applied，回滚为 undone
+//
+// 失败的步骤保留在记录中，可以重复执行。
+func (a *App) recoverOrganize(operationID int64, resume bool) (*api.OperationReplayResult, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	op, err := a.db.GetOperation(a.ctx, operationID)
+	if err != nil {
+		return nil, err
+	}
+	if op.Type != data.OperationOrganize {
+		return nil, errors.New("操作类型不匹配")
+	}
+	if op.Status != data.OperationRunning {
+		return nil, errors.New("整理已结束，无需继续或回滚")
+	}
+	ws, err := a.db.GetWorkspaceByID(a.ctx, op.WorkspaceID.Int64)
+	if err != nil {
+		return nil, err
+	}
+	moves, err := a.db.ListOperationMoves(a.ctx, op.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api.OperationReplayResult{OperationID: op.ID, Status: op.Status}
+	next, state, want := data.OperationApplied, data.MoveStateDone, moveAtTo
+	if !resume {
+		next, state, want = data.OperationUndone, data.MoveStateRolledBack, moveAtFrom
+	}
+	for i := range moves {
+		move := moves[i]
+		if !resume {
+			move = moves[len(moves)-1-i]
+		}
+		src, dst := move.From, move.To
+		if !resume {
+			src, dst = move.To, move.From
+		}
+
+		var stepErr error
+		switch location := locateMove(ws.Path, move); {
+		case location == moveAtFrom && interruptedCopy(ws.Path, move):
+			// 跨设备复制已完成校验但源文件尚未删除：继续时删除源文件，回滚时删除副本
+			remove := move.To
+			if resume {
+				remove = move.From
+			}
+			if stepErr = os.Remove(filepath.Join(ws.Path, filepath.FromSlash(remove))); stepErr == nil {
+				stepErr = a.syncFilePath(move.FileID, dst)
+			}
+			if stepErr == nil {
+				result.Succeeded++
+			}
+		case location == want:
+			stepErr = a.syncFilePath(move.FileID, dst)
+			if stepErr == nil {
+				result.Skipped++
+			}
+		case location == moveAtMissing:
+			stepErr = errors.New("文件在磁盘上已不存在")
+		default:
+			_, stepErr = a.moveFileInWorkspace(ws.Path, move.FileID, src, dst)
+			if stepErr == nil {
+				result.Succeeded++
+			}
+		}
+		// 清理中断的复制遗留的临时文件
+		_ = os.Remove(filepath.Join(ws.Path, filepath.FromSlash(move.To)) + partialCopySuffix)
+
+		if stepErr != nil {
+			result.Failed++
+			result.Conflicts = append(result.Conflicts, api.OperationConflict{FileID: move.FileID, Path: src, Message: stepErr.Error()})
+			a.setOrganizeMoveState(op.ID, move.Seq, data.MoveStateFailed, stepErr.Error())
+			continue
+		}
+		a.setOrganizeMoveState(op.ID, move.Seq, state, "")
+	}
+
+	if result.Failed > 0 {
+		result.Message = fmt.Sprintf("%d 个文件处理失败，可处理后重试", result.Failed)
+	} else {
+		if err := a.db.FinishOperation(a.ctx, op.ID, next); err != nil {
+			return nil, err
+		}
+		result.Status = next
+	}
+
+	if a.logger != nil {
+		a.logger.Info("处理未完成的整理",
+			zap.Int64("operation_id", op.ID),
+			zap.Bool("resume", resume),
+			zap.Int("succeeded", result.Succeeded),
+			zap.Int("skipped", result.Skipped),
+			zap.Int("failed", result.Failed),
+		)
+	}
+	return result, nil
+}
+
+// locateMove 判断移动步骤对应的文件实际在原位置还是目标位置
+//
+// 两处都存在时以记录的状态为准：只有确认完成的步骤才视为已移动。
+func locateMove(root string, move data.OperationMove) string {
+	_, fromErr := os.Stat(filepath.Join(root, filepath.FromSlash(move.From)))
+	_, toErr := os.Stat(filepath.Join(root, filepath.FromSlash(move.To)))
+	switch {
+	case fromErr == nil && toErr == nil:
+		if move.State == data.MoveStateDone {
+			return moveAtTo
+		}
+		return moveAtFrom
+	case fromErr == nil:
+		return moveAtFrom
+	case toErr == nil:
+		return moveAtTo
+	default:
+		return moveAtMissing
+	}
+}
+
+// interruptedCopy 判断移动步骤是否为中断的跨设备复制：两处都存在、步骤未确认完成且内容一致
+func interruptedCopy(root string, move data.OperationMove) bool {
+	if move.State == data.MoveStateDone {
+		return false
+	}
+	return sameFileContent(
+		filepath.Join(root, filepath.FromSlash(move.From)),
+		filepath.Join(root, filepath.FromSlash(move.To)),
+	)
+}
+
+// syncFilePath 文件已在磁盘上就位但数据库可能尚未更新（移动后异常退出）时补齐记录
+func (a *App) syncFilePath(fileID int64, relPath string) error {
+	file, err := a.db.GetFileByID(a.ctx, fileID)
+	if err != nil {
+		// 记录已被重新扫描清理，下次扫描会按磁盘状态重建
+		return nil
+	}
+	if filepath.ToSlash(file.Path) == relPath {
+		return nil
+	}
+	return a.db.UpdateFileName(a.ctx, fileID, filepath.Base(filepath.FromSlash(relPath)), relPath)
+}
+
+// setOrganizeMoveState 更新移动步骤状态，失败只记录警告（恢复时以磁盘状态为准）
+func (a *App) setOrganizeMoveState(operationID int64, seq int, state, message string) {
+	if err := a.db.SetOperationMoveState(a.ctx, operationID, seq, state, message); err != nil && a.logger != nil {
+		a.logger.Warn("更新整理步骤状态失败",
+			zap.Int64("operation_id", operationID),
+			zap.Int("seq", seq),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,577 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// organizeRuleRunEvent 自动整理规则运行后向前端推送的事件，数据为 api.OrganizeRuleRun
+const organizeRuleRunEvent = "organize-rule-run"
+
+const (
+	// organizeRuleTick 检查规则是否需要运行的间隔
+	organizeRuleTick = 30 * time.Second
+	// organizeRuleSettle 文件最近一次修改距今不足该时长时视为仍在写入（例如正在下载），本次不整理
+	organizeRuleSettle = 10 * time.Second
+)
+
+// inboxPartialSuffixes 下载或复制中的临时文件后缀，自动整理时跳过
+var inboxPartialSuffixes = []string{".part", ".partial", ".crdownload", ".download", ".tmp", partialCopySuffix}
+
+// ListOrganizeRules 返回当前工作区的自动整理规则
+func (a *App) ListOrganizeRules() ([]api.OrganizeRule, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	rules, err := a.db.ListOrganizeRules(a.ctx, a.currentWorkspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]api.OrganizeRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toAPIOrganizeRule(rule))
+	}
+	return result, nil
+}
+
+// CreateOrganizeRule 为当前工作区新增自动整理规则
+func (a *App) CreateOrganizeRule(input api.OrganizeRuleInput) (*api.OrganizeRule, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	rule, err := a.organizeRuleFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	created, err := a.db.CreateOrganizeRule(a.ctx, *rule)
+	if err != nil {
+		return nil, err
+	}
+	if a.logger != nil {
+		a.logger.Info("新增自动整理规则",
+			zap.Int64("rule_id", created.ID),
+			zap.String("name", created.Name),
+			zap.String("source_folder", created.SourceFolder),
+		)
+	}
+	result := toAPIOrganizeRule(*created)
+	return &result, nil
+}
+
+// UpdateOrganizeRule 修改自动整理规则
+func (a *App) UpdateOrganizeRule(ruleID int64, input api.OrganizeRuleInput) (*api.OrganizeRule, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if _, err := a.currentOrganizeRule(ruleID); err != nil {
+		return nil, err
+	}
+	rule, err := a.organizeRuleFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = ruleID
+	updated, err := a.db.UpdateOrganizeRule(a.ctx, *rule)
+	if err != nil {
+		return nil, err
+	}
+	result := toAPIOrganizeRule(*updated)
+	return &result, nil
+}
+
+// DeleteOrganizeRule 删除自动整理规则，已执行的整理仍可在操作日志中撤销
+func (a *App) DeleteOrganizeRule(ruleID int64) error {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if _, err := a.currentOrganizeRule(ruleID); err != nil {
+		return err
+	}
+	if err := a.db.DeleteOrganizeRule(a.ctx, ruleID); err != nil {
+		return err
+	}
+	if a.logger != nil {
+		a.logger.Info("删除自动整理规则", zap.Int64("rule_id", ruleID))
+	}
+	return nil
+}
+
+// RunOrganizeRule 立即运行规则；dryRun 为 true 时只生成预览（规则或全局设置为只预览时同样不会移动文件）
+func (a *App) RunOrganizeRule(ruleID int64, dryRun bool) (*api.OrganizeRuleRun, error) {
+	rule, err := a.currentOrganizeRule(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return a.runOrganizeRule(rule, data.RuleTriggerManual, dryRun)
+}
+
+// ListOrganizeRuleRuns 返回规则的运行记录（最新在前）
+func (a *App) ListOrganizeRuleRuns(ruleID int64, limit int) ([]api.OrganizeRuleRun, error) {
+	if _, err := a.currentOrganizeRule(ruleID); err != nil {
+		return nil, err
+	}
+
+	runs, err := a.db.ListOrganizeRuleRuns(a.ctx, ruleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]api.OrganizeRuleRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, toAPIOrganizeRuleRun(run))
+	}
+	return result, nil
+}
+
+// GetOrganizeRuleRunPreview 返回只预览或因冲突未执行的运行所保存的预览
+func (a *App) GetOrganizeRuleRunPreview(runID int64) (*api.OrganizePreview, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	run, err := a.db.GetOrganizeRuleRun(a.ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.currentOrganizeRule(run.RuleID); err != nil {
+		return nil, err
+	}
+	if run.Preview == "" {
+		return nil, errors.New("该次运行没有保存预览")
+	}
+	var preview api.OrganizePreview
+	if err := json.Unmarshal([]byte(run.Preview), &preview); err != nil {
+		return nil, fmt.Errorf("解析预览失败: %w", err)
+	}
+	return &preview, nil
+}
+
+// currentOrganizeRule 读取规则并确认其属于当前工作区
+func (a *App) currentOrganizeRule(ruleID int64) (*data.OrganizeRule, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	rule, err := a.db.GetOrganizeRule(a.ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.WorkspaceID != a.currentWorkspace.ID {
+		return nil, errors.New("自动整理规则不属于当前工作区")
+	}
+	return rule, nil
+}
+
+// organizeRuleFromInput 校验规则设置；整理方式按收件箱生成一次计划来校验，与执行时的检查一致
+func (a *App) organizeRuleFromInput(input api.OrganizeRuleInput) (*data.OrganizeRule, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("规则名称不能为空")
+	}
+	source, err := cleanWorkspaceDir(input.SourceFolder)
+	if err != nil {
+		return nil, fmt.Errorf("收件箱文件夹无效: %w", err)
+	}
+	if source == "" {
+		return nil, errors.New("请选择工作区内的收件箱文件夹")
+	}
+	if input.IntervalMinutes < 0 {
+		return nil, errors.New("定时间隔不能为负数")
+	}
+
+	req := input.Request
+	req.SourceFolder = source
+	if _, err := a.buildOrganizePlan(a.currentWorkspace, req, "", nil); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("序列化整理请求失败: %w", err)
+	}
+
+	return &data.OrganizeRule{
+		WorkspaceID:     a.currentWorkspace.ID,
+		Name:            name,
+		SourceFolder:    source,
+		Request:         string(raw),
+		IntervalMinutes: input.IntervalMinutes,
+		OnNewFiles:      input.OnNewFiles,
+		DryRun:          input.DryRun,
+		Enabled:         input.Enabled,
+	}, nil
+}
+
+// startOrganizeRuleScheduler 启动后台检查：定时规则到期或收件箱出现新文件时运行规则
+func (a *App) startOrganizeRuleScheduler() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	a.ruleStop, a.ruleDone = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(organizeRuleTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				a.checkOrganizeRules(stop, now)
+			}
+		}
+	}()
+}
+
+// stopOrganizeRuleScheduler 停止后台检查，并等待其退出（包括正在运行的规则），之后才能关闭数据库
+func (a *App) stopOrganizeRuleScheduler() {
+	if a.ruleStop == nil {
+		return
+	}
+	close(a.ruleStop)
+	<-a.ruleDone
+	a.ruleStop, a.ruleDone = nil, nil
+}
+
+// checkOrganizeRules 检查当前工作区的规则；其他工作区的规则在切换过去后才会运行。stop 关闭后不再运行剩余的规则
+func (a *App) checkOrganizeRules(stop <-chan struct{}, now time.Time) {
+	a.opMu.Lock()
+	ws := a.currentWorkspace
+	a.opMu.Unlock()
+	if a.db == nil || ws == nil {
+		return
+	}
+
+	// 存在异常退出遗留的整理时先由用户继续或回滚，避免在不一致的状态上继续移动文件
+	running, err := a.db.ListRunningOperations(a.ctx)
+	if err != nil {
+		return
+	}
+	for _, op := range running {
+		if op.WorkspaceID.Int64 == ws.ID {
+			return
+		}
+	}
+
+	rules, err := a.db.ListOrganizeRules(a.ctx, ws.ID)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Warn("读取自动整理规则失败", zap.Error(err))
+		}
+		return
+	}
+	for i := range rules {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		rule := &rules[i]
+		if !rule.Enabled {
+			continue
+		}
+
+		trigger := ""
+		if rule.IntervalMinutes > 0 &&
+			(!rule.LastRunAt.Valid || now.Sub(rule.LastRunAt.Time) >= time.Duration(rule.IntervalMinutes)*time.Minute) {
+			trigger = data.RuleTriggerSchedule
+		}
+		if trigger == "" && rule.OnNewFiles {
+			snapshot := inboxSnapshot(filepath.Join(ws.Path, filepath.FromSlash(rule.SourceFolder)), now)
+			if snapshot == rule.Snapshot {
+				continue
+			}
+			if snapshot == "" {
+				// 收件箱已清空，只记录指纹
+				if err := a.db.SetOrganizeRuleSnapshot(a.ctx, rule.ID, snapshot); err != nil && a.logger != nil {
+					a.logger.Warn("更新收件箱指纹失败", zap.Int64("rule_id", rule.ID), zap.Error(err))
+				}
+				continue
+			}
+			trigger = data.RuleTriggerNewFiles
+		}
+		if trigger == "" {
+			continue
+		}
+
+		if _, err := a.runOrganizeRule(rule, trigger, false); err != nil && a.logger != nil {
+			a.logger.Warn("自动整理规则运行失败", zap.Int64("rule_id", rule.ID), zap.Error(err))
+		}
+	}
+}
+
+// runOrganizeRule 运行一次规则：登记收件箱中的新文件，生成计划，按设置只预览或执行并写入运行记录
+//
+// 执行时与手动整理一样写入操作日志，可撤销；存在冲突时不执行，由冲突策略决定能否自动处理。
+func (a *App) runOrganizeRule(rule *data.OrganizeRule, trigger string, dryRun bool) (*api.OrganizeRuleRun, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	// 持有 opMu 期间工作区不会切换，本次运行只使用此处取得的工作区
+	ws := a.currentWorkspace
+	if ws == nil || ws.ID != rule.WorkspaceID {
+		return nil, errors.New("当前工作区与规则不一致，请先切换到规则所属的工作区")
+	}
+	inbox := filepath.Join(ws.Path, filepath.FromSlash(rule.SourceFolder))
+
+	startedAt := time.Now()
+	run := data.OrganizeRuleRun{
+		RuleID:    rule.ID,
+		Trigger:   trigger,
+		DryRun:    dryRun || rule.DryRun || (a.settings != nil && a.settings.AutoOrganizeDryRunOnly),
+		StartedAt: startedAt,
+	}
+
+	var req api.OrganizeRequest
+	err := json.Unmarshal([]byte(rule.Request), &req)
+	req.SourceFolder = rule.SourceFolder
+	if err == nil {
+		err = a.importInboxFiles(ws, rule.SourceFolder, startedAt)
+	}
+	var plan *api.OrganizePreview
+	if err == nil {
+		plan, err = a.buildOrganizePlan(ws, req, "", func(file data.FileRecord) bool {
+			info, statErr := os.Lstat(filepath.Join(ws.Path, file.Path))
+			return statErr != nil || inboxFileInProgress(file.Name, info.ModTime(), startedAt)
+		})
+	}
+
+	if err != nil {
+		run.Status = data.RuleRunFailed
+		run.Message = err.Error()
+	} else {
+		run.MoveCount = plan.Summary.MoveCount
+		run.ConflictCount = plan.Summary.ConflictCount
+		run.SkipCount = plan.Summary.SkipCount
+		switch {
+		case run.DryRun:
+			run.Status = data.RuleRunDryRun
+			run.Preview = marshalRulePreview(plan)
+		case plan.Summary.ConflictCount > 0:
+			run.Status = data.RuleRunBlocked
+			run.Message = fmt.Sprintf("存在 %d 个冲突，未执行（可在规则中选择冲突处理方式）", plan.Summary.ConflictCount)
+			run.Preview = marshalRulePreview(plan)
+		case plan.Summary.MoveCount == 0:
+			run.Status = data.RuleRunNoChanges
+		default:
+			result, execErr := a.executeOrganizePlan(ws, plan, organizeRun{
+				action:      organizeActionAuto,
+				removeEmpty: req.RemoveEmptyFolders,
+				keepFolder:  rule.SourceFolder,
+				ruleID:      rule.ID,
+			})
+			if execErr != nil {
+				run.Status = data.RuleRunFailed
+				run.Message = execErr.Error()
+			} else {
+				run.Status = data.RuleRunApplied
+				run.OperationID.Int64, run.OperationID.Valid = result.OperationID, result.OperationID > 0
+			}
+		}
+	}
+	run.FinishedAt = time.Now()
+
+	// 记录运行后的收件箱指纹，未能整理的文件不会反复触发
+	if rule.OnNewFiles {
+		if err := a.db.SetOrganizeRuleSnapshot(a.ctx, rule.ID, inboxSnapshot(inbox, run.FinishedAt)); err != nil && a.logger != nil {
+			a.logger.Warn("更新收件箱指纹失败", zap.Int64("rule_id", rule.ID), zap.Error(err))
+		}
+	}
+	saved, err := a.db.InsertOrganizeRuleRun(a.ctx, run)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.logger != nil {
+		a.logger.Info("自动整理规则运行完成",
+			zap.Int64("rule_id", rule.ID),
+			zap.String("trigger", trigger),
+			zap.String("status", run.Status),
+			zap.Bool("dry_run", run.DryRun),
+			zap.Int("moved", run.MoveCount),
+			zap.Int("conflicts", run.ConflictCount),
+			zap.String("message", run.Message),
+		)
+	}
+	result := toAPIOrganizeRuleRun(*saved)
+	a.emitEvent(organizeRuleRunEvent, result)
+	return &result, nil
+}
+
+// importInboxFiles 为收件箱中尚未记录的文件与文件夹写入记录，并识别新文件名中的标签；仍在写入的文件留到下次
+//
+// 不重新扫描整个工作区，已有文件的记录与标签保持不变。
+func (a *App) importInboxFiles(ws *data.Workspace, source string, now time.Time) error {
+	root := filepath.Join(ws.Path, filepath.FromSlash(source))
+	var items []data.FileMetadata
+	walkErr := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(ws.Path, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		item := data.FileMetadata{
+			WorkspaceID: ws.ID,
+			Path:        rel,
+			Name:        info.Name(),
+			Type:        data.FileTypeDirectory,
+			ModTime:     info.ModTime().UTC(),
+			CreatedAt:   now.UTC(),
+		}
+		if !d.IsDir() {
+			if !info.Mode().IsRegular() || inboxFileInProgress(info.Name(), info.ModTime(), now) {
+				return nil
+			}
+			item.Type = data.FileTypeRegular
+			item.Size = info.Size()
+			item.Hash = fmt.Sprintf("%s_%d_%d", rel, info.Size(), info.ModTime().UnixNano())
+		}
+		items = append(items, item)
+		return nil
+	})
+	if walkErr != nil {
+		return fmt.Errorf("读取收件箱失败: %w", walkErr)
+	}
+
+	ids, err := a.db.InsertFileRecords(a.ctx, items)
+	if err != nil {
+		return err
+	}
+	added := 0
+	for i, id := range ids {
+		if id == 0 || items[i].Type != data.FileTypeRegular {
+			continue
+		}
+		added++
+		tags := a.parseTagsFromFileName(items[i].Name)
+		if len(tags) == 0 {
+			continue
+		}
+		if err := a.db.BatchAddTagsToFile(a.ctx, id, tags); err != nil && a.logger != nil {
+			a.logger.Warn("为文件添加标签失败", zap.Int64("file_id", id), zap.String("file_name", items[i].Name), zap.Error(err))
+		}
+	}
+	if added > 0 && a.logger != nil {
+		a.logger.Info("登记收件箱中的新文件", zap.String("source_folder", source), zap.Int("count", added))
+	}
+	return nil
+}
+
+// inboxFileInProgress 判断文件是否可能仍在写入：下载中的临时文件，或刚刚修改过
+func inboxFileInProgress(name string, modTime, now time.Time) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range inboxPartialSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return now.Sub(modTime) < organizeRuleSettle
+}
+
+// inboxSnapshot 计算收件箱中已写入完成的文件的指纹（路径、大小、修改时间），没有文件时返回空字符串
+func inboxSnapshot(root string, now time.Time) string {
+	hash := sha256.New()
+	count := 0
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() || inboxFileInProgress(info.Name(), info.ModTime(), now) {
+			return nil
+		}
+		fmt.Fprintf(hash, "%s|%d|%d\n", p, info.Size(), info.ModTime().UnixNano())
+		count++
+		return nil
+	})
+	if count == 0 {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// marshalRulePreview 序列化预览以便事后查看，失败时不保存预览
+func marshalRulePreview(plan *api.OrganizePreview) string {
+	raw, err := json.Marshal(plan)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+func toAPIOrganizeRule(rule data.OrganizeRule) api.OrganizeRule {
+	var req api.OrganizeRequest
+	_ = json.Unmarshal([]byte(rule.Request), &req)
+	result := api.OrganizeRule{
+		ID:              rule.ID,
+		WorkspaceID:     rule.WorkspaceID,
+		Name:            rule.Name,
+		SourceFolder:    rule.SourceFolder,
+		Request:         req,
+		IntervalMinutes: rule.IntervalMinutes,
+		OnNewFiles:      rule.OnNewFiles,
+		DryRun:          rule.DryRun,
+		Enabled:         rule.Enabled,
+		LastStatus:      rule.LastStatus,
+		LastMessage:     rule.LastMessage,
+		CreatedAt:       formatTime(rule.CreatedAt),
+		UpdatedAt:       formatTime(rule.UpdatedAt),
+	}
+	if rule.LastRunAt.Valid {
+		result.LastRunAt = formatTime(rule.LastRunAt.Time)
+	}
+	return result
+}
+
+func toAPIOrganizeRuleRun(run data.OrganizeRuleRun) api.OrganizeRuleRun {
+	return api.OrganizeRuleRun{
+		ID:            run.ID,
+		RuleID:        run.RuleID,
+		Trigger:       run.Trigger,
+		DryRun:        run.DryRun,
+		Status:        run.Status,
+		OperationID:   run.OperationID.Int64,
+		MoveCount:     run.MoveCount,
+		ConflictCount: run.ConflictCount,
+		SkipCount:     run.SkipCount,
+		Message:       run.Message,
+		HasPreview:    run.Preview != "",
+		StartedAt:     formatTime(run.StartedAt),
+		FinishedAt:    formatTime(run.FinishedAt),
+	}
+}
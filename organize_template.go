@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+	"tagexplorer/internal/pathtemplate"
+)
+
+// ValidateOrganizeTemplate 校验整理路径模板，错误时返回出错位置便于前端高亮
+func (a *App) ValidateOrganizeTemplate(req api.OrganizeRequest) (*api.OrganizeTemplateCheck, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	tags, err := a.db.ListTags(a.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	tmpl, _, err := compileOrganizeTemplate(req, tags)
+	if err != nil {
+		var syntaxErr *pathtemplate.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return &api.OrganizeTemplateCheck{
+				Message:  syntaxErr.Error(),
+				Offset:   syntaxErr.Offset,
+				Length:   syntaxErr.Length,
+				Template: req.Template,
+			}, nil
+		}
+		return nil, err
+	}
+	return &api.OrganizeTemplateCheck{Valid: true, Template: tmpl.Source}, nil
+}
+
+// compileOrganizeTemplate 解析整理模板，并校验层级占位符与标签组是否存在，返回标签组名到标签 ID 的映射
+func compileOrganizeTemplate(req api.OrganizeRequest, tags []data.Tag) (*pathtemplate.Template, map[string]int64, error) {
+	src := req.Template
+	if strings.TrimSpace(src) == "" {
+		src = defaultOrganizeTemplate(len(req.Levels))
+	}
+	tmpl, err := pathtemplate.Parse(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, part := range tmpl.Placeholders(pathtemplate.KindLevel) {
+		if part.Level > len(req.Levels) {
+			return nil, nil, pathtemplate.NewError(src, part.Pos, part.End, fmt.Sprintf("：只配置了 %d 级", len(req.Levels)))
+		}
+	}
+
+	byName := make(map[string]int64, len(tags))
+	for _, tag := range tags {
+		byName[tag.Name] = tag.ID
+	}
+	groups := make(map[string]int64)
+	for _, part := range tmpl.Placeholders(pathtemplate.KindTag) {
+		id, ok := byName[part.Arg]
+		if !ok {
+			return nil, nil, pathtemplate.NewError(src, part.Pos, part.End, fmt.Sprintf("：标签组「%s」不存在", part.Arg))
+		}
+		groups[part.Arg] = id
+	}
+	return tmpl, groups, nil
+}
+
+// defaultOrganizeTemplate 未指定模板时与原有行为一致：每级一个目录，文件名不变
+func defaultOrganizeTemplate(levels int) string {
+	parts := make([]string, 0, levels+1)
+	for i := 1; i <= levels; i++ {
+		parts = append(parts, fmt.Sprintf("{level%d}", i))
+	}
+	return strings.Join(append(parts, "{name}{ext}"), "/")
+}
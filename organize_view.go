@@ -0,0 +1,475 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// organizeViewManifest 视图目录中的清单文件，记录本程序生成的链接；删除与重建时只处理清单内的条目
+const organizeViewManifest = ".tagexplorer-view.json"
+
+type organizeViewManifestFile struct {
+	ViewID    int64              `json:"view_id"`
+	Workspace string             `json:"workspace"`
+	LinkType  string             `json:"link_type"`
+	Links     []organizeViewLink `json:"links"`
+}
+
+type organizeViewLink struct {
+	Path   string `json:"path"`   // 相对视图目录（/ 分隔）
+	Source string `json:"source"` // 原文件的绝对路径
+}
+
+// CreateOrganizeView 按整理计划在工作区外生成链接目录树，原文件保持不动
+func (a *App) CreateOrganizeView(req api.OrganizeViewRequest) (*api.OrganizeViewResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	linkType := req.LinkType
+	switch linkType {
+	case "":
+		linkType = data.LinkSymlink
+	case data.LinkSymlink, data.LinkHardlink:
+	default:
+		return nil, fmt.Errorf("无效的链接方式: %s", req.LinkType)
+	}
+	viewPath, err := a.checkOrganizeViewPath(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	if entries, err := os.ReadDir(viewPath); err == nil && len(entries) > 0 {
+		return nil, errors.New("视图目录须不存在或为空")
+	}
+	if req.Organize.ConflictStrategy == organizeConflictQuarantine {
+		return nil, errors.New("整理视图不支持隔离区冲突处理方式")
+	}
+	raw, err := json.Marshal(req.Organize)
+	if err != nil {
+		return nil, fmt.Errorf("序列化整理条件失败: %w", err)
+	}
+
+	view, err := a.db.CreateOrganizeView(a.ctx, a.currentWorkspace.ID, viewPath, linkType, string(raw))
+	if err != nil {
+		return nil, err
+	}
+	result, err := a.buildOrganizeView(view, req.Organize)
+	if err != nil {
+		if delErr := a.db.DeleteOrganizeView(a.ctx, view.ID); delErr != nil && a.logger != nil {
+			a.logger.Warn("删除生成失败的整理视图记录失败", zap.Int64("view_id", view.ID), zap.Error(delErr))
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListOrganizeViews 返回当前工作区的整理视图
+func (a *App) ListOrganizeViews() ([]api.OrganizeView, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	views, err := a.db.ListOrganizeViews(a.ctx, a.currentWorkspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]api.OrganizeView, 0, len(views))
+	for _, view := range views {
+		item, err := toAPIOrganizeView(view)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Warn("解析整理视图失败", zap.Int64("view_id", view.ID), zap.Error(err))
+			}
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// RebuildOrganizeView 按当前标签重新生成整理视图，新目录树生成完成后才替换旧的
+func (a *App) RebuildOrganizeView(id int64) (*api.OrganizeViewResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	view, err := a.db.GetOrganizeView(a.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if view.WorkspaceID != a.currentWorkspace.ID {
+		return nil, errors.New("整理视图不属于当前工作区")
+	}
+	var req api.OrganizeRequest
+	if err := json.Unmarshal([]byte(view.Request), &req); err != nil {
+		return nil, fmt.Errorf("解析整理视图条件失败: %w", err)
+	}
+	if _, err := a.checkOrganizeViewPath(view.Path); err != nil {
+		return nil, err
+	}
+	return a.buildOrganizeView(view, req)
+}
+
+// RemoveOrganizeView 删除整理视图：移除生成的链接与空目录，并删除视图记录
+//
+// 不在清单中的文件，以及原文件已不存在的硬链接（可能是唯一副本）会保留。
+func (a *App) RemoveOrganizeView(id int64) (*api.OrganizeViewRemoval, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	view, err := a.db.GetOrganizeView(a.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &api.OrganizeViewRemoval{}
+	if _, err := os.Stat(view.Path); err == nil {
+		manifest, err := readOrganizeViewManifest(view.Path, view.ID)
+		if err != nil {
+			return nil, err
+		}
+		ours, foreign, err := classifyOrganizeViewEntries(view.Path, manifest)
+		if err != nil {
+			return nil, err
+		}
+		result.Removed, result.Kept = removeOrganizeViewEntries(view.Path, ours)
+		result.Kept = append(result.Kept, foreign...)
+		sort.Strings(result.Kept)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("读取视图目录失败: %w", err)
+	}
+
+	if err := a.db.DeleteOrganizeView(a.ctx, view.ID); err != nil {
+		return nil, err
+	}
+
+	if a.logger != nil {
+		a.logger.Info("删除整理视图",
+			zap.Int64("view_id", view.ID),
+			zap.String("path", view.Path),
+			zap.Int("removed", result.Removed),
+			zap.Int("kept", len(result.Kept)),
+		)
+	}
+	return result, nil
+}
+
+// buildOrganizeView 在视图目录旁的临时目录中生成完整的链接树，再与旧视图交换
+func (a *App) buildOrganizeView(view *data.OrganizeView, req api.OrganizeRequest) (*api.OrganizeViewResult, error) {
+	if req.ConflictStrategy == organizeConflictQuarantine {
+		return nil, errors.New("整理视图不支持隔离区冲突处理方式")
+	}
+
+	staging := filepath.Join(filepath.Dir(view.Path),
+		fmt.Sprintf(".%s.building-%d", filepath.Base(view.Path), time.Now().UnixNano()))
+	plan, err := a.buildOrganizePlan(a.currentWorkspace, req, staging, nil)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Summary.ConflictCount > 0 {
+		return nil, fmt.Errorf("存在 %d 个冲突，需先解决后再生成视图", plan.Summary.ConflictCount)
+	}
+
+	// 旧视图中有清单之外的文件时不做任何改动，避免误删用户放入的文件
+	var oldLinks []organizeViewLink
+	if _, err := os.Stat(view.Path); err == nil {
+		manifest, err := readOrganizeViewManifest(view.Path, view.ID)
+		if err != nil {
+			return nil, err
+		}
+		ours, foreign, err := classifyOrganizeViewEntries(view.Path, manifest)
+		if err != nil {
+			return nil, err
+		}
+		if len(foreign) > 0 {
+			return nil, fmt.Errorf("视图目录中有非本程序生成的文件，无法重建: %s", foreign[0])
+		}
+		oldLinks = ours
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("读取视图目录失败: %w", err)
+	}
+
+	manifest := organizeViewManifestFile{
+		ViewID:    view.ID,
+		Workspace: a.currentWorkspace.Path,
+		LinkType:  view.LinkType,
+	}
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		return nil, fmt.Errorf("创建视图目录失败: %w", err)
+	}
+	built := false
+	defer func() {
+		if !built {
+			// 临时目录只包含链接，删除不会影响原文件
+			_ = os.RemoveAll(staging)
+		}
+	}()
+
+	for _, item := range plan.Items {
+		if item.Status != "move" {
+			continue
+		}
+		source := filepath.Join(a.currentWorkspace.Path, filepath.FromSlash(item.OriginalPath))
+		link := filepath.Join(staging, filepath.FromSlash(item.TargetPath))
+		if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+			return nil, fmt.Errorf("创建视图目录失败: %w", err)
+		}
+		if view.LinkType == data.LinkHardlink {
+			err = os.Link(source, link)
+		} else {
+			err = os.Symlink(source, link)
+		}
+		if err != nil {
+			if view.LinkType == data.LinkHardlink {
+				return nil, fmt.Errorf("创建硬链接失败（视图目录须与工作区位于同一设备）: %w", err)
+			}
+			return nil, fmt.Errorf("创建符号链接失败: %w", err)
+		}
+		manifest.Links = append(manifest.Links, organizeViewLink{Path: item.TargetPath, Source: source})
+	}
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化视图清单失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(staging, organizeViewManifest), raw, 0o644); err != nil {
+		return nil, fmt.Errorf("写入视图清单失败: %w", err)
+	}
+
+	// 先把旧视图移到一旁，新视图就位后再清理旧视图
+	var aside string
+	if dirExists(view.Path) {
+		aside = staging + ".old"
+		if err := os.Rename(view.Path, aside); err != nil {
+			return nil, fmt.Errorf("替换旧视图失败: %w", err)
+		}
+	}
+	if err := os.Rename(staging, view.Path); err != nil {
+		if aside != "" {
+			_ = os.Rename(aside, view.Path)
+		}
+		return nil, fmt.Errorf("替换旧视图失败: %w", err)
+	}
+	built = true
+	if aside != "" {
+		if _, kept := removeOrganizeViewEntries(aside, oldLinks); len(kept) > 0 && a.logger != nil {
+			a.logger.Warn("清理旧视图时有文件未能删除", zap.String("path", aside), zap.Strings("kept", kept))
+		}
+	}
+
+	if err := a.db.SetOrganizeViewBuilt(a.ctx, view.ID, len(manifest.Links)); err != nil {
+		return nil, err
+	}
+	updated, err := a.db.GetOrganizeView(a.ctx, view.ID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := toAPIOrganizeView(*updated)
+	if err != nil {
+		return nil, err
+	}
+	plan.BasePath = view.Path
+
+	if a.logger != nil {
+		a.logger.Info("生成整理视图",
+			zap.Int64("view_id", view.ID),
+			zap.String("path", view.Path),
+			zap.String("link_type", view.LinkType),
+			zap.Int("links", len(manifest.Links)),
+		)
+	}
+	return &api.OrganizeViewResult{View: item, Preview: *plan}, nil
+}
+
+// checkOrganizeViewPath 校验视图目录：须为绝对路径、上级目录存在，且与当前工作区互不包含
+func (a *App) checkOrganizeViewPath(viewPath string) (string, error) {
+	viewPath = strings.TrimSpace(viewPath)
+	if viewPath == "" || !filepath.IsAbs(viewPath) {
+		return "", errors.New("视图目录须为绝对路径")
+	}
+	viewPath = filepath.Clean(viewPath)
+
+	parent, err := filepath.EvalSymlinks(filepath.Dir(viewPath))
+	if err != nil {
+		return "", fmt.Errorf("视图目录的上级目录不可用: %w", err)
+	}
+	realView := filepath.Join(parent, filepath.Base(viewPath))
+	if resolved, err := filepath.EvalSymlinks(realView); err == nil {
+		realView = resolved
+	}
+	realWorkspace, err := filepath.EvalSymlinks(a.currentWorkspace.Path)
+	if err != nil {
+		realWorkspace = a.currentWorkspace.Path
+	}
+	if pathWithin(realView, realWorkspace) || pathWithin(realWorkspace, realView) {
+		return "", errors.New("视图目录不能位于工作区内，也不能包含工作区")
+	}
+
+	if info, err := os.Stat(viewPath); err == nil && !info.IsDir() {
+		return "", errors.New("视图目录与已有文件同名")
+	}
+	return viewPath, nil
+}
+
+// pathWithin 判断 target 是否为 base 本身或其子路径
+func pathWithin(target, base string) bool {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+func dirExists(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
+
+// readOrganizeViewManifest 读取视图清单；目录为空时视为没有清单
+func readOrganizeViewManifest(root string, viewID int64) (*organizeViewManifestFile, error) {
+	raw, err := os.ReadFile(filepath.Join(root, organizeViewManifest))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &organizeViewManifestFile{ViewID: viewID}, nil
+		}
+		return nil, fmt.Errorf("读取视图清单失败: %w", err)
+	}
+	var manifest organizeViewManifestFile
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("解析视图清单失败: %w", err)
+	}
+	if manifest.ViewID != viewID {
+		return nil, errors.New("视图目录属于其他整理视图")
+	}
+	return &manifest, nil
+}
+
+// classifyOrganizeViewEntries 区分视图目录中可以安全删除的链接与其他文件（相对路径）
+//
+// 符号链接须仍为链接；硬链接须仍与原文件是同一文件，否则它可能是唯一的副本。
+func classifyOrganizeViewEntries(root string, manifest *organizeViewManifestFile) ([]organizeViewLink, []string, error) {
+	links := make(map[string]organizeViewLink, len(manifest.Links))
+	for _, link := range manifest.Links {
+		links[link.Path] = link
+	}
+
+	var ours []organizeViewLink
+	var foreign []string
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == organizeViewManifest {
+			return nil
+		}
+		link, ok := links[rel]
+		if ok && isOrganizeViewLink(p, link, manifest.LinkType) {
+			ours = append(ours, link)
+			return nil
+		}
+		foreign = append(foreign, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取视图目录失败: %w", err)
+	}
+	return ours, foreign, nil
+}
+
+func isOrganizeViewLink(p string, link organizeViewLink, linkType string) bool {
+	info, err := os.Lstat(p)
+	if err != nil {
+		return false
+	}
+	if linkType != data.LinkHardlink {
+		return info.Mode()&os.ModeSymlink != 0
+	}
+	source, err := os.Stat(link.Source)
+	return err == nil && info.Mode().IsRegular() && os.SameFile(info, source)
+}
+
+// removeOrganizeViewEntries 删除链接、清单与由此变空的目录，返回删除数与未能删除的文件
+func removeOrganizeViewEntries(root string, links []organizeViewLink) (int, []string) {
+	removed := 0
+	var kept []string
+	for _, link := range links {
+		if err := os.Remove(filepath.Join(root, filepath.FromSlash(link.Path))); err != nil {
+			kept = append(kept, link.Path)
+			continue
+		}
+		removed++
+	}
+	_ = os.Remove(filepath.Join(root, organizeViewManifest))
+
+	// 自深向浅删除空目录，非空目录删除失败即保留
+	var dirs []string
+	_ = filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.IsDir() {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		_ = os.Remove(dir)
+	}
+	return removed, kept
+}
+
+func toAPIOrganizeView(view data.OrganizeView) (api.OrganizeView, error) {
+	var req api.OrganizeRequest
+	if err := json.Unmarshal([]byte(view.Request), &req); err != nil {
+		return api.OrganizeView{}, fmt.Errorf("解析整理视图条件失败: %w", err)
+	}
+	item := api.OrganizeView{
+		ID:          view.ID,
+		WorkspaceID: view.WorkspaceID,
+		Path:        view.Path,
+		LinkType:    view.LinkType,
+		Organize:    req,
+		LinkCount:   view.LinkCount,
+		CreatedAt:   formatTime(view.CreatedAt),
+	}
+	if view.BuiltAt.Valid {
+		item.BuiltAt = formatTime(view.BuiltAt.Time)
+	}
+	return item, nil
+}
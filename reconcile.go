@@ -0,0 +1,279 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// 标签修复策略
+const (
+	reconcileDiskWins = "disk"  // 以文件名为准
+	reconcileDBWins   = "db"    // 以数据库为准
+	reconcileUnion    = "union" // 取并集
+)
+
+// tagActionReconcile 标签修复在操作日志中的动作名
+const tagActionReconcile = "reconcile"
+
+// ReconcileTags 分析工作区中文件名标签与数据库标签不一致的文件（只读，不触磁盘）
+func (a *App) ReconcileTags(workspaceID int64) (*api.TagReconcileReport, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if workspaceID <= 0 && a.currentWorkspace != nil {
+		workspaceID = a.currentWorkspace.ID
+	}
+	if workspaceID <= 0 {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	report := &api.TagReconcileReport{
+		WorkspaceID: workspaceID,
+		Items:       make([]api.TagReconcileItem, 0),
+	}
+
+	const batchSize = 1000
+	offset := 0
+	for {
+		page, err := a.db.ListFiles(a.ctx, workspaceID, batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("获取文件列表失败: %w", err)
+		}
+		if len(page.Records) == 0 {
+			break
+		}
+
+		for _, file := range page.Records {
+			if file.Type != data.FileTypeRegular {
+				continue
+			}
+			report.ScannedCount++
+
+			diskTags := a.parseTagsFromFileName(file.Name)
+			onlyDisk, onlyDB := a.diffFileTags(diskTags, file.Tags)
+			if len(onlyDisk) == 0 && len(onlyDB) == 0 {
+				continue
+			}
+			// 因文件名长度限制而仅保存在数据库中的标签不算差异
+			if len(onlyDisk) == 0 && a.generateFileNameWithTags(file.Name, file.Tags) == file.Name {
+				continue
+			}
+
+			dbTags := make([]string, 0, len(file.Tags))
+			for _, tag := range file.Tags {
+				dbTags = append(dbTags, tag.Name)
+			}
+			report.Items = append(report.Items, api.TagReconcileItem{
+				FileID:     file.ID,
+				Path:       file.Path,
+				Name:       file.Name,
+				DiskTags:   diskTags,
+				DBTags:     dbTags,
+				OnlyOnDisk: onlyDisk,
+				OnlyInDB:   onlyDB,
+			})
+		}
+
+		if len(page.Records) < batchSize {
+			break
+		}
+		offset += batchSize
+	}
+
+	if a.logger != nil {
+		a.logger.Info("完成标签一致性分析",
+			zap.Int64("workspace_id", workspaceID),
+			zap.Int("scanned", report.ScannedCount),
+			zap.Int("mismatched", len(report.Items)),
+		)
+	}
+
+	return report, nil
+}
+
+// ApplyTagReconcile 按策略修复标签差异，最终通过常规重命名流程写回文件名
+func (a *App) ApplyTagReconcile(req api.TagReconcileRequest) (*api.TagReconcileResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+	if req.WorkspaceID > 0 && req.WorkspaceID != a.currentWorkspace.ID {
+		return nil, errors.New("当前工作区与修复请求不一致，请先切换到原工作区")
+	}
+	if req.Strategy != "" && !isValidReconcileStrategy(req.Strategy) {
+		return nil, fmt.Errorf("无效的修复策略: %s", req.Strategy)
+	}
+	if len(req.Resolutions) == 0 {
+		return nil, errors.New("至少需要选择一个文件")
+	}
+
+	result := &api.TagReconcileResult{
+		Items: make([]api.TagReconcileApplyItem, 0, len(req.Resolutions)),
+	}
+	var records []api.FileChangeRecord
+	for _, resolution := range req.Resolutions {
+		strategy := resolution.Strategy
+		if strategy == "" {
+			strategy = req.Strategy
+		}
+
+		item := api.TagReconcileApplyItem{FileID: resolution.FileID, Strategy: strategy}
+		record, err := a.applyTagReconcile(resolution.FileID, strategy)
+		if record != nil {
+			records = append(records, *record)
+		}
+		if err != nil {
+			item.Message = err.Error()
+			result.Failed++
+			if a.logger != nil {
+				a.logger.Warn("修复文件标签失败",
+					zap.Int64("file_id", resolution.FileID),
+					zap.String("strategy", strategy),
+					zap.Error(err),
+				)
+			}
+		} else {
+			item.Success = true
+			result.Applied++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	// 整批修复记录为一次操作，便于整体撤销
+	if len(records) > 0 {
+		a.recordOperation(data.OperationTag, a.currentWorkspace.ID,
+			fmt.Sprintf("修复 %d 个文件的标签", len(records)),
+			api.FileOperationPayload{
+				WorkspaceID: a.currentWorkspace.ID,
+				Action:      tagActionReconcile,
+				Changes:     records,
+			},
+		)
+	}
+
+	if a.logger != nil {
+		a.logger.Info("完成标签修复",
+			zap.Int64("workspace_id", a.currentWorkspace.ID),
+			zap.Int("applied", result.Applied),
+			zap.Int("failed", result.Failed),
+		)
+	}
+
+	return result, nil
+}
+
+// applyTagReconcile 对单个文件应用修复策略，返回用于撤销的变化记录（文件未发生变化或修复失败时为 nil）
+//
+// 新标签需要先写入数据库才能生成文件名，重命名失败时再恢复原有标签。
+func (a *App) applyTagReconcile(fileID int64, strategy string) (*api.FileChangeRecord, error) {
+	if !isValidReconcileStrategy(strategy) {
+		return nil, fmt.Errorf("无效的修复策略: %s", strategy)
+	}
+
+	file, err := a.db.GetFileByID(a.ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	if file.WorkspaceID != a.currentWorkspace.ID {
+		return nil, errors.New("文件不属于当前工作区")
+	}
+	if file.Type != data.FileTypeRegular {
+		return nil, errors.New("仅支持修复普通文件")
+	}
+	record := &api.FileChangeRecord{FileID: fileID, Before: tagIDsOf(file.Tags)}
+
+	diskTags := a.parseTagsFromFileName(file.Name)
+	onlyDisk, _ := a.diffFileTags(diskTags, file.Tags)
+
+	switch strategy {
+	case reconcileDiskWins:
+		// 文件名中的标签可能经过字符替换，优先沿用数据库中对应的原始名称
+		dbNames := make(map[string]string, len(file.Tags))
+		for _, tag := range file.Tags {
+			dbNames[a.tagCompareKey(tag.Name)] = tag.Name
+		}
+		names := make([]string, 0, len(diskTags))
+		for _, name := range diskTags {
+			if original, ok := dbNames[a.tagCompareKey(name)]; ok {
+				name = original
+			}
+			names = append(names, name)
+		}
+		if err := a.db.ReplaceFileTagsByName(a.ctx, file.ID, names); err != nil {
+			return nil, err
+		}
+	case reconcileUnion:
+		if err := a.db.BatchAddTagsToFile(a.ctx, file.ID, onlyDisk); err != nil {
+			return nil, err
+		}
+	case reconcileDBWins:
+		// 数据库保持不变，只需按数据库标签重写文件名
+	}
+
+	updated, err := a.db.GetFileByID(a.ctx, file.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	record.After = tagIDsOf(updated.Tags)
+
+	if _, err := a.renameFileWithTags(file.ID, record); err != nil {
+		// 文件名未能同步时恢复原有标签，避免数据库与文件名出现新的不一致
+		if !sameIDs(record.Before, record.After) {
+			if _, restoreErr := a.db.RestoreFileTags(a.ctx, map[int64][]int64{file.ID: record.Before}); restoreErr != nil {
+				return nil, fmt.Errorf("重命名文件失败: %w（恢复原有标签失败: %v）", err, restoreErr)
+			}
+		}
+		return nil, fmt.Errorf("重命名文件失败，标签未修改: %w", err)
+	}
+	if sameIDs(record.Before, record.After) && record.From == "" {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// diffFileTags 比较文件名标签与数据库标签，返回各自独有的部分
+func (a *App) diffFileTags(diskTags []string, dbTags []data.Tag) (onlyDisk, onlyDB []string) {
+	diskSet := make(map[string]struct{}, len(diskTags))
+	for _, name := range diskTags {
+		diskSet[a.tagCompareKey(name)] = struct{}{}
+	}
+	dbSet := make(map[string]struct{}, len(dbTags))
+	for _, tag := range dbTags {
+		key := a.tagCompareKey(tag.Name)
+		dbSet[key] = struct{}{}
+		if _, ok := diskSet[key]; !ok {
+			onlyDB = append(onlyDB, tag.Name)
+		}
+	}
+	for _, name := range diskTags {
+		if _, ok := dbSet[a.tagCompareKey(name)]; !ok {
+			onlyDisk = append(onlyDisk, name)
+		}
+	}
+	return onlyDisk, onlyDB
+}
+
+// tagCompareKey 生成标签比较键：与写入文件名时的清理规则一致，且不区分大小写
+func (a *App) tagCompareKey(name string) string {
+	return strings.ToLower(a.sanitizeFileNamePart(strings.TrimSpace(name)))
+}
+
+func isValidReconcileStrategy(strategy string) bool {
+	switch strategy {
+	case reconcileDiskWins, reconcileDBWins, reconcileUnion:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"tagexplorer/internal/api"
+)
+
+func TestApplyTagReconcileRenameFailureKeepsTags(t *testing.T) {
+	a, root := newTestApp(t, "report [x].txt")
+	fileID := testFileID(t, a, "report [x].txt")
+
+	// 数据库中的标签改为 y，与文件名中的 x 不一致
+	file, err := a.db.GetFileByID(a.ctx, fileID)
+	if err != nil || len(file.Tags) != 1 {
+		t.Fatalf("file = %+v, %v, want the tag parsed from its name", file, err)
+	}
+	if err := a.db.RemoveTagFromFile(a.ctx, fileID, file.Tags[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	tagY, err := a.db.CreateTag(a.ctx, "y", "#000000", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.db.AddTagToFile(a.ctx, fileID, tagY.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	a.rename = func(oldpath, newpath string) error { return errors.New("disk full") }
+	result, err := a.ApplyTagReconcile(api.TagReconcileRequest{
+		WorkspaceID: a.currentWorkspace.ID,
+		Strategy:    reconcileUnion,
+		Resolutions: []api.TagReconcileResolution{{FileID: fileID}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Failed != 1 || result.Applied != 0 {
+		t.Errorf("result = %+v, want one failure", result)
+	}
+
+	file, err = a.db.GetFileByID(a.ctx, fileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Tags) != 1 || file.Tags[0].ID != tagY.ID {
+		t.Errorf("tags = %+v, want only y restored", file.Tags)
+	}
+	assertExists(t, root, "report [x].txt", true)
+	if ops, err := a.ListOperations(10); err != nil || len(ops) != 0 {
+		t.Errorf("operations = %+v, %v, want nothing journaled for the failed repair", ops, err)
+	}
+}
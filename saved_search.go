@@ -0,0 +1,424 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+	"tagexplorer/internal/tagquery"
+)
+
+// maxSavedSearchNameLength 保存搜索名称的最大字符数
+const maxSavedSearchNameLength = 100
+
+// SavedSearchConfig 工作区配置文件中的保存搜索
+//
+// 标签 ID 只在本机数据库中有效，导出时统一改写为按名称匹配的标签表达式。
+type SavedSearchConfig struct {
+	Name   string               `json:"name"`
+	Folder string               `json:"folder,omitempty"` // 所属文件夹，为空表示全局
+	Params api.FileSearchParams `json:"params"`
+}
+
+// ListSavedSearches 返回全局保存搜索与当前工作区的保存搜索
+func (a *App) ListSavedSearches() ([]api.SavedSearch, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	var workspaceID int64
+	if a.currentWorkspace != nil {
+		workspaceID = a.currentWorkspace.ID
+	}
+	searches, err := a.db.ListSavedSearches(a.ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]api.SavedSearch, 0, len(searches))
+	for _, search := range searches {
+		item, err := toAPISavedSearch(search)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Warn("解析保存搜索失败", zap.Int64("saved_search_id", search.ID), zap.Error(err))
+			}
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// CreateSavedSearch 保存当前搜索条件，global 为 true 时对所有工作区可用
+func (a *App) CreateSavedSearch(name string, params api.FileSearchParams, global bool) (*api.SavedSearch, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	var workspaceID int64
+	if !global {
+		if a.currentWorkspace == nil {
+			return nil, errors.New("尚未选择工作区")
+		}
+		workspaceID = a.currentWorkspace.ID
+	}
+
+	query, err := a.encodeSavedSearch(name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	search, err := a.db.CreateSavedSearch(a.ctx, workspaceID, name, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.logger != nil {
+		a.logger.Info("新增保存搜索",
+			zap.Int64("saved_search_id", search.ID),
+			zap.String("name", search.Name),
+			zap.Bool("global", global),
+		)
+	}
+
+	item, err := toAPISavedSearch(*search)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateSavedSearch 更新保存搜索的名称与条件
+func (a *App) UpdateSavedSearch(id int64, name string, params api.FileSearchParams) (*api.SavedSearch, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	query, err := a.encodeSavedSearch(name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	search, err := a.db.UpdateSavedSearch(a.ctx, id, name, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.logger != nil {
+		a.logger.Info("更新保存搜索", zap.Int64("saved_search_id", id), zap.String("name", search.Name))
+	}
+
+	item, err := toAPISavedSearch(*search)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// DeleteSavedSearch 删除保存搜索
+func (a *App) DeleteSavedSearch(id int64) error {
+	if a.db == nil {
+		return errors.New("数据库尚未准备就绪")
+	}
+
+	if err := a.db.DeleteSavedSearch(a.ctx, id); err != nil {
+		return err
+	}
+
+	if a.logger != nil {
+		a.logger.Info("删除保存搜索", zap.Int64("saved_search_id", id))
+	}
+	return nil
+}
+
+// ExecuteSavedSearch 执行保存搜索并按游标分页返回结果，cursor 为空时从第一页开始并统计总数
+//
+// 全局搜索在当前工作区执行；属于某个工作区的搜索始终在该工作区执行。
+func (a *App) ExecuteSavedSearch(id int64, cursor string) (*api.FileCursorPage, error) {
+	if a.ctx == nil {
+		return nil, errors.New("应用尚未初始化")
+	}
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	search, err := a.db.GetSavedSearch(a.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	params, err := decodeSavedSearch(search.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceID := search.WorkspaceID.Int64
+	if !search.WorkspaceID.Valid {
+		if a.currentWorkspace == nil {
+			return nil, errors.New("尚未选择工作区")
+		}
+		workspaceID = a.currentWorkspace.ID
+	}
+
+	query, err := toFileQuery(workspaceID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := a.db.SearchFilesByCursor(a.ctx, query, cursor, cursor == "")
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("执行保存搜索失败",
+				zap.Int64("saved_search_id", id),
+				zap.Int64("workspace_id", workspaceID),
+				zap.Error(err),
+			)
+		}
+		return nil, err
+	}
+
+	return toAPIFileCursorPage(page), nil
+}
+
+// encodeSavedSearch 校验名称与搜索条件，并序列化为存储格式（不保存分页状态）
+func (a *App) encodeSavedSearch(name string, params api.FileSearchParams) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("保存搜索的名称不能为空")
+	}
+	if len([]rune(name)) > maxSavedSearchNameLength {
+		return "", fmt.Errorf("保存搜索的名称不能超过 %d 个字符", maxSavedSearchNameLength)
+	}
+
+	params.Cursor = ""
+	params.Offset = 0
+	params.IncludeTotal = false
+	params.TagQuery = strings.TrimSpace(params.TagQuery)
+
+	if _, err := toFileQuery(0, params); err != nil {
+		return "", err
+	}
+	if params.TagQuery != "" {
+		if _, err := a.db.ValidateTagQuery(a.ctx, params.TagQuery); err != nil {
+			return "", err
+		}
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("序列化搜索条件失败: %w", err)
+	}
+	return string(raw), nil
+}
+
+func decodeSavedSearch(query string) (api.FileSearchParams, error) {
+	var params api.FileSearchParams
+	if err := json.Unmarshal([]byte(query), &params); err != nil {
+		return api.FileSearchParams{}, fmt.Errorf("解析保存的搜索条件失败: %w", err)
+	}
+	return params, nil
+}
+
+func toAPISavedSearch(search data.SavedSearch) (api.SavedSearch, error) {
+	params, err := decodeSavedSearch(search.Query)
+	if err != nil {
+		return api.SavedSearch{}, err
+	}
+
+	var workspaceID *int64
+	if search.WorkspaceID.Valid {
+		value := search.WorkspaceID.Int64
+		workspaceID = &value
+	}
+	return api.SavedSearch{
+		ID:          search.ID,
+		WorkspaceID: workspaceID,
+		Name:        search.Name,
+		Params:      params,
+		CreatedAt:   formatTime(search.CreatedAt),
+		UpdatedAt:   formatTime(search.UpdatedAt),
+	}, nil
+}
+
+// exportSavedSearches 收集全局保存搜索及指定文件夹对应工作区的保存搜索，用于写入工作区配置文件
+func (a *App) exportSavedSearches(folders []string) ([]SavedSearchConfig, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	workspaces, err := a.db.ListWorkspaces(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 全局搜索只需收集一次，其余按文件夹顺序收集
+	scopes := []int64{0}
+	folderOf := make(map[int64]string)
+	for _, folder := range folders {
+		absPath, err := filepath.Abs(folder)
+		if err != nil {
+			continue
+		}
+		for _, ws := range workspaces {
+			if ws.Path == absPath {
+				folderOf[ws.ID] = folder
+				scopes = append(scopes, ws.ID)
+			}
+		}
+	}
+
+	tags, err := a.db.ListTags(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	tagNames := make(map[int64]string, len(tags))
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+	}
+
+	var result []SavedSearchConfig
+	seen := make(map[int64]bool)
+	for _, scope := range scopes {
+		searches, err := a.db.ListSavedSearches(a.ctx, scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, search := range searches {
+			if seen[search.ID] {
+				continue
+			}
+			seen[search.ID] = true
+
+			params, err := decodeSavedSearch(search.Query)
+			if err != nil {
+				if a.logger != nil {
+					a.logger.Warn("跳过无法解析的保存搜索", zap.Int64("saved_search_id", search.ID), zap.Error(err))
+				}
+				continue
+			}
+
+			// 将标签 ID 改写为标签名表达式
+			terms := make([]string, 0, len(params.TagIDs)+1)
+			for _, tagID := range params.TagIDs {
+				name, ok := tagNames[tagID]
+				if !ok {
+					if a.logger != nil {
+						a.logger.Warn("保存搜索引用的标签已不存在", zap.Int64("saved_search_id", search.ID), zap.Int64("tag_id", tagID))
+					}
+					continue
+				}
+				terms = append(terms, tagquery.Quote(name))
+			}
+			if len(terms) > 0 {
+				if params.TagQuery != "" {
+					terms = append(terms, "("+params.TagQuery+")")
+				}
+				params.TagQuery = strings.Join(terms, " AND ")
+			}
+			params.TagIDs = nil
+
+			result = append(result, SavedSearchConfig{
+				Name:   search.Name,
+				Folder: folderOf[search.WorkspaceID.Int64],
+				Params: params,
+			})
+		}
+	}
+	return result, nil
+}
+
+// savedSearchesForConfig 导出保存搜索写入配置文件，失败时仅记录警告，不影响配置保存
+func (a *App) savedSearchesForConfig(folders []string) []SavedSearchConfig {
+	searches, err := a.exportSavedSearches(folders)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Warn("导出保存搜索失败", zap.Error(err))
+		}
+		return nil
+	}
+	return searches
+}
+
+// importSavedSearches 导入工作区配置文件中的保存搜索，返回因本地已有同名搜索而跳过的名称
+//
+// 本地同名搜索保持不变；只导入全局搜索与属于配置中文件夹（folders）的搜索，不会为其他文件夹创建工作区。
+func (a *App) importSavedSearches(folders []string, searches []SavedSearchConfig) []string {
+	if a.db == nil || len(searches) == 0 {
+		return nil
+	}
+
+	configFolders := make(map[string]bool, len(folders))
+	for _, folder := range folders {
+		if absPath, err := filepath.Abs(folder); err == nil {
+			configFolders[absPath] = true
+		}
+	}
+	workspaceIDs := make(map[string]int64)
+
+	imported := 0
+	var skipped []string
+	for _, item := range searches {
+		var workspaceID int64
+		if item.Folder != "" {
+			absPath, err := filepath.Abs(item.Folder)
+			if err != nil || !configFolders[absPath] {
+				if a.logger != nil {
+					a.logger.Warn("保存搜索所属文件夹不在工作区配置中，已跳过", zap.String("name", item.Name), zap.String("folder", item.Folder))
+				}
+				continue
+			}
+			if _, err := os.Stat(absPath); err != nil {
+				if a.logger != nil {
+					a.logger.Warn("保存搜索所属文件夹不可用，已跳过", zap.String("name", item.Name), zap.String("folder", item.Folder))
+				}
+				continue
+			}
+			id, ok := workspaceIDs[absPath]
+			if !ok {
+				ws, err := a.db.UpsertWorkspace(a.ctx, absPath, filepath.Base(absPath))
+				if err != nil {
+					if a.logger != nil {
+						a.logger.Warn("导入保存搜索失败", zap.String("name", item.Name), zap.Error(err))
+					}
+					continue
+				}
+				id = ws.ID
+				workspaceIDs[absPath] = id
+			}
+			workspaceID = id
+		}
+
+		params := item.Params
+		params.TagIDs = nil
+		raw, err := json.Marshal(params)
+		created := false
+		if err == nil {
+			created, err = a.db.InsertSavedSearchIfAbsent(a.ctx, workspaceID, item.Name, string(raw))
+		}
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Warn("导入保存搜索失败", zap.String("name", item.Name), zap.Error(err))
+			}
+			continue
+		}
+		if !created {
+			skipped = append(skipped, item.Name)
+			continue
+		}
+		imported++
+	}
+
+	if a.logger != nil {
+		a.logger.Info("导入保存搜索",
+			zap.Int("total", len(searches)),
+			zap.Int("imported", imported),
+			zap.Strings("skipped_existing", skipped),
+		)
+	}
+	return skipped
+}
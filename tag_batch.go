@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// AddTagsToFiles 为多个文件添加标签，标签变更在单个事务中完成后再逐个重命名文件
+func (a *App) AddTagsToFiles(fileIDs, tagIDs []int64) (*api.BatchTagResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.batchUpdateFileTags(data.TagBatchAdd, fileIDs, tagIDs)
+}
+
+// RemoveTagsFromFiles 从多个文件移除标签，标签变更在单个事务中完成后再逐个重命名文件
+func (a *App) RemoveTagsFromFiles(fileIDs, tagIDs []int64) (*api.BatchTagResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.batchUpdateFileTags(data.TagBatchRemove, fileIDs, tagIDs)
+}
+
+// SetTagsForFiles 将多个文件的标签统一设置为指定标签（tagIDs 为空表示清空）
+func (a *App) SetTagsForFiles(fileIDs, tagIDs []int64) (*api.BatchTagResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+	return a.batchUpdateFileTags(data.TagBatchSet, fileIDs, tagIDs)
+}
+
+// batchUpdateFileTags 执行批量标签操作：事务内更新标签，随后重命名有变化的文件，并记录为一次可撤销的操作
+//
+// 标签更新要么全部成功要么全部回滚；重命名失败不影响标签变更，逐个记录在结果中。
+func (a *App) batchUpdateFileTags(action string, fileIDs, tagIDs []int64) (*api.BatchTagResult, error) {
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+	if a.currentWorkspace == nil {
+		return nil, errors.New("尚未选择工作区")
+	}
+
+	changes, err := a.db.BatchUpdateFileTags(a.ctx, a.currentWorkspace.ID, action, fileIDs, tagIDs)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("批量更新文件标签失败",
+				zap.String("action", action),
+				zap.Int("file_count", len(fileIDs)),
+				zap.Int64s("tag_ids", tagIDs),
+				zap.Error(err),
+			)
+		}
+		return nil, err
+	}
+
+	result := &api.BatchTagResult{
+		Requested: len(fileIDs),
+		Changed:   len(changes),
+	}
+	if len(changes) == 0 {
+		result.Items = []api.TagRenameResult{}
+		return result, nil
+	}
+
+	records, items := a.renameForTagChanges(changes, "批量更新标签后重命名文件失败")
+	for _, item := range items {
+		switch item.Status {
+		case "renamed":
+			result.Renamed++
+		case "failed":
+			result.RenameFailed++
+		}
+	}
+	result.Items = items
+
+	summary := fmt.Sprintf("%s %d 个文件的标签", tagActionLabel(action), len(changes))
+	result.OperationID = a.recordOperation(data.OperationTag, a.currentWorkspace.ID, summary, api.FileOperationPayload{
+		WorkspaceID: a.currentWorkspace.ID,
+		Action:      action,
+		TagIDs:      tagIDs,
+		Changes:     records,
+	})
+
+	if a.logger != nil {
+		a.logger.Info("批量更新文件标签完成",
+			zap.String("action", action),
+			zap.Int("requested", result.Requested),
+			zap.Int("changed", result.Changed),
+			zap.Int("renamed", result.Renamed),
+			zap.Int("rename_failed", result.RenameFailed),
+			zap.Int64("operation_id", result.OperationID),
+		)
+	}
+	return result, nil
+}
+
+// updateSingleFileTags 单个文件的标签变更，与批量操作共用事务、重命名与操作日志流程
+func (a *App) updateSingleFileTags(action string, fileID int64, tagIDs []int64) (*api.TagRenameResult, error) {
+	result, err := a.batchUpdateFileTags(action, []int64{fileID}, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Items) > 0 {
+		return &result.Items[0], nil
+	}
+
+	// 标签没有变化，文件名无需调整
+	file, err := a.db.GetFileByID(a.ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	return &api.TagRenameResult{
+		FileID:  fileID,
+		OldName: file.Name,
+		NewName: file.Name,
+		Status:  "unchanged",
+	}, nil
+}
+
+// renameForTagChanges 按新标签重命名当前工作区中标签发生变化的文件，返回用于撤销的变化记录与重命名结果
+//
+// 其他工作区的文件只记录标签变化，不做重命名。
+func (a *App) renameForTagChanges(changes []data.FileTagChange, warnMsg string) ([]api.FileChangeRecord, []api.TagRenameResult) {
+	records := make([]api.FileChangeRecord, 0, len(changes))
+	items := make([]api.TagRenameResult, 0, len(changes))
+	for _, change := range changes {
+		record := api.FileChangeRecord{
+			FileID: change.FileID,
+			Before: change.Before,
+			After:  change.After,
+		}
+		if a.inCurrentWorkspace(change.FileID) {
+			items = append(items, *a.renameAfterTagChange(change.FileID, &record, warnMsg))
+		}
+		records = append(records, record)
+	}
+	return records, items
+}
+
+// inCurrentWorkspace 判断文件是否属于当前工作区
+func (a *App) inCurrentWorkspace(fileID int64) bool {
+	if a.currentWorkspace == nil {
+		return false
+	}
+	file, err := a.db.GetFileByID(a.ctx, fileID)
+	return err == nil && file.WorkspaceID == a.currentWorkspace.ID
+}
+
+func tagActionLabel(action string) string {
+	switch action {
+	case data.TagBatchAdd:
+		return "添加"
+	case data.TagBatchRemove:
+		return "移除"
+	case data.TagBatchClear:
+		return "清除"
+	case tagActionReconcile:
+		return "修复"
+	default:
+		return "设置"
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"tagexplorer/internal/api"
+	"tagexplorer/internal/data"
+)
+
+// 标签编辑动作
+const (
+	tagEditRename = "rename"
+	tagEditMerge  = "merge"
+	tagEditDelete = "delete"
+)
+
+// RenameTag 修改标签名称，并同步重命名当前工作区中带有该标签的文件
+func (a *App) RenameTag(id int64, newName string) (*api.TagEditResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	before, err := a.db.RenameTag(a.ctx, id, newName)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("重命名标签失败", zap.Int64("tag_id", id), zap.String("name", newName), zap.Error(err))
+		}
+		return nil, err
+	}
+	after, err := a.db.GetTag(a.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if after.Name == before.Name {
+		return &api.TagEditResult{Items: []api.TagRenameResult{}}, nil
+	}
+
+	fileIDs, err := a.db.ListFileIDsByTag(a.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]data.FileTagChange, 0, len(fileIDs))
+	for _, fileID := range fileIDs {
+		changes = append(changes, data.FileTagChange{FileID: fileID})
+	}
+	records, items := a.renameForTagChanges(changes, "重命名标签后重命名文件失败")
+
+	// 标签集合没有变化，只保留实际重命名的文件
+	renamed := records[:0]
+	for _, record := range records {
+		if record.From != "" {
+			renamed = append(renamed, record)
+		}
+	}
+
+	summary := fmt.Sprintf("重命名标签「%s」为「%s」", before.Name, after.Name)
+	return a.finishTagEdit(summary, api.TagEditPayload{
+		Action:  tagEditRename,
+		Tag:     toAPITagSnapshot(data.TagSnapshot{Tag: *before}),
+		NewName: after.Name,
+		Changes: renamed,
+	}, 0, items), nil
+}
+
+// MergeTags 将 source 标签合并到 target，并同步重命名当前工作区中受影响的文件
+func (a *App) MergeTags(sourceID, targetID int64) (*api.TagEditResult, error) {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if a.db == nil {
+		return nil, errors.New("数据库尚未准备就绪")
+	}
+
+	target, err := a.db.GetTag(a.ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, changes, err := a.db.MergeTags(a.ctx, sourceID, targetID)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("合并标签失败", zap.Int64("source_id", sourceID), zap.Int64("target_id", targetID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	records, items := a.renameForTagChanges(changes, "合并标签后重命名文件失败")
+	summary := fmt.Sprintf("合并标签「%s」到「%s」", snapshot.Tag.Name, target.Name)
+	return a.finishTagEdit(summary, api.TagEditPayload{
+		Action:      tagEditMerge,
+		Tag:         toAPITagSnapshot(*snapshot),
+		TargetTagID: targetID,
+		Changes:     records,
+	}, len(changes), items), nil
+}
+
+// DeleteTag 删除标签，并同步重命名当前工作区中带有该标签的文件
+//
+// 重命名结果与操作 ID 记录在操作日志中，可通过 ListOperations 查看并撤销。
+func (a *App) DeleteTag(id int64) error {
+	a.opMu.Lock()
+	defer a.opMu.Unlock()
+
+	if a.db == nil {
+		return errors.New("数据库尚未准备就绪")
+	}
+
+	snapshot, changes, err := a.db.DeleteTagWithChanges(a.ctx, id)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("删除标签失败", zap.Int64("tag_id", id), zap.Error(err))
+		}
+		return err
+	}
+
+	records, items := a.renameForTagChanges(changes, "删除标签后重命名文件失败")
+	summary := fmt.Sprintf("删除标签「%s」", snapshot.Tag.Name)
+	a.finishTagEdit(summary, api.TagEditPayload{
+		Action:  tagEditDelete,
+		Tag:     toAPITagSnapshot(*snapshot),
+		Changes: records,
+	}, len(changes), items)
+	return nil
+}
+
+// finishTagEdit 汇总重命名结果并写入操作日志
+//
+// 只有发生了文件重命名的操作才绑定到当前工作区，纯标签变化在任意工作区都可撤销。
+func (a *App) finishTagEdit(summary string, payload api.TagEditPayload, changed int, items []api.TagRenameResult) *api.TagEditResult {
+	result := &api.TagEditResult{Changed: changed, Items: items}
+	for _, item := range items {
+		switch item.Status {
+		case "renamed":
+			result.Renamed++
+		case "failed":
+			result.RenameFailed++
+		}
+	}
+
+	var workspaceID int64
+	for _, record := range payload.Changes {
+		if record.From != "" && a.currentWorkspace != nil {
+			workspaceID = a.currentWorkspace.ID
+			break
+		}
+	}
+	payload.WorkspaceID = workspaceID
+	result.OperationID = a.recordOperation(data.OperationTagEdit, workspaceID, summary, payload)
+
+	if a.logger != nil {
+		a.logger.Info("标签编辑完成",
+			zap.String("action", payload.Action),
+			zap.Int64("tag_id", payload.Tag.ID),
+			zap.Int("changed", result.Changed),
+			zap.Int("renamed", result.Renamed),
+			zap.Int("rename_failed", result.RenameFailed),
+			zap.Int64("operation_id", result.OperationID),
+		)
+	}
+	return result
+}
+
+// replayTagEdit 撤销或重做标签重命名、合并、删除
+//
+// 标签本身的状态先于文件检查：撤销删除/合并要求标签仍不存在，重做要求标签仍存在。
+func (a *App) replayTagEdit(payload api.TagEditPayload, undo bool, result *api.OperationReplayResult) error {
+	snapshot := fromAPITagSnapshot(payload.Tag)
+	tagID := snapshot.Tag.ID
+	tagConflict := func(msg string) {
+		result.Conflicts = append(result.Conflicts, api.OperationConflict{Message: msg})
+	}
+
+	var pre, post func() error
+	current, err := a.db.GetTag(a.ctx, tagID)
+	switch payload.Action {
+	case tagEditRename:
+		expected, target := payload.NewName, snapshot.Tag.Name
+		if !undo {
+			expected, target = snapshot.Tag.Name, payload.NewName
+		}
+		switch {
+		case err != nil:
+			tagConflict(fmt.Sprintf("标签「%s」已被删除", expected))
+		case current.Name == target:
+		case current.Name != expected:
+			tagConflict(fmt.Sprintf("标签已被重命名为「%s」", current.Name))
+		default:
+			pre = func() error {
+				_, err := a.db.RenameTag(a.ctx, tagID, target)
+				return err
+			}
+		}
+	case tagEditDelete, tagEditMerge:
+		if undo {
+			if err == nil {
+				tagConflict(fmt.Sprintf("标签「%s」已存在", current.Name))
+				break
+			}
+			pre = func() error {
+				return a.db.RestoreTag(a.ctx, snapshot)
+			}
+			break
+		}
+		if err != nil {
+			tagConflict(fmt.Sprintf("标签「%s」已不存在", snapshot.Tag.Name))
+			break
+		}
+		if payload.Action == tagEditDelete {
+			post = func() error {
+				_, _, err := a.db.DeleteTagWithChanges(a.ctx, tagID)
+				return err
+			}
+			break
+		}
+		if _, err := a.db.GetTag(a.ctx, payload.TargetTagID); err != nil {
+			tagConflict("合并的目标标签已不存在")
+			break
+		}
+		post = func() error {
+			_, _, err := a.db.MergeTags(a.ctx, tagID, payload.TargetTagID)
+			return err
+		}
+	default:
+		return fmt.Errorf("无效的标签操作: %s", payload.Action)
+	}
+
+	return a.replayFileChanges(payload.Changes, undo, result, pre, post)
+}
+
+func toAPITagSnapshot(snapshot data.TagSnapshot) api.TagSnapshot {
+	tag := toAPITag(snapshot.Tag)
+	return api.TagSnapshot{
+		ID:       tag.ID,
+		Name:     tag.Name,
+		Color:    tag.Color,
+		ParentID: tag.ParentID,
+		ChildIDs: snapshot.ChildIDs,
+	}
+}
+
+func fromAPITagSnapshot(snapshot api.TagSnapshot) data.TagSnapshot {
+	tag := data.Tag{ID: snapshot.ID, Name: snapshot.Name, Color: snapshot.Color}
+	if snapshot.ParentID != nil {
+		tag.ParentID = sql.NullInt64{Int64: *snapshot.ParentID, Valid: true}
+	}
+	return data.TagSnapshot{Tag: tag, ChildIDs: snapshot.ChildIDs}
+}